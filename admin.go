@@ -1,7 +1,7 @@
 package client
 
 import (
-	"bytes"
+	"context"
 )
 
 // Lock contains the metadata for a SF system lock
@@ -16,8 +16,13 @@ type Locks map[string]LockMetadata
 
 // GetLocks retrieves a list of SF system locks
 func (c *Client) GetLocks() (Locks, error) {
+	return c.GetLocksCtx(context.Background())
+}
+
+// GetLocksCtx is GetLocks with a caller supplied context.
+func (c *Client) GetLocksCtx(ctx context.Context) (Locks, error) {
 	locks := Locks{}
-	err := c.doRequestJSON("admin/locks", "GET", bytes.Buffer{}, &locks)
+	err := c.doRequestJSON(ctx, "admin/locks", "GET", nil, &locks)
 
 	return locks, err
 }
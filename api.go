@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+	"io"
+)
+
+//go:generate go run ./internal/mockgen -source api.go -out mock/mock.go
+
+// The API is split into an interface for each subsystem, so code can depend
+// on only the calls it uses and tests can substitute a fake. *Client
+// implements all of them, and the mock package has generated fakes.
+
+// Instances manages instances and their lifecycle.
+type Instances interface {
+	GetInstances() ([]Instance, error)
+	GetInstancesCtx(ctx context.Context) ([]Instance, error)
+	GetInstance(uuid string) (Instance, error)
+	GetInstanceCtx(ctx context.Context, uuid string) (Instance, error)
+	CreateInstance(name string, cpus int, memory int, networks []NetworkSpec,
+		disks []DiskSpec, video VideoSpec, sshKey string, userData string,
+		nameSpace string, metadata string, secureBoot bool, uefi bool,
+		nvramTemplate string) (Instance, error)
+	CreateInstanceCtx(ctx context.Context, name string, cpus int, memory int,
+		networks []NetworkSpec, disks []DiskSpec, video VideoSpec,
+		sshKey string, userData string, nameSpace string, metadata string,
+		secureBoot bool, uefi bool, nvramTemplate string) (Instance, error)
+	CreateInstanceFromSpec(spec InstanceSpec) (Instance, error)
+	CreateInstanceFromSpecCtx(ctx context.Context,
+		spec InstanceSpec) (Instance, error)
+	SnapshotInstance(uuid string, all bool, device string) error
+	SnapshotInstanceCtx(ctx context.Context, uuid string, all bool,
+		device string) error
+	GetInstanceSnapshots(uuid string) ([]Snapshot, error)
+	GetInstanceSnapshotsCtx(ctx context.Context, uuid string) ([]Snapshot, error)
+	RebootInstance(uuid string) error
+	RebootInstanceCtx(ctx context.Context, uuid string) error
+	PowerOffInstance(uuid string) error
+	PowerOffInstanceCtx(ctx context.Context, uuid string) error
+	PowerOnInstance(uuid string) error
+	PowerOnInstanceCtx(ctx context.Context, uuid string) error
+	PauseInstance(uuid string) error
+	PauseInstanceCtx(ctx context.Context, uuid string) error
+	UnPauseInstance(uuid string) error
+	UnPauseInstanceCtx(ctx context.Context, uuid string) error
+	DeleteInstance(uuid string, namespace string) error
+	DeleteInstanceCtx(ctx context.Context, uuid string, namespace string) error
+	DeleteAllInstances(namespace string) ([]string, error)
+	DeleteAllInstancesCtx(ctx context.Context, namespace string) ([]string, error)
+	GetInstanceEvents(uuid string) ([]Event, error)
+	GetInstanceEventsCtx(ctx context.Context, uuid string) ([]Event, error)
+	GetConsoleData(uuid string, n int) (string, error)
+	GetConsoleDataCtx(ctx context.Context, uuid string, n int) (string, error)
+	WaitForInstanceState(ctx context.Context, uuid string,
+		targets, failures []State) (Instance, error)
+	StreamConsole(ctx context.Context, uuid string) io.ReadCloser
+}
+
+// Networks manages networks and network interfaces.
+type Networks interface {
+	GetNetworks() ([]Network, error)
+	GetNetworksCtx(ctx context.Context) ([]Network, error)
+	GetNetwork(uuid string) (Network, error)
+	GetNetworkCtx(ctx context.Context, uuid string) (Network, error)
+	CreateNetwork(netblock string, provideDHCP bool, provideNAT bool,
+		name string) (Network, error)
+	CreateNetworkCtx(ctx context.Context, netblock string, provideDHCP bool,
+		provideNAT bool, name string) (Network, error)
+	DeleteNetwork(uuid string) error
+	DeleteNetworkCtx(ctx context.Context, uuid string) error
+	DeleteAllNetworks(namespace string) ([]string, error)
+	DeleteAllNetworksCtx(ctx context.Context, namespace string) ([]string, error)
+	GetNetworkEvents(uuid string) ([]Event, error)
+	GetNetworkEventsCtx(ctx context.Context, uuid string) ([]Event, error)
+	GetInstanceInterfaces(uuid string) ([]NetworkInterface, error)
+	GetInstanceInterfacesCtx(ctx context.Context,
+		uuid string) ([]NetworkInterface, error)
+	GetNetworkInterfaces(uuid string) ([]NetworkInterface, error)
+	GetNetworkInterfacesCtx(ctx context.Context,
+		uuid string) ([]NetworkInterface, error)
+	GetInterface(uuid string) (NetworkInterface, error)
+	GetInterfaceCtx(ctx context.Context, uuid string) (NetworkInterface, error)
+	FloatInterface(interfaceUUID string) error
+	FloatInterfaceCtx(ctx context.Context, interfaceUUID string) error
+	DefloatInterface(interfaceUUID string) error
+	DefloatInterfaceCtx(ctx context.Context, interfaceUUID string) error
+	WaitForNetworkState(ctx context.Context, uuid string,
+		targets, failures []State) (Network, error)
+}
+
+// Namespaces manages namespaces and their keys.
+type Namespaces interface {
+	GetNamespaces() ([]string, error)
+	GetNamespacesCtx(ctx context.Context) ([]string, error)
+	CreateNamespace(namespace string) error
+	CreateNamespaceCtx(ctx context.Context, namespace string) error
+	DeleteNamespace(namespace string) error
+	DeleteNamespaceCtx(ctx context.Context, namespace string) error
+	GetNamespaceKeys(namespace string) ([]string, error)
+	GetNamespaceKeysCtx(ctx context.Context, namespace string) ([]string, error)
+	CreateNamespaceKey(namespace, keyName, key string) error
+	CreateNamespaceKeyCtx(ctx context.Context, namespace, keyName,
+		key string) error
+	UpdateNamespaceKey(namespace, keyName, key string) error
+	UpdateNamespaceKeyCtx(ctx context.Context, namespace, keyName,
+		key string) error
+	DeleteNamespaceKey(namespace, keyName string) error
+	DeleteNamespaceKeyCtx(ctx context.Context, namespace, keyName string) error
+}
+
+// MetadataStore manages key-value metadata on instances, networks and
+// namespaces. It is not called Metadata as that is the type of the values.
+type MetadataStore interface {
+	GetMetadata(res ResourceType, uuid string) (Metadata, error)
+	GetMetadataCtx(ctx context.Context, res ResourceType,
+		uuid string) (Metadata, error)
+	SetMetadata(res ResourceType, uuid, key, value string) error
+	SetMetadataCtx(ctx context.Context, res ResourceType, uuid, key,
+		value string) error
+	DeleteMetadata(res ResourceType, uuid, key string) error
+	DeleteMetadataCtx(ctx context.Context, res ResourceType, uuid,
+		key string) error
+	GetInstanceMetadata(uuid string) (Metadata, error)
+	GetInstanceMetadataCtx(ctx context.Context, uuid string) (Metadata, error)
+	SetInstanceMetadata(uuid, key, value string) error
+	SetInstanceMetadataCtx(ctx context.Context, uuid, key, value string) error
+	DeleteInstanceMetadata(uuid, key string) error
+	DeleteInstanceMetadataCtx(ctx context.Context, uuid, key string) error
+	SetInstanceMetadataItem(uuid string, key string, value string) error
+	SetInstanceMetadataItemCtx(ctx context.Context, uuid string, key string,
+		value string) error
+	DeleteInstanceMetadataItem(uuid string, key string) error
+	DeleteInstanceMetadataItemCtx(ctx context.Context, uuid string,
+		key string) error
+	GetNetworkMetadata(uuid string) (Metadata, error)
+	GetNetworkMetadataCtx(ctx context.Context, uuid string) (Metadata, error)
+	SetNetworkMetadata(uuid, key, value string) error
+	SetNetworkMetadataCtx(ctx context.Context, uuid, key, value string) error
+	DeleteNetworkMetadata(uuid, key string) error
+	DeleteNetworkMetadataCtx(ctx context.Context, uuid, key string) error
+	GetNamespaceMetadata(uuid string) (Metadata, error)
+	GetNamespaceMetadataCtx(ctx context.Context, uuid string) (Metadata, error)
+	SetNamespaceMetadata(uuid, key, value string) error
+	SetNamespaceMetadataCtx(ctx context.Context, uuid, key, value string) error
+	DeleteNamespaceMetadata(uuid, key string) error
+	DeleteNamespaceMetadataCtx(ctx context.Context, uuid, key string) error
+}
+
+// Artifacts manages artifacts, their blobs and labels, and cached images.
+type Artifacts interface {
+	CacheArtifact(image_url string) error
+	CacheArtifactCtx(ctx context.Context, image_url string) error
+	GetArtifact(uuid string) (Artifact, error)
+	GetArtifactCtx(ctx context.Context, uuid string) (Artifact, error)
+	GetArtifacts(node string) ([]Artifact, error)
+	GetArtifactsCtx(ctx context.Context, node string) ([]Artifact, error)
+	GetArtifactEvents(uuid string) ([]Event, error)
+	GetArtifactEventsCtx(ctx context.Context, uuid string) ([]Event, error)
+	GetArtifactVersions(uuid string) ([]Blob, error)
+	GetArtifactVersionsCtx(ctx context.Context, uuid string) ([]Blob, error)
+	GetBlobs(node string) ([]Blob, error)
+	GetBlobsCtx(ctx context.Context, node string) ([]Blob, error)
+	UpdateLabel(labelName string, blobUUID string) error
+	UpdateLabelCtx(ctx context.Context, labelName string, blobUUID string) error
+	CacheImage(imageURL string) error
+	CacheImageCtx(ctx context.Context, imageURL string) error
+	GetImageMeta() ([]ImageMeta, error)
+	GetImageMetaCtx(ctx context.Context) ([]ImageMeta, error)
+}
+
+// Uploads stages data, such as images, on the cluster.
+type Uploads interface {
+	CreateUpload() (Upload, error)
+	CreateUploadCtx(ctx context.Context) (Upload, error)
+	SendUpload(uuid string, data io.ReaderAt, size int64) (int64, error)
+	SendUploadCtx(ctx context.Context, uuid string, data io.ReaderAt,
+		size int64) (int64, error)
+	SendUploadFile(uuid string, path string) (int64, error)
+	SendUploadFileCtx(ctx context.Context, uuid string,
+		path string) (int64, error)
+	TruncateUpload(uuid string, offset int64) error
+	TruncateUploadCtx(ctx context.Context, uuid string, offset int64) error
+}
+
+// Watcher waits for and follows changes to resources of any type.
+type Watcher interface {
+	WaitForDeleted(ctx context.Context, res ResourceType, uuid string) error
+	WatchEvents(ctx context.Context, res ResourceType, uuid string,
+		filters ...EventFilter) *EventWatch
+}
+
+// Admin covers the cluster as a whole.
+type Admin interface {
+	GetNodes() ([]Node, error)
+	GetNodesCtx(ctx context.Context) ([]Node, error)
+	GetLocks() (Locks, error)
+	GetLocksCtx(ctx context.Context) (Locks, error)
+	GetServerInfo() (ServerInfo, error)
+	GetServerInfoCtx(ctx context.Context) (ServerInfo, error)
+}
+
+// API is the whole of the Shaken Fist API.
+type API interface {
+	Instances
+	Networks
+	Namespaces
+	MetadataStore
+	Artifacts
+	Uploads
+	Watcher
+	Admin
+}
+
+var (
+	_ Instances     = (*Client)(nil)
+	_ Networks      = (*Client)(nil)
+	_ Namespaces    = (*Client)(nil)
+	_ MetadataStore = (*Client)(nil)
+	_ Artifacts     = (*Client)(nil)
+	_ Uploads       = (*Client)(nil)
+	_ Watcher       = (*Client)(nil)
+	_ Admin         = (*Client)(nil)
+	_ API           = (*Client)(nil)
+)
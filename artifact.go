@@ -1,13 +1,20 @@
 package client
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 )
 
-//
+// CacheArtifact asks the cluster to fetch and cache the artifact at image_url.
 func (c *Client) CacheArtifact(image_url string) error {
+	return c.CacheArtifactCtx(context.Background(), image_url)
+}
+
+// CacheArtifactCtx is CacheArtifact with a caller supplied context.
+func (c *Client) CacheArtifactCtx(ctx context.Context, image_url string) error {
+	if err := c.require(ctx, CapabilityArtifacts); err != nil {
+		return err
+	}
+
 	path := "artifacts"
 	r := &struct {
 		URL string `json:"url"`
@@ -15,12 +22,7 @@ func (c *Client) CacheArtifact(image_url string) error {
 		URL: image_url,
 	}
 
-	req, err := json.Marshal(r)
-	if err != nil {
-		return fmt.Errorf("Unable to marshal data: %v", err)
-	}
-
-	err = c.doRequestJSON(path, "POST", *bytes.NewBuffer(req), nil)
+	err := c.doRequestJSON(ctx, path, "POST", r, nil)
 	return err
 }
 
@@ -36,44 +38,69 @@ type Artifact struct {
 }
 
 func (c *Client) GetArtifact(uuid string) (Artifact, error) {
+	return c.GetArtifactCtx(context.Background(), uuid)
+}
+
+// GetArtifactCtx is GetArtifact with a caller supplied context.
+func (c *Client) GetArtifactCtx(ctx context.Context, uuid string) (Artifact, error) {
+	if err := c.require(ctx, CapabilityArtifacts); err != nil {
+		return Artifact{}, err
+	}
+
 	var artifact Artifact
 
 	path := "artifacts/" + uuid
-	err := c.doRequestJSON(path, "GET", bytes.Buffer{}, &artifact)
+	err := c.doRequestJSON(ctx, path, "GET", nil, &artifact)
 	return artifact, err
 }
 
 func (c *Client) GetArtifacts(node string) ([]Artifact, error) {
-	var artifacts []Artifact
+	return c.GetArtifactsCtx(context.Background(), node)
+}
 
-	path := "artifacts"
-	r := &struct {
-		Node string `json:"node"`
-	}{
-		Node: node,
-	}
-	req, err := json.Marshal(r)
-	if err != nil {
-		return []Artifact{}, fmt.Errorf("Unable to marshal data: %v", err)
+// GetArtifactsCtx is GetArtifacts with a caller supplied context.
+func (c *Client) GetArtifactsCtx(ctx context.Context, node string) ([]Artifact, error) {
+	if err := c.require(ctx, CapabilityArtifacts); err != nil {
+		return nil, err
 	}
 
-	err = c.doRequestJSON(path, "GET", *bytes.NewBuffer(req), &artifacts)
+	var artifacts []Artifact
+
+	err := c.sendJSON(ctx, nodeRequest("artifacts", node), &artifacts)
 	return artifacts, err
 }
 
 func (c *Client) GetArtifactEvents(uuid string) ([]Event, error) {
+	return c.GetArtifactEventsCtx(context.Background(), uuid)
+}
+
+// GetArtifactEventsCtx is GetArtifactEvents with a caller supplied context.
+func (c *Client) GetArtifactEventsCtx(ctx context.Context, uuid string) ([]Event, error) {
+	if err := c.require(ctx, CapabilityArtifacts); err != nil {
+		return nil, err
+	}
+
 	var events []Event
 
 	path := "artifacts/" + uuid + "/events"
-	err := c.doRequestJSON(path, "GET", bytes.Buffer{}, &events)
+	err := c.doRequestJSON(ctx, path, "GET", nil, &events)
 	return events, err
 }
 
 func (c *Client) GetArtifactVersions(uuid string) ([]Blob, error) {
+	return c.GetArtifactVersionsCtx(context.Background(), uuid)
+}
+
+// GetArtifactVersionsCtx is GetArtifactVersions with a caller supplied context.
+func (c *Client) GetArtifactVersionsCtx(ctx context.Context, uuid string) ([]Blob, error) {
+	if err := c.require(ctx, CapabilityArtifacts); err != nil {
+		return nil, err
+	}
+
 	var blobs []Blob
 
 	path := "artifacts/" + uuid + "/versions"
-	err := c.doRequestJSON(path, "GET", bytes.Buffer{}, &blobs)
+	err := c.doRequestJSON(ctx, path, "GET", nil, &blobs)
 	return blobs, err
 }
 
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tokenRefreshMargin is how long before the expiry of an auth token the
+// client starts using a fresh one. Tokens with a short lifetime use
+// tokenRefreshFraction of it instead.
+const (
+	tokenRefreshMargin   = 30 * time.Second
+	tokenRefreshFraction = 4
+)
+
+type authRequest struct {
+	Namespace string `json:"namespace"`
+	APIKey    string `json:"key"`
+}
+
+type authResponse struct {
+	Token string `json:"access_token"`
+}
+
+// requestAuth fetches a new auth token from the server and caches it.
+func (c *Client) requestAuth(ctx context.Context) error {
+	req := &authRequest{
+		Namespace: c.namespace,
+		APIKey:    c.apiKey,
+	}
+	post, err := jsonBody(req)
+	if err != nil {
+		return fmt.Errorf("unable to marshal auth request: %w", err)
+	}
+
+	// The ETag exchange belongs to the request which needed the token
+	body, _, err := c.httpRequest(withoutCacheExchange(ctx), "auth", "POST",
+		post, "")
+	if err != nil {
+		return fmt.Errorf("auth request failed: %w", err)
+	}
+	defer body.Close()
+
+	resp := authResponse{}
+	err = json.NewDecoder(body).Decode(&resp)
+	if err != nil {
+		return fmt.Errorf("unable to decode response body: %w", err)
+	}
+
+	// The token has just been issued, so its lifetime is measured from now.
+	// That keeps a clock which differs from the server's from making every
+	// token look close to expiry.
+	now := time.Now()
+	issued, expiry := tokenClaims(resp.Token)
+	if !expiry.IsZero() {
+		if issued.IsZero() {
+			issued = now
+		}
+		expiry = now.Add(expiry.Sub(issued))
+	}
+	c.setToken(fmt.Sprintf("Bearer %s", resp.Token), expiry, now)
+
+	return nil
+}
+
+// setToken caches an auth token which expires at expiry, or never if it is
+// zero. If the token has no time left it is used until the server rejects
+// it, rather than fetching a new one for every request.
+func (c *Client) setToken(token string, expiry, now time.Time) {
+	margin := time.Duration(0)
+	if lifetime := expiry.Sub(now); expiry.IsZero() || lifetime <= 0 {
+		expiry = time.Time{}
+	} else {
+		margin = tokenRefreshMargin
+		if limit := lifetime / tokenRefreshFraction; margin > limit {
+			margin = limit
+		}
+	}
+
+	c.authLock.Lock()
+	c.cachedAuth = token
+	c.authExpiry = expiry
+	c.authMargin = margin
+	c.authLock.Unlock()
+}
+
+// authToken returns an auth token which is valid for at least its refresh
+// margin. Only one refresh is made at a time, concurrent
+// callers wait for it to finish and share the result.
+func (c *Client) authToken(ctx context.Context) (string, error) {
+	for {
+		c.authLock.Lock()
+		now := time.Now()
+		valid := c.cachedAuth != "" &&
+			(c.authExpiry.IsZero() || now.Before(c.authExpiry))
+		fresh := valid &&
+			(c.authExpiry.IsZero() || now.Add(c.authMargin).Before(c.authExpiry))
+
+		if fresh {
+			token := c.cachedAuth
+			c.authLock.Unlock()
+			return token, nil
+		}
+
+		if wait := c.authRefresh; wait != nil {
+			// Someone else is refreshing. Keep using the current token
+			// while it is still accepted, otherwise wait for them.
+			if valid {
+				token := c.cachedAuth
+				c.authLock.Unlock()
+				return token, nil
+			}
+			c.authLock.Unlock()
+
+			select {
+			case <-wait:
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+
+			c.authLock.Lock()
+			err := c.authErr
+			c.authLock.Unlock()
+
+			// A refresh abandoned by its caller says nothing about ours
+			if err != nil && !isContextError(err) {
+				return "", err
+			}
+			continue
+		}
+
+		done := make(chan struct{})
+		c.authRefresh = done
+		c.authLock.Unlock()
+
+		err := c.requestAuth(ctx)
+
+		c.authLock.Lock()
+		c.authErr = err
+		c.authRefresh = nil
+		token := c.cachedAuth
+		c.authLock.Unlock()
+		close(done)
+
+		if err != nil {
+			return "", err
+		}
+		return token, nil
+	}
+}
+
+// invalidateAuth discards the cached token if it is still the one which
+// the server rejected. A token which has since been refreshed is kept.
+func (c *Client) invalidateAuth(token string) {
+	c.authLock.Lock()
+	defer c.authLock.Unlock()
+
+	if c.cachedAuth == token {
+		c.cachedAuth = ""
+		c.authExpiry = time.Time{}
+	}
+}
+
+// tokenExpiry returns the expiry time from the "exp" claim of a JWT. It
+// returns the zero time if the token is not a JWT or has no expiry.
+func tokenExpiry(token string) time.Time {
+	_, expiry := tokenClaims(token)
+	return expiry
+}
+
+// tokenClaims returns the times from the "iat" and "exp" claims of a JWT.
+// Each is the zero time if the token is not a JWT or lacks the claim.
+func tokenClaims(token string) (issued, expiry time.Time) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(
+		strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return
+	}
+
+	claims := struct {
+		Iat float64 `json:"iat"`
+		Exp float64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return
+	}
+
+	if claims.Iat != 0 {
+		issued = time.Unix(int64(claims.Iat), 0)
+	}
+	if claims.Exp != 0 {
+		expiry = time.Unix(int64(claims.Exp), 0)
+	}
+	return
+}
+
+// isContextError reports whether err was caused by a cancelled or expired
+// context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
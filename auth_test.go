@@ -0,0 +1,248 @@
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// makeJWT builds an unsigned JWT which expires at exp.
+func makeJWT(exp time.Time) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	claims := enc.EncodeToString(
+		[]byte(fmt.Sprintf(`{"sub":"test","exp":%d}`, exp.Unix())))
+	return header + "." + claims + ".sig"
+}
+
+// makeIssuedJWT builds an unsigned JWT which was issued at iat and expires
+// at exp.
+func makeIssuedJWT(iat, exp time.Time) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	claims := enc.EncodeToString([]byte(fmt.Sprintf(
+		`{"sub":"test","iat":%d,"exp":%d}`, iat.Unix(), exp.Unix())))
+	return header + "." + claims + ".sig"
+}
+
+var _ = Describe("Concurrent auth", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+		workers               = 20
+	)
+
+	var (
+		client    *Client
+		authCalls int32
+		validAuth atomic.Value
+	)
+
+	// hammer runs GetInstances from many goroutines at once and returns
+	// any errors.
+	hammer := func() []error {
+		var wg sync.WaitGroup
+		errs := make(chan error, workers)
+		start := make(chan struct{})
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				if _, err := client.GetInstances(); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+		close(errs)
+
+		result := []error{}
+		for err := range errs {
+			result = append(result, err)
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		client = NewClient(test_url, test_namespace, test_key)
+		atomic.StoreInt32(&authCalls, 0)
+		validAuth.Store("")
+
+		// Each auth request issues a new token, and only the most
+		// recent token is accepted.
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			func(req *http.Request) (*http.Response, error) {
+				n := atomic.AddInt32(&authCalls, 1)
+				time.Sleep(10 * time.Millisecond)
+				token := fmt.Sprintf("token-%d", n)
+				validAuth.Store("Bearer " + token)
+				return httpmock.NewStringResponse(200,
+					`{"access_token":"`+token+`"}`), nil
+			})
+
+		httpmock.RegisterResponder("GET", test_url+"/instances",
+			func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("Authorization") != validAuth.Load().(string) {
+					return httpmock.NewStringResponse(401, `{"error":"expired"}`), nil
+				}
+				return httpmock.NewStringResponse(200, `[]`), nil
+			})
+	})
+
+	It("should make one auth request for many goroutines", func() {
+		Expect(hammer()).To(BeEmpty())
+		Expect(atomic.LoadInt32(&authCalls)).To(Equal(int32(1)))
+	})
+
+	It("should make one refresh when a shared token expires", func() {
+		Expect(hammer()).To(BeEmpty())
+
+		// The server forgets the token
+		validAuth.Store("revoked")
+
+		Expect(hammer()).To(BeEmpty())
+		Expect(atomic.LoadInt32(&authCalls)).To(Equal(int32(2)))
+	})
+
+	It("should share an auth failure between waiting goroutines", func() {
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&authCalls, 1)
+				time.Sleep(10 * time.Millisecond)
+				return httpmock.NewStringResponse(401, `{"error":"bad key"}`), nil
+			})
+
+		errs := hammer()
+		Expect(errs).To(HaveLen(workers))
+		for _, err := range errs {
+			Expect(IsUnauthorized(err)).To(BeTrue())
+		}
+		Expect(atomic.LoadInt32(&authCalls)).To(BeNumerically("<", workers))
+	})
+})
+
+var _ = Describe("Proactive token refresh", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	var (
+		client *Client
+	)
+
+	BeforeEach(func() {
+		client = NewClient(test_url, test_namespace, test_key)
+
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			httpmock.NewStringResponder(200, `[]`))
+	})
+
+	It("should decode the expiry of a JWT", func() {
+		exp := time.Unix(1900000000, 0)
+		Expect(tokenExpiry(makeJWT(exp))).To(Equal(exp))
+		Expect(tokenExpiry("not-a-jwt").IsZero()).To(BeTrue())
+		Expect(tokenExpiry("a.!!!.c").IsZero()).To(BeTrue())
+	})
+
+	It("should reuse a token which is not close to expiry", func() {
+		token := makeJWT(time.Now().Add(time.Hour))
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewStringResponder(200, `{"access_token":"`+token+`"}`))
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["POST "+test_url+"/auth"]).To(Equal(1))
+		Expect(info["GET "+test_url+"/nodes"]).To(Equal(3))
+	})
+
+	It("should refresh a token before it expires", func() {
+		token := makeJWT(time.Now().Add(time.Hour))
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewStringResponder(200, `{"access_token":"`+token+`"}`))
+
+		_, err := client.GetNodes()
+		Expect(err).To(BeNil())
+		Expect(client.authMargin).To(Equal(tokenRefreshMargin))
+
+		// The token is now inside the refresh margin, and the next call
+		// should not need a 401 to find out.
+		client.authLock.Lock()
+		client.authExpiry = time.Now().Add(tokenRefreshMargin / 2)
+		client.authLock.Unlock()
+
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["POST "+test_url+"/auth"]).To(Equal(2))
+		Expect(info["GET "+test_url+"/nodes"]).To(Equal(2))
+	})
+
+	It("should reuse a token with a lifetime shorter than the margin", func() {
+		token := makeJWT(time.Now().Add(20 * time.Second))
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewStringResponder(200, `{"access_token":"`+token+`"}`))
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["POST "+test_url+"/auth"]).To(Equal(1))
+		Expect(client.authMargin).To(BeNumerically("<=", 5*time.Second))
+	})
+
+	It("should measure the lifetime of a token from when it was issued", func() {
+		// The server's clock is an hour behind ours
+		skew := -time.Hour
+		issued := time.Now().Add(skew)
+		token := makeIssuedJWT(issued, issued.Add(10*time.Minute))
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewStringResponder(200, `{"access_token":"`+token+`"}`))
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["POST "+test_url+"/auth"]).To(Equal(1))
+		Expect(client.authExpiry).To(BeTemporally("~",
+			time.Now().Add(10*time.Minute), 5*time.Second))
+	})
+
+	It("should rely on a 401 for a token which looks expired", func() {
+		// Without an issue time, our clock being ahead of the server's
+		// makes the token look expired when it arrives
+		token := makeJWT(time.Now().Add(-time.Minute))
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewStringResponder(200, `{"access_token":"`+token+`"}`))
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["POST "+test_url+"/auth"]).To(Equal(1))
+		Expect(client.authExpiry.IsZero()).To(BeTrue())
+	})
+})
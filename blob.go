@@ -1,9 +1,7 @@
 package client
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 )
 
 type Blob struct {
@@ -15,19 +13,31 @@ type Blob struct {
 }
 
 func (c *Client) GetBlobs(node string) ([]Blob, error) {
+	return c.GetBlobsCtx(context.Background(), node)
+}
+
+// GetBlobsCtx is GetBlobs with a caller supplied context.
+func (c *Client) GetBlobsCtx(ctx context.Context, node string) ([]Blob, error) {
+	if err := c.require(ctx, CapabilityArtifacts); err != nil {
+		return nil, err
+	}
+
 	var blobs []Blob
 
-	path := "blobs"
-	r := &struct {
+	err := c.sendJSON(ctx, nodeRequest("blobs", node), &blobs)
+	return blobs, err
+}
+
+// nodeRequest returns a GET request for path, filtered to a node if node
+// is not empty.
+func nodeRequest(path, node string) *apiRequest {
+	r := newAPIRequest("GET", path).withFallback(&struct {
 		Node string `json:"node"`
 	}{
 		Node: node,
+	})
+	if node != "" {
+		r.param("node", node)
 	}
-	req, err := json.Marshal(r)
-	if err != nil {
-		return []Blob{}, fmt.Errorf("Unable to marshal data: %v", err)
-	}
-
-	err = c.doRequestJSON(path, "GET", *bytes.NewBuffer(req), &blobs)
-	return blobs, err
+	return r
 }
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// requestBody is a request body which can be sent more than once. Every
+// attempt at a request, including the resend after an auth token refresh
+// and failover to another API server, reads a fresh copy from open. A nil
+// *requestBody is an empty body.
+type requestBody struct {
+	// open returns a new reader positioned at the start of the body
+	open func() (io.ReadCloser, error)
+
+	// length is the size of the body in bytes
+	length      int64
+	contentType string
+
+	// data is the body if it is held in memory, and value the value a JSON
+	// body encodes. Either is used for debug logging.
+	data  []byte
+	value interface{}
+}
+
+// bodyError is an error reading or encoding a request body. It is the
+// fault of the caller rather than the server, so the request is not retried
+// and the endpoint is not marked as failed.
+type bodyError struct {
+	err error
+}
+
+func (e *bodyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *bodyError) Unwrap() error {
+	return e.err
+}
+
+// isBodyError reports whether err was caused by the request body.
+func isBodyError(err error) bool {
+	var be *bodyError
+	return errors.As(err, &be)
+}
+
+// jsonBody returns a body holding the JSON encoding of v. The value is
+// encoded once to measure it and then again as each attempt sends it, so
+// the encoding is never held between attempts or while waiting to retry.
+func jsonBody(v interface{}) (*requestBody, error) {
+	counter := &countingWriter{}
+	if err := encodeJSON(counter, v); err != nil {
+		return nil, fmt.Errorf("cannot encode request body: %w", err)
+	}
+
+	return &requestBody{
+		length:      counter.n,
+		contentType: "application/json",
+		value:       v,
+		open: func() (io.ReadCloser, error) {
+			r, w := io.Pipe()
+			go func() {
+				err := encodeJSON(w, v)
+				if err != nil {
+					err = &bodyError{fmt.Errorf("cannot encode request body: %w", err)}
+				}
+				w.CloseWithError(err)
+			}()
+			return r, nil
+		},
+	}, nil
+}
+
+// encodeJSON writes the JSON encoding of v to w. The output matches
+// json.Marshal, without the newline which json.Encoder adds.
+func encodeJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(trimNewline{w}).Encode(v)
+}
+
+// trimNewline drops the newline which ends each write from json.Encoder,
+// which writes the whole encoding of a value at once.
+type trimNewline struct {
+	w io.Writer
+}
+
+func (t trimNewline) Write(p []byte) (int, error) {
+	n, err := t.w.Write(bytes.TrimSuffix(p, []byte("\n")))
+	if err == nil {
+		n = len(p)
+	}
+	return n, err
+}
+
+// countingWriter discards what is written to it, counting the bytes.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// bytesBody returns a body holding data.
+func bytesBody(data []byte, contentType string) *requestBody {
+	b := readerAtBody(bytes.NewReader(data), int64(len(data)), contentType)
+	b.data = data
+	return b
+}
+
+// readerAtBody returns a body of size bytes read from r. Each attempt reads
+// r independently, so large uploads are never held in memory, and r must
+// allow concurrent calls to ReadAt, as *os.File does.
+func readerAtBody(r io.ReaderAt, size int64, contentType string) *requestBody {
+	return &requestBody{
+		length:      size,
+		contentType: contentType,
+		open: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(io.NewSectionReader(r, 0, size)), nil
+		},
+	}
+}
+
+// fileBody returns a body which streams the file at path, reopening it for
+// every attempt.
+func fileBody(path, contentType string) (*requestBody, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read upload: %w", err)
+	}
+
+	return &requestBody{
+		length:      info.Size(),
+		contentType: contentType,
+		open: func() (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+	}, nil
+}
+
+// contentTypeOr returns the content type of the body, or fallback for an
+// empty body.
+func (b *requestBody) contentTypeOr(fallback string) string {
+	if b == nil || b.contentType == "" {
+		return fallback
+	}
+	return b.contentType
+}
+
+// size returns the length of the body, which is zero for an empty body.
+func (b *requestBody) size() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.length
+}
+
+// logged returns the body as it should appear in a debug log entry.
+func (b *requestBody) logged() string {
+	if b.size() == 0 {
+		return ""
+	}
+	if !strings.HasPrefix(b.contentType, "application/json") ||
+		(b.data == nil && b.value == nil) {
+		return fmt.Sprintf("<%d bytes of %s data>", b.length, b.contentType)
+	}
+	if b.data != nil {
+		return redactBody(b.data)
+	}
+
+	data, err := json.Marshal(b.value)
+	if err != nil {
+		return fmt.Sprintf("<unencodable %s data: %v>", b.contentType, err)
+	}
+	return redactBody(data)
+}
+
+// setBody gives req a fresh copy of body.
+func (b *requestBody) setBody(req *http.Request) error {
+	if b.size() == 0 {
+		return nil
+	}
+
+	r, err := b.open()
+	if err != nil {
+		return &bodyError{fmt.Errorf("cannot open request body: %w", err)}
+	}
+	req.Body = r
+	req.GetBody = b.open
+	req.ContentLength = b.length
+	return nil
+}
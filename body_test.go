@@ -0,0 +1,241 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// bodyServer is a local API server which records the body of every request
+// it receives after auth. The first failures requests are rejected with the
+// status failWith, and the rest are answered with response.
+type bodyServer struct {
+	*httptest.Server
+
+	lock     sync.Mutex
+	bodies   []string
+	lengths  []int64
+	failures int
+	failWith int
+	response string
+}
+
+func newBodyServer(failures, failWith int) *bodyServer {
+	s := &bodyServer{failures: failures, failWith: failWith, response: `{}`}
+	s.Server = newTestServer(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				w.Write([]byte(`{"version": "v0.7.0"}`))
+				return
+			}
+
+			// Hash large bodies rather than keeping them
+			h := sha256.New()
+			io.Copy(h, r.Body)
+
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			s.bodies = append(s.bodies, fmt.Sprintf("%x", h.Sum(nil)))
+			s.lengths = append(s.lengths, r.ContentLength)
+			if len(s.bodies) <= s.failures {
+				w.WriteHeader(s.failWith)
+				return
+			}
+			w.Write([]byte(s.response))
+		})
+	return s
+}
+
+func sha(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+var _ = Describe("Request bodies", func() {
+	var (
+		server *bodyServer
+	)
+
+	newClient := func() *Client {
+		policy := DefaultRetryPolicy()
+		policy.InitialBackoff = time.Millisecond
+
+		client, err := newTestClient(server.URL, WithRetryPolicy(policy))
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	BeforeEach(func() {
+		server = nil
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			server.Close()
+		}
+	})
+
+	It("should send the full JSON body on every retry", func() {
+		server = newBodyServer(2, http.StatusServiceUnavailable)
+
+		err := newClient().SetInstanceMetadata("a7b1c9e2", "owner", "bob")
+		Expect(err).To(BeNil())
+
+		want := sha([]byte(`{"value":"bob"}`))
+		Expect(server.bodies).To(Equal([]string{want, want, want}))
+		Expect(server.lengths).To(Equal([]int64{15, 15, 15}))
+	})
+
+	It("should send the full JSON body again after refreshing auth", func() {
+		server = newBodyServer(1, http.StatusUnauthorized)
+
+		err := newClient().SetInstanceMetadata("a7b1c9e2", "owner", "bob")
+		Expect(err).To(BeNil())
+
+		want := sha([]byte(`{"value":"bob"}`))
+		Expect(server.bodies).To(Equal([]string{want, want}))
+	})
+
+	It("should stream a file upload on every retry", func() {
+		server = newBodyServer(1, http.StatusBadGateway)
+
+		data := make([]byte, 8*1024*1024)
+		rand.New(rand.NewSource(42)).Read(data)
+
+		f, err := ioutil.TempFile("", "upload")
+		Expect(err).To(BeNil())
+		defer os.Remove(f.Name())
+		_, err = f.Write(data)
+		Expect(err).To(BeNil())
+		Expect(f.Close()).To(BeNil())
+
+		body, err := fileBody(f.Name(), "application/octet-stream")
+		Expect(err).To(BeNil())
+
+		resp, err := newClient().doRequest(context.Background(), "upload",
+			"PUT", body)
+		Expect(err).To(BeNil())
+		resp.Close()
+
+		want := sha(data)
+		Expect(server.bodies).To(Equal([]string{want, want}))
+		Expect(server.lengths).To(Equal([]int64{int64(len(data)),
+			int64(len(data))}))
+	})
+
+	It("should stream an upload again after refreshing auth", func() {
+		server = newBodyServer(1, http.StatusUnauthorized)
+		server.response = `1048576`
+
+		data := make([]byte, 1024*1024)
+		rand.New(rand.NewSource(42)).Read(data)
+
+		f, err := ioutil.TempFile("", "upload")
+		Expect(err).To(BeNil())
+		defer os.Remove(f.Name())
+		_, err = f.Write(data)
+		Expect(err).To(BeNil())
+		Expect(f.Close()).To(BeNil())
+
+		length, err := newClient().SendUploadFile("c2d4e6f8", f.Name())
+		Expect(err).To(BeNil())
+		Expect(length).To(Equal(int64(len(data))))
+
+		want := sha(data)
+		Expect(server.bodies).To(Equal([]string{want, want}))
+	})
+
+	It("should send part of a reader as an upload chunk", func() {
+		server = newBodyServer(0, 0)
+		server.response = `6`
+
+		data := []byte("header:chunk:trailer")
+		length, err := newClient().SendUpload("c2d4e6f8",
+			io.NewSectionReader(bytes.NewReader(data), 7, 6), 6)
+		Expect(err).To(BeNil())
+		Expect(length).To(Equal(int64(6)))
+		Expect(server.bodies).To(Equal([]string{sha([]byte("chunk:"))}))
+		Expect(server.lengths).To(Equal([]int64{6}))
+	})
+
+	It("should not retry a body which cannot be opened", func() {
+		server = newBodyServer(0, 0)
+
+		f, err := ioutil.TempFile("", "upload")
+		Expect(err).To(BeNil())
+		_, err = f.Write([]byte("gone before it is sent"))
+		Expect(err).To(BeNil())
+		Expect(f.Close()).To(BeNil())
+		body, err := fileBody(f.Name(), "application/octet-stream")
+		Expect(err).To(BeNil())
+		Expect(os.Remove(f.Name())).To(BeNil())
+
+		attempts := 0
+		client := newClient()
+		client.retryPolicy.OnAttempt = func(RetryAttempt) { attempts++ }
+		_, err = client.doRequest(context.Background(), "upload", "PUT", body)
+		Expect(err).To(MatchError(ContainSubstring("cannot open request body")))
+		Expect(attempts).To(Equal(1))
+		Expect(server.bodies).To(BeEmpty())
+	})
+
+	It("should encode a JSON body afresh for each attempt", func() {
+		body, err := jsonBody(map[string]string{"key": "value"})
+		Expect(err).To(BeNil())
+		Expect(body.size()).To(Equal(int64(len(`{"key":"value"}`))))
+
+		for i := 0; i < 2; i++ {
+			r, err := body.open()
+			Expect(err).To(BeNil())
+			data, err := ioutil.ReadAll(r)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(`{"key":"value"}`))
+		}
+	})
+
+	It("should give each attempt an independent reader", func() {
+		data := []byte("some binary data")
+		body := bytesBody(data, "application/octet-stream")
+
+		first, err := body.open()
+		Expect(err).To(BeNil())
+		second, err := body.open()
+		Expect(err).To(BeNil())
+
+		half := make([]byte, 4)
+		_, err = io.ReadFull(first, half)
+		Expect(err).To(BeNil())
+
+		all, err := ioutil.ReadAll(second)
+		Expect(err).To(BeNil())
+		Expect(all).To(Equal(data))
+	})
+
+	It("should summarise binary bodies when logging", func() {
+		Expect(bytesBody([]byte{0, 1, 2}, "application/octet-stream").logged()).To(
+			Equal("<3 bytes of application/octet-stream data>"))
+		body, err := jsonBody(map[string]string{"key": "secret"})
+		Expect(err).To(BeNil())
+		Expect(body.logged()).To(MatchJSON(`{"key": "REDACTED"}`))
+	})
+
+	It("should not send a body which cannot be encoded", func() {
+		server = newBodyServer(0, 0)
+
+		err := newClient().doRequestJSON(context.Background(), "instances",
+			"POST", map[string]interface{}{"bad": make(chan int)}, nil)
+		Expect(err).To(MatchError(ContainSubstring("cannot encode request body")))
+		Expect(server.bodies).To(BeEmpty())
+	})
+})
@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheSettings configure the response cache.
+type CacheSettings struct {
+	// TTL is how long a response is used for before it is fetched again
+	TTL time.Duration
+
+	// ResourceTTLs override TTL for some resources, named by the first
+	// element of their API path, such as "networks" or "nodes". A TTL of
+	// zero or less turns off caching for the resource.
+	ResourceTTLs map[string]time.Duration
+
+	// MaxEntries is the most responses which are cached. The default is
+	// 1000.
+	MaxEntries int
+}
+
+// WithResponseCache caches the responses to GET requests. A response is
+// used until its TTL expires, after which it is fetched again. If the
+// server gave the response an ETag, the cached copy is revalidated with
+// If-None-Match rather than downloaded again.
+//
+// A successful change to a resource, such as DeleteNetwork or SetMetadata,
+// removes the cached responses for that resource, the paths below it and
+// the lists which include it. A response to a GET which was sent before
+// such a change finished is not cached. Changes made by other clients, and
+// the effects of a change on other resources, are only seen once the TTL
+// expires, so use BypassCache for calls which must see the current state.
+func WithResponseCache(settings CacheSettings) Option {
+	return func(c *Client) error {
+		if settings.MaxEntries <= 0 {
+			settings.MaxEntries = 1000
+		}
+		c.cache = &responseCache{settings: settings,
+			entries: map[string]*cacheEntry{}}
+		return nil
+	}
+}
+
+type bypassCacheKey struct{}
+
+// BypassCache returns a context for calls which fetch the current state
+// from the server rather than using a cached response. The response is
+// still cached for later calls.
+func BypassCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+// cacheBypassed reports whether ctx is from BypassCache.
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+// cacheExchange carries the ETag of a cached response to sendRequest, and
+// what the server said about it back.
+type cacheExchange struct {
+	ifNoneMatch string
+	etag        string
+	notModified bool
+}
+
+type cacheExchangeKey struct{}
+
+func withCacheExchange(ctx context.Context, ex *cacheExchange) context.Context {
+	return context.WithValue(ctx, cacheExchangeKey{}, ex)
+}
+
+// withoutCacheExchange returns ctx without an exchange, for requests such
+// as fetching an auth token which are made on the way to a cached one.
+func withoutCacheExchange(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheExchangeKey{}, (*cacheExchange)(nil))
+}
+
+// cacheExchangeFrom returns the exchange recorded in ctx, or nil.
+func cacheExchangeFrom(ctx context.Context) *cacheExchange {
+	ex, _ := ctx.Value(cacheExchangeKey{}).(*cacheExchange)
+	return ex
+}
+
+// cacheEntry is a cached response body.
+type cacheEntry struct {
+	data    []byte
+	etag    string
+	expires time.Time
+}
+
+// responseCache holds response bodies by request path.
+type responseCache struct {
+	settings CacheSettings
+
+	lock    sync.Mutex
+	entries map[string]*cacheEntry
+
+	// generation counts invalidations. changed holds the generation at
+	// which each resource path was last invalidated, and floor is the
+	// latest generation which has been forgotten from it.
+	generation uint64
+	changed    map[string]uint64
+	floor      uint64
+}
+
+// resource returns the kind of resource a path is for.
+func resource(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+	return strings.SplitN(path, "/", 2)[0]
+}
+
+// resourcePath returns the path of the resource a path is for, such as
+// "instances/<uuid>" for "instances/<uuid>/metadata/owner", or the
+// collection for a path such as "instances".
+func resourcePath(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, "/")
+}
+
+// affects reports whether a change to the resource at res changes the
+// response for key, because key is res, below it, or a list above it.
+func affects(res, key string) bool {
+	path := strings.SplitN(key, "?", 2)[0]
+	return path == res || strings.HasPrefix(path, res+"/") ||
+		strings.HasPrefix(res, path+"/")
+}
+
+// ttl returns how long responses for path are cached.
+func (rc *responseCache) ttl(path string) time.Duration {
+	if ttl, ok := rc.settings.ResourceTTLs[resource(path)]; ok {
+		return ttl
+	}
+	return rc.settings.TTL
+}
+
+// get returns a copy of the entry for key, or nil.
+func (rc *responseCache) get(key string) *cacheEntry {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil
+	}
+	copied := *entry
+	return &copied
+}
+
+// current returns the generation to pass to put for a request which is
+// about to be sent.
+func (rc *responseCache) current() uint64 {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	return rc.generation
+}
+
+// put caches a response for key, unless a change to the resource has been
+// made since generation, when the request for it was sent.
+func (rc *responseCache) put(key string, entry *cacheEntry, generation uint64) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if generation < rc.floor {
+		return
+	}
+	for res, changed := range rc.changed {
+		if changed > generation && affects(res, key) {
+			return
+		}
+	}
+
+	if _, ok := rc.entries[key]; !ok && len(rc.entries) >= rc.settings.MaxEntries {
+		// Make room by dropping the entry closest to expiry
+		var oldest string
+		for k, e := range rc.entries {
+			if oldest == "" || e.expires.Before(rc.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		delete(rc.entries, oldest)
+	}
+	rc.entries[key] = entry
+}
+
+// invalidate drops the cached responses affected by a change to the
+// resource path is for. A nil cache does nothing.
+func (rc *responseCache) invalidate(path string) {
+	if rc == nil {
+		return
+	}
+
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	res := resourcePath(path)
+	for key := range rc.entries {
+		if affects(res, key) {
+			delete(rc.entries, key)
+		}
+	}
+
+	rc.generation++
+	if rc.changed == nil {
+		rc.changed = map[string]uint64{}
+	}
+	rc.changed[res] = rc.generation
+	if len(rc.changed) > rc.settings.MaxEntries {
+		// Forget the oldest change. Responses to requests sent before it
+		// are no longer cached, as they cannot be checked.
+		var oldest string
+		for r, g := range rc.changed {
+			if oldest == "" || g < rc.changed[oldest] {
+				oldest = r
+			}
+		}
+		rc.floor = rc.changed[oldest]
+		delete(rc.changed, oldest)
+	}
+}
+
+// cachedJSON is sendJSON for a GET through the response cache.
+func (c *Client) cachedJSON(ctx context.Context, r *apiRequest,
+	resp interface{}) error {
+
+	key := r.target()
+	ttl := c.cache.ttl(r.path)
+	if ttl <= 0 {
+		return c.fetchJSON(ctx, r, resp)
+	}
+	generation := c.cache.current()
+
+	ex := &cacheExchange{}
+	entry := c.cache.get(key)
+	if entry != nil && !cacheBypassed(ctx) {
+		if time.Now().Before(entry.expires) {
+			return decodeResponse(entry.data, resp)
+		}
+		ex.ifNoneMatch = entry.etag
+	}
+
+	if ex.ifNoneMatch == "" {
+		f, err := c.fetch(ctx, r)
+		if err != nil {
+			return err
+		}
+		c.cache.put(key, &cacheEntry{data: f.data, etag: f.etag,
+			expires: time.Now().Add(ttl)}, generation)
+		return decodeResponse(f.data, resp)
+	}
+
+	// Revalidation is specific to this entry, so it is not coalesced
+	respBody, err := c.send(withCacheExchange(ctx, ex), r)
+	if err != nil {
+		return fmt.Errorf("request error: %w", err)
+	}
+	defer respBody.Close()
+
+	if ex.notModified {
+		entry.expires = time.Now().Add(ttl)
+		c.cache.put(key, entry, generation)
+		return decodeResponse(entry.data, resp)
+	}
+
+	data, err := ioutil.ReadAll(respBody)
+	if err != nil {
+		return err
+	}
+	c.cache.put(key, &cacheEntry{data: data, etag: ex.etag,
+		expires: time.Now().Add(ttl)}, generation)
+	return decodeResponse(data, resp)
+}
+
+// decodeResponse decodes a response body into resp, if it is not nil.
+func decodeResponse(data []byte, resp interface{}) error {
+	if resp == nil {
+		return nil
+	}
+	return json.Unmarshal(data, resp)
+}
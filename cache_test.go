@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response cache", func() {
+	const (
+		test_uuid string = "8b4d3e8a-4c13-4c69-9f4e-2d4e0e4bd0f5"
+	)
+
+	var (
+		server *httptest.Server
+
+		lock         sync.Mutex
+		requests     []string
+		notModified  int
+		networksBody string
+		etag         string
+
+		// authMatches are the If-None-Match headers sent to /auth, and
+		// expireToken rejects the next network list to force a new token
+		authMatches []string
+		expireToken bool
+	)
+
+	BeforeEach(func() {
+		requests = []string{}
+		notModified = 0
+		networksBody = `[{"uuid": "` + test_uuid + `", "name": "net"}]`
+		etag = ""
+		authMatches = []string{}
+		expireToken = false
+
+		server = httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				if r.URL.Path == "/auth" {
+					authMatches = append(authMatches, r.Header.Get("If-None-Match"))
+					w.Header().Set("ETag", `"auth"`)
+					w.Write([]byte(`{"access_token":"token"}`))
+					return
+				}
+				requests = append(requests, r.Method+" "+r.URL.Path)
+
+				switch {
+				case r.Method != "GET":
+					w.Write([]byte(`null`))
+				case r.URL.Path == "/networks" && expireToken:
+					expireToken = false
+					w.WriteHeader(http.StatusUnauthorized)
+				case r.URL.Path == "/networks":
+					if etag != "" {
+						if r.Header.Get("If-None-Match") == etag {
+							notModified++
+							w.WriteHeader(http.StatusNotModified)
+							return
+						}
+						w.Header().Set("ETag", etag)
+					}
+					w.Write([]byte(networksBody))
+				case strings.HasPrefix(r.URL.Path, "/networks/"):
+					w.Write([]byte(`{"uuid": "` +
+						strings.TrimPrefix(r.URL.Path, "/networks/") + `"}`))
+				case r.URL.Path == "/nodes":
+					w.Write([]byte(`[{"name": "sf-1"}]`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func(settings CacheSettings) *Client {
+		client, err := newTestClient(server.URL, WithResponseCache(settings))
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	count := func(request string) int {
+		lock.Lock()
+		defer lock.Unlock()
+		n := 0
+		for _, r := range requests {
+			if r == request {
+				n++
+			}
+		}
+		return n
+	}
+
+	It("should reuse responses until the TTL expires", func() {
+		client := newClient(CacheSettings{TTL: time.Hour,
+			ResourceTTLs: map[string]time.Duration{"nodes": 50 * time.Millisecond}})
+
+		for i := 0; i < 3; i++ {
+			networks, err := client.GetNetworks()
+			Expect(err).To(BeNil())
+			Expect(networks[0].Name).To(Equal("net"))
+			_, err = client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+		Expect(count("GET /networks")).To(Equal(1))
+		Expect(count("GET /nodes")).To(Equal(1))
+
+		time.Sleep(60 * time.Millisecond)
+		_, err := client.GetNodes()
+		Expect(err).To(BeNil())
+		Expect(count("GET /nodes")).To(Equal(2))
+		Expect(count("GET /networks")).To(Equal(1))
+	})
+
+	It("should not cache resources with no TTL", func() {
+		client := newClient(CacheSettings{TTL: time.Hour,
+			ResourceTTLs: map[string]time.Duration{"nodes": 0}})
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+		Expect(count("GET /nodes")).To(Equal(2))
+	})
+
+	It("should not share decoded results between callers", func() {
+		client := newClient(CacheSettings{TTL: time.Hour})
+
+		first, err := client.GetNetworks()
+		Expect(err).To(BeNil())
+		first[0].Name = "changed"
+
+		second, err := client.GetNetworks()
+		Expect(err).To(BeNil())
+		Expect(second[0].Name).To(Equal("net"))
+	})
+
+	It("should revalidate with the ETag once the TTL expires", func() {
+		etag = `"v1"`
+		client := newClient(CacheSettings{TTL: 20 * time.Millisecond})
+
+		_, err := client.GetNetworks()
+		Expect(err).To(BeNil())
+		time.Sleep(30 * time.Millisecond)
+
+		networks, err := client.GetNetworks()
+		Expect(err).To(BeNil())
+		Expect(networks[0].Name).To(Equal("net"))
+		Expect(notModified).To(Equal(1))
+
+		// A changed resource is downloaded again
+		lock.Lock()
+		etag = `"v2"`
+		networksBody = `[{"uuid": "` + test_uuid + `", "name": "renamed"}]`
+		lock.Unlock()
+		time.Sleep(30 * time.Millisecond)
+
+		networks, err = client.GetNetworks()
+		Expect(err).To(BeNil())
+		Expect(networks[0].Name).To(Equal("renamed"))
+		Expect(notModified).To(Equal(1))
+		Expect(count("GET /networks")).To(Equal(3))
+	})
+
+	It("should keep the ETag exchange out of refreshing the token", func() {
+		etag = `"v1"`
+		client := newClient(CacheSettings{TTL: 10 * time.Millisecond})
+
+		_, err := client.GetNetworks()
+		Expect(err).To(BeNil())
+
+		time.Sleep(20 * time.Millisecond)
+		lock.Lock()
+		expireToken = true
+		lock.Unlock()
+		networks, err := client.GetNetworks()
+		Expect(err).To(BeNil())
+		Expect(networks[0].Name).To(Equal("net"))
+
+		// The cached copy still has its own ETag
+		time.Sleep(20 * time.Millisecond)
+		_, err = client.GetNetworks()
+		Expect(err).To(BeNil())
+
+		lock.Lock()
+		defer lock.Unlock()
+		Expect(authMatches).To(Equal([]string{"", ""}))
+		Expect(notModified).To(Equal(2))
+	})
+
+	It("should invalidate a resource when it is changed", func() {
+		client := newClient(CacheSettings{TTL: time.Hour})
+
+		_, err := client.GetNetworks()
+		Expect(err).To(BeNil())
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+
+		Expect(client.DeleteNetwork(test_uuid)).To(Succeed())
+		_, err = client.GetNetworks()
+		Expect(err).To(BeNil())
+		Expect(count("GET /networks")).To(Equal(2))
+
+		Expect(client.SetMetadata(TypeNetwork, test_uuid, "k", "v")).To(Succeed())
+		_, err = client.GetNetworks()
+		Expect(err).To(BeNil())
+		Expect(count("GET /networks")).To(Equal(3))
+
+		// Other resources are untouched
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+		Expect(count("GET /nodes")).To(Equal(1))
+	})
+
+	It("should only invalidate the resource which changed", func() {
+		const other_uuid = "2f6e8c1a-9b3d-4e5f-8a7b-6c5d4e3f2a1b"
+		client := newClient(CacheSettings{TTL: time.Hour})
+
+		for _, uuid := range []string{test_uuid, other_uuid} {
+			_, err := client.GetNetwork(uuid)
+			Expect(err).To(BeNil())
+		}
+		Expect(client.SetMetadata(TypeNetwork, test_uuid, "k", "v")).To(Succeed())
+		for _, uuid := range []string{test_uuid, other_uuid} {
+			_, err := client.GetNetwork(uuid)
+			Expect(err).To(BeNil())
+		}
+
+		Expect(count("GET /networks/" + test_uuid)).To(Equal(2))
+		Expect(count("GET /networks/" + other_uuid)).To(Equal(1))
+	})
+
+	It("should not cache a response fetched before a change", func() {
+		rc := &responseCache{settings: CacheSettings{TTL: time.Hour,
+			MaxEntries: 2}, entries: map[string]*cacheEntry{}}
+		entry := &cacheEntry{data: []byte(`[]`),
+			expires: time.Now().Add(time.Hour)}
+
+		generation := rc.current()
+		rc.invalidate("networks/" + test_uuid + "/metadata/k")
+		rc.put("networks", entry, generation)
+		rc.put("networks/"+test_uuid+"/events", entry, generation)
+		rc.put("networks/other", entry, generation)
+		rc.put("nodes", entry, generation)
+		Expect(rc.get("networks")).To(BeNil())
+		Expect(rc.get("networks/" + test_uuid + "/events")).To(BeNil())
+		Expect(rc.get("networks/other")).NotTo(BeNil())
+		Expect(rc.get("nodes")).NotTo(BeNil())
+
+		// Requests older than the changes which have been forgotten are
+		// not cached either
+		rc.invalidate("instances/a")
+		rc.invalidate("instances/b")
+		rc.put("nodes?node=sf-1", entry, generation)
+		Expect(rc.get("nodes?node=sf-1")).To(BeNil())
+		rc.put("nodes?node=sf-1", entry, rc.current())
+		Expect(rc.get("nodes?node=sf-1")).NotTo(BeNil())
+	})
+
+	It("should bypass the cache for a single call", func() {
+		client := newClient(CacheSettings{TTL: time.Hour})
+
+		_, err := client.GetNetworks()
+		Expect(err).To(BeNil())
+
+		lock.Lock()
+		networksBody = `[{"uuid": "` + test_uuid + `", "name": "renamed"}]`
+		lock.Unlock()
+
+		networks, err := client.GetNetworksCtx(BypassCache(context.Background()))
+		Expect(err).To(BeNil())
+		Expect(networks[0].Name).To(Equal("renamed"))
+
+		// The fresh response replaced the cached one
+		networks, err = client.GetNetworks()
+		Expect(err).To(BeNil())
+		Expect(networks[0].Name).To(Equal("renamed"))
+		Expect(count("GET /networks")).To(Equal(2))
+	})
+})
@@ -6,10 +6,16 @@ package client
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,35 +26,124 @@ const (
 	TypeNamespace ResourceType = iota
 	TypeInstance
 	TypeNetwork
+	TypeArtifact
 )
 
 func (r ResourceType) String() string {
-	return [...]string{"auth/namespaces", "instances", "networks"}[r]
+	return [...]string{"auth/namespaces", "instances", "networks", "artifacts"}[r]
 }
 
 // Client holds all of the information required to connect to
 // the server
+//
+// Every API method has a variant with a "Ctx" suffix which takes a
+// context.Context as its first argument. The context governs the whole
+// call, including any auth token refresh and decoding of the response body.
+// The methods without the suffix use context.Background().
+//
+// A Client is safe for concurrent use by multiple goroutines once it has
+// been configured.
 type Client struct {
 	server_url string
 	httpClient *http.Client
 	namespace  string
 	apiKey     string
-	cachedAuth string
+
+	// Auth token state, guarded by authLock. authRefresh is non-nil while
+	// a token refresh is in flight and is closed when it completes.
+	authLock    sync.Mutex
+	cachedAuth  string
+	authExpiry  time.Time
+	authMargin  time.Duration
+	authRefresh chan struct{}
+	authErr     error
+
+	retryPolicy *RetryPolicy
+	userAgent   string
+	headers     http.Header
+	basePath    string
+	logger      Logger
+
+	requestLogger RequestLogger
+	debugLogging  bool
+	metrics       MetricsCollector
+
+	// What we have learnt about the server, guarded by capLock. infoFetch
+	// is a fetch of the server info in progress, and infoErr why the last
+	// one failed, which is returned without asking again until infoRetry.
+	capLock     sync.Mutex
+	queryParams support
+	serverInfo  *ServerInfo
+	infoFetch   *infoFetch
+	infoErr     error
+	infoRetry   time.Time
+
+	// Cached GET responses, nil unless enabled
+	cache *responseCache
+
+	// GET requests in progress, nil if coalescing is off
+	flights *flightGroup
+
+	// Rate limits, throttle is built from the options
+	globalLimit *Limit
+	routeLimits map[RouteClass]Limit
+	throttle    *throttle
+
+	// Polling used by the wait helpers, nil for DefaultWaitOptions
+	waitOptions *WaitOptions
+
+	// Polling used by StreamConsole, nil for DefaultConsoleOptions
+	consoleOptions *ConsoleOptions
+
+	// Instances seen in a terminal state
+	instanceStates terminalStates
+
+	// API servers, server_url is the first of them
+	endpoints        *endpointPool
+	extraEndpoints   []string
+	endpointStrategy EndpointStrategy
+	endpointCoolDown time.Duration
+
+	// Settings applied to httpClient by configureTransport
+	transport http.RoundTripper
+	timeout   *time.Duration
+	tlsConfig *tls.Config
+	tls       TLSSettings
+	proxy     *url.URL
+
+	// Unix socket paths, by the placeholder host used for them
+	sockets map[string]string
+
+	// initErr is why NewClient could not set up the client. Every request
+	// fails with it.
+	initErr error
 }
 
+// errInvalidClient is returned by every call on a client which NewClient
+// could not set up.
+var errInvalidClient = errors.New("cannot create client")
+
 // NewClient returns a Shaken Fist client.
 //
 // The server_url string should be the base URL of the server including
 // the port number: "http://<server>:<port>"  eg. "http://sf-1:13000".
 // (Standard port for the Shaken Fist API server is 13000.)
+//
+// Use NewClientWithOptions for more control over how the client connects.
+// If server_url cannot be used, every call on the client fails with the
+// reason.
 func NewClient(server_url string, namespace, apiKey string) *Client {
-
-	return &Client{
-		server_url: server_url,
-		httpClient: &http.Client{},
-		namespace:  namespace,
-		apiKey:     apiKey,
+	c, err := NewClientWithOptions(server_url, namespace, apiKey)
+	if err != nil {
+		return &Client{
+			server_url: server_url,
+			httpClient: &http.Client{},
+			namespace:  namespace,
+			apiKey:     apiKey,
+			initErr:    fmt.Errorf("%w: %v", errInvalidClient, err),
+		}
 	}
+	return c
 }
 
 // SetTimeout sets the HTTP client timeout in seconds.
@@ -62,125 +157,250 @@ func (c *Client) SetTimeout(timeout int) {
 // Internal helper functions
 //
 
-func (c *Client) getRequest(
+func (c *Client) getRequest(ctx context.Context,
 	object, uuid string, cmd string, resp interface{}) error {
 
-	err := c.doRequestJSON(object+"/"+uuid+"/"+cmd, "GET", bytes.Buffer{}, resp)
+	err := c.doRequestJSON(ctx, object+"/"+uuid+"/"+cmd, "GET", nil, resp)
 	return err
 }
 
-func (c *Client) postRequest(object string, uuid string, cmd string) error {
-	err := c.doRequestJSON(object+"/"+uuid+"/"+cmd, "POST", bytes.Buffer{}, nil)
+func (c *Client) postRequest(ctx context.Context,
+	object string, uuid string, cmd string) error {
+
+	err := c.doRequestJSON(ctx, object+"/"+uuid+"/"+cmd, "POST", nil, nil)
 	return err
 }
 
-func (c *Client) doRequestJSON(
-	path, method string, data bytes.Buffer, resp interface{}) error {
+// doRequestJSON sends data, if it is not nil, as a JSON request body and
+// decodes the JSON response into resp, if it is not nil.
+func (c *Client) doRequestJSON(ctx context.Context,
+	path, method string, data interface{}, resp interface{}) error {
 
-	body, err := c.doRequest(path, method, data)
-	if err != nil {
-		return fmt.Errorf("request error: %v", err)
-	}
+	return c.sendJSON(ctx, newAPIRequest(method, path).withBody(data), resp)
+}
 
-	// Check if JSON decoding is required
-	if resp != nil {
-		err = json.NewDecoder(body).Decode(resp)
+// doRequest sends a request, retrying as the retry policy allows. The body
+// is reopened for every attempt.
+func (c *Client) doRequest(ctx context.Context,
+	path, method string, data *requestBody) (io.ReadCloser, error) {
+
+	if c.initErr != nil {
+		return nil, c.initErr
 	}
 
-	return err
+	// Retries and the auth refresh are part of the same call
+	ctx = ensureRequestID(ctx)
+
+	policy := c.retryPolicy
+	for attempt := 1; ; attempt++ {
+		token, err := c.authToken(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get auth token: %w", err)
+		}
+
+		attemptCtx := withAttempt(ctx, attempt)
+		body, statusCode, err := c.httpRequest(attemptCtx, path, method, data, token)
+
+		// If auth token has expired, then get a new token
+		if statusCode == http.StatusUnauthorized {
+			c.invalidateAuth(token)
+			token, err = c.authToken(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to refresh auth token: %w", err)
+			}
+
+			// Try with new token, if second error occurs it is returned
+			body, statusCode, err = c.httpRequest(attemptCtx, path, method, data, token)
+		}
+
+		retry := policy.shouldRetry(ctx, method, path, attempt, statusCode, err)
+		var delay time.Duration
+		if retry {
+			delay = policy.backoff(attempt, retryAfter(err))
+		}
+		policy.report(RetryAttempt{
+			Method:     method,
+			Path:       path,
+			Attempt:    attempt,
+			StatusCode: statusCode,
+			Err:        err,
+			WillRetry:  retry,
+			Delay:      delay,
+		})
+
+		if err == nil {
+			if method != "GET" {
+				c.cache.invalidate(path)
+				c.flights.forget(path)
+			}
+			return body, nil
+		}
+		if !retry {
+			return nil, fmt.Errorf("httpRequest error: %w", err)
+		}
+		c.logf("retrying %s %s in %v after attempt %d failed: %v",
+			method, path, delay, attempt, err)
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, fmt.Errorf("httpRequest error: %w", err)
+		}
+	}
 }
 
-func (c *Client) doRequest(
-	path, method string, data bytes.Buffer) (io.ReadCloser, error) {
+// httpRequest sends a single request to one of the API servers. Requests
+// which cannot connect are tried against the other servers in turn.
+func (c *Client) httpRequest(ctx context.Context, path, method string,
+	body *requestBody, token string) (io.ReadCloser, int, error) {
 
-	if c.cachedAuth == "" {
-		err := c.requestAuth()
+	for tried := 1; ; tried++ {
+		release, err := c.throttle.wait(ctx, routeClass(method, path))
 		if err != nil {
-			return nil, fmt.Errorf("unable to get auth token: %v", err)
+			return nil, 0, err
 		}
-	}
 
-	body, statusCode, err := c.httpRequest(path, method, data)
+		ep := c.endpoints.pick()
+		start := time.Now()
+		respBody, statusCode, err := c.sendRequest(ctx, ep.url, path, method,
+			body, token)
+		c.observeRequest(ctx, ep.url, path, method, statusCode, time.Since(start))
 
-	// If auth token has expired, then get a new token
-	if statusCode == http.StatusUnauthorized {
-		if c.requestAuth() != nil {
-			return nil, fmt.Errorf("unable to refresh auth token: %v", err)
+		if respBody == nil {
+			release()
+		} else {
+			respBody = &releasingBody{ReadCloser: respBody, release: release}
 		}
 
-		// Try with new token, if second error occurs it is returned
-		body, _, err = c.httpRequest(path, method, data)
-	}
+		switch {
+		case err != nil && statusCode == 0:
+			// A request abandoned by the caller, or with a body which
+			// cannot be sent, says nothing about the server
+			if ctx.Err() == nil && !isBodyError(err) {
+				c.endpoints.markFailed(ep)
+			}
+		case statusCode == http.StatusBadGateway ||
+			statusCode == http.StatusServiceUnavailable ||
+			statusCode == http.StatusGatewayTimeout:
+			c.endpoints.markFailed(ep)
+		default:
+			c.endpoints.markHealthy(ep)
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("httpRequest error: %v", err)
+		if err != nil && isDialError(err) && tried < c.endpoints.size() &&
+			ctx.Err() == nil {
+			c.logf("unable to connect to %s, trying another endpoint: %v",
+				ep.url, err)
+			continue
+		}
+		return respBody, statusCode, err
 	}
-
-	return body, nil
 }
 
-func (c *Client) httpRequest(
-	path, method string, body bytes.Buffer) (io.ReadCloser, int, error) {
+// sendRequest sends a request to the API server at baseURL.
+func (c *Client) sendRequest(ctx context.Context, baseURL, path, method string,
+	body *requestBody, token string) (io.ReadCloser, int, error) {
 
-	req, err := http.NewRequest(method, c.server_url+"/"+path, &body)
+	req, err := http.NewRequestWithContext(ctx, method, c.url(baseURL, path), nil)
 	if err != nil {
 		return nil, 0, err
 	}
+	if err := body.setBody(req); err != nil {
+		return nil, 0, err
+	}
 
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", c.cachedAuth)
+	for key, values := range c.headers {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	req.Header.Set("Content-Type", body.contentTypeOr("application/json"))
+	req.Header.Set("Authorization", token)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	requestID := RequestIDFrom(ctx)
+	if requestID != "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+	ex := cacheExchangeFrom(ctx)
+	if ex != nil && ex.ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ex.ifNoneMatch)
+	}
 
+	entry := RequestLogEntry{
+		Method:      method,
+		Path:        path,
+		Endpoint:    baseURL,
+		Attempt:     attemptFrom(ctx),
+		RequestID:   requestID,
+		RequestSize: body.size(),
+	}
+	if c.debugLogging {
+		entry.RequestHeaders = redactHeaders(req.Header)
+		entry.RequestBody = body.logged()
+	}
+
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
+	entry.Duration = time.Since(start)
 	if err != nil {
-		return nil, 0, fmt.Errorf("unable to connect to server: %v", err)
+		err = fmt.Errorf("unable to connect to server: %w", err)
+		entry.Err = err
+		c.logRequest(entry)
+		recordRequestID(ctx, RequestID{Client: requestID})
+		return nil, 0, err
+	}
+	entry.StatusCode = resp.StatusCode
+	recordRequestID(ctx, RequestID{Client: requestID,
+		Server: serverRequestID(resp)})
+
+	// The cached copy is still current
+	if resp.StatusCode == http.StatusNotModified && ex != nil &&
+		ex.ifNoneMatch != "" {
+
+		resp.Body.Close()
+		ex.notModified = true
+		c.logRequest(entry)
+		return ioutil.NopCloser(strings.NewReader("")), resp.StatusCode, nil
+	}
+	if ex != nil {
+		ex.etag = resp.Header.Get("ETag")
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
 		respBody := new(bytes.Buffer)
 		_, err := respBody.ReadFrom(resp.Body)
+		var apiErr *APIError
 		if err != nil {
-			return nil, resp.StatusCode,
-				fmt.Errorf("received non 200 status code: %v",
-					resp.StatusCode)
+			apiErr = newAPIError(method, path, resp, nil)
+		} else {
+			apiErr = newAPIError(method, path, resp, respBody.Bytes())
 		}
-		return nil, resp.StatusCode,
-			fmt.Errorf("received non 200 status code: %v - %s",
-				resp.StatusCode, respBody.String())
-	}
-	return resp.Body, 0, nil
-}
-
-type authRequest struct {
-	Namespace string `json:"namespace"`
-	APIKey    string `json:"key"`
-}
+		apiErr.ClientRequestID = requestID
 
-type authResponse struct {
-	Token string `json:"access_token"`
-}
+		entry.ResponseSize = int64(respBody.Len())
+		entry.Err = apiErr
+		if c.debugLogging {
+			entry.ResponseBody = redactBody(respBody.Bytes())
+		}
+		c.logRequest(entry)
 
-func (c *Client) requestAuth() error {
-	req := &authRequest{
-		Namespace: c.namespace,
-		APIKey:    c.apiKey,
-	}
-	post, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("unable to marshal auth request: %v", err)
+		return nil, resp.StatusCode, apiErr
 	}
 
-	body, _, err := c.httpRequest("auth", "POST", *bytes.NewBuffer(post))
-	if err != nil {
-		return fmt.Errorf("auth request failed: %v", err)
+	if c.requestLogger == nil {
+		return resp.Body, resp.StatusCode, nil
 	}
-
-	resp := authResponse{}
-	err = json.NewDecoder(body).Decode(&resp)
-	if err != nil {
-		return fmt.Errorf("unable to decode response body: %v", err)
+	logged := &loggedBody{ReadCloser: resp.Body, client: c, entry: entry}
+	if c.debugLogging {
+		logged.capture = &strings.Builder{}
 	}
+	return logged, resp.StatusCode, nil
+}
 
-	c.cachedAuth = fmt.Sprintf("Bearer %s", resp.Token)
-
-	return nil
+// url returns the full URL of an API path on the server at baseURL.
+func (c *Client) url(baseURL, path string) string {
+	if c.basePath != "" {
+		return baseURL + "/" + c.basePath + "/" + path
+	}
+	return baseURL + "/" + path
 }
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
@@ -26,3 +28,32 @@ var _ = BeforeEach(func() {
 	// Remove any mocks
 	httpmock.Reset()
 })
+
+// withTestAuth answers token requests as an API server does, and passes
+// every other request to handler.
+func withTestAuth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth" {
+			w.Write([]byte(`{"access_token":"token"}`))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// newTestServer starts a local API server which answers token requests and
+// passes every other request to handler.
+func newTestServer(handler http.HandlerFunc) *httptest.Server {
+	return httptest.NewServer(withTestAuth(handler))
+}
+
+// newTestClient returns a client of a local API server. It bypasses
+// httpmock, which replaces the default transport, and does not retry, so
+// tests see each failure as it happens. opts are applied after those.
+func newTestClient(url string, opts ...Option) (*Client, error) {
+	opts = append([]Option{
+		WithTransport(&http.Transport{}),
+		WithRetryPolicy(nil),
+	}, opts...)
+	return NewClientWithOptions(url, "testspace", "testkey", opts...)
+}
@@ -1,7 +1,7 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -70,7 +70,7 @@ var _ = Describe("Auth request", func() {
 			})
 
 		// Make auth request
-		err := client.requestAuth()
+		err := client.requestAuth(context.Background())
 		Expect(err).To(BeNil())
 
 		Expect(client.cachedAuth).To(Equal("Bearer ABC123"))
@@ -103,7 +103,7 @@ var _ = Describe("Auth caching", func() {
 	It("should get and store auth token", func() {
 
 		// Make auth request
-		err := client.requestAuth()
+		err := client.requestAuth(context.Background())
 		Expect(err).To(BeNil())
 
 		Expect(client.cachedAuth).To(Equal("Bearer ABC123"))
@@ -116,7 +116,7 @@ var _ = Describe("Auth caching", func() {
 	It("should get and store auth token", func() {
 
 		// Make auth request
-		err := client.requestAuth()
+		err := client.requestAuth(context.Background())
 		Expect(err).To(BeNil())
 
 		Expect(client.cachedAuth).To(Equal("Bearer ABC123"))
@@ -137,14 +137,14 @@ var _ = Describe("Auth caching", func() {
 		httpmock.RegisterResponder("GET", test_url+"/instances",
 			httpmock.NewBytesResponder(200, jsonResp))
 
-		err = client.doRequestJSON("instances", "GET", bytes.Buffer{}, &instances)
+		err = client.doRequestJSON(context.Background(), "instances", "GET", nil, &instances)
 		Expect(err).To(BeNil())
 
 		// Make second request, expecting auth token to be cached
 		httpmock.RegisterResponder("GET", test_url+"/instances",
 			httpmock.NewBytesResponder(200, jsonResp))
 
-		err = client.doRequestJSON("instances", "GET", bytes.Buffer{}, &instances)
+		err = client.doRequestJSON(context.Background(), "instances", "GET", nil, &instances)
 		Expect(err).To(BeNil())
 
 		// Check auth request was made only once
@@ -184,7 +184,7 @@ var _ = Describe("Helper functions", func() {
 			httpmock.NewBytesResponder(200, jsonResp))
 
 		// Make client request
-		err = client.getRequest("instances", "123-456", "cmd", &instances)
+		err = client.getRequest(context.Background(), "instances", "123-456", "cmd", &instances)
 		Expect(err).To(BeNil())
 
 		// Check correct URL requested
@@ -199,7 +199,7 @@ var _ = Describe("Helper functions", func() {
 			httpmock.NewBytesResponder(200, nil))
 
 		// Make client request
-		err := client.postRequest("instances", "123-456", "cmd")
+		err := client.postRequest(context.Background(), "instances", "123-456", "cmd")
 		Expect(err).To(BeNil())
 
 		// Check correct URL requested
@@ -214,7 +214,79 @@ var _ = Describe("Helper functions", func() {
 			httpmock.NewBytesResponder(200, nil))
 
 		// Make client request
-		err := client.postRequest("wrong", "123-456", "cmd")
+		err := client.postRequest(context.Background(), "wrong", "123-456", "cmd")
 		Expect(err).ToNot(BeNil())
 	})
 })
+
+var _ = Describe("Context handling", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	var (
+		client *Client
+	)
+
+	BeforeEach(func() {
+		// Configure client
+		client = NewClient(test_url, test_namespace, test_key)
+
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewBytesResponder(200, []byte(`{"access_token":"ABC123"}`)))
+	})
+
+	It("should pass the context through to the HTTP request", func() {
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "marker")
+
+		httpmock.RegisterResponder("GET", test_url+"/instances",
+			func(req *http.Request) (*http.Response, error) {
+				Expect(req.Context().Value(ctxKey{})).To(Equal("marker"))
+				return httpmock.NewStringResponse(200, `[]`), nil
+			})
+
+		_, err := client.GetInstancesCtx(ctx)
+		Expect(err).To(BeNil())
+	})
+
+	It("should abandon a request when the deadline passes", func() {
+		httpmock.RegisterResponder("GET", test_url+"/instances/123-456",
+			func(req *http.Request) (*http.Response, error) {
+				// Simulate a hung server
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			})
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := client.GetInstanceCtx(ctx, "123-456")
+		Expect(err).ToNot(BeNil())
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+
+	It("should abandon the auth request when the context is cancelled", func() {
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			func(req *http.Request) (*http.Response, error) {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+
+		_, err := client.GetNetworksCtx(ctx)
+		Expect(err).ToNot(BeNil())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["GET "+test_url+"/networks"]).To(Equal(0))
+	})
+})
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// CoalesceStats describe how many GET requests were shared between callers.
+type CoalesceStats struct {
+	// Requests is the number of GET calls made
+	Requests int64
+
+	// Saved is the number of calls which used another call's request rather
+	// than making their own
+	Saved int64
+}
+
+// CoalescedRequestCollector is implemented by metrics collectors which also
+// count the requests saved by coalescing.
+type CoalescedRequestCollector interface {
+	ObserveCoalesced(route string)
+}
+
+// WithRequestCoalescing says whether identical GET requests made at the
+// same time share one request to the server. Each caller still gets its own
+// decoded copy of the response. Coalescing is off by default.
+//
+// Once a change to a resource succeeds, such as DeleteNetwork or
+// SetMetadata, GET requests for that resource, the paths below it and the
+// lists which include it no longer join requests sent before the change.
+func WithRequestCoalescing(enabled bool) Option {
+	return func(c *Client) error {
+		if enabled {
+			c.flights = newFlightGroup()
+		} else {
+			c.flights = nil
+		}
+		return nil
+	}
+}
+
+// CoalesceStats returns how many GET requests were shared between callers.
+func (c *Client) CoalesceStats() CoalesceStats {
+	if c.flights == nil {
+		return CoalesceStats{}
+	}
+
+	c.flights.lock.Lock()
+	defer c.flights.lock.Unlock()
+	return c.flights.stats
+}
+
+// fetched is a GET response body read in full.
+type fetched struct {
+	data []byte
+	etag string
+}
+
+// flight is a GET request which other callers can wait for.
+type flight struct {
+	done chan struct{}
+	resp *fetched
+	err  error
+	id   RequestID
+}
+
+// flightGroup tracks the GET requests in progress.
+type flightGroup struct {
+	lock    sync.Mutex
+	flights map[string]*flight
+	stats   CoalesceStats
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: map[string]*flight{}}
+}
+
+// fetch makes the GET request described by r and reads the response. If the
+// same request is already in progress it waits for that one instead.
+func (c *Client) fetch(ctx context.Context, r *apiRequest) (*fetched, error) {
+	g := c.flights
+	if g == nil {
+		return c.fetchOnce(ctx, r)
+	}
+
+	key := r.target()
+	g.lock.Lock()
+	g.stats.Requests++
+	if f, ok := g.flights[key]; ok {
+		g.stats.Saved++
+		g.lock.Unlock()
+		if m, ok := c.metrics.(CoalescedRequestCollector); ok {
+			m.ObserveCoalesced(routeTemplate(r.path))
+		}
+
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("request error: %w", ctx.Err())
+		}
+
+		// The request was abandoned by the caller who made it, not us
+		if errors.Is(f.err, context.Canceled) ||
+			errors.Is(f.err, context.DeadlineExceeded) {
+			return c.fetch(ctx, r)
+		}
+		recordRequestID(ctx, f.id)
+		return f.resp, f.err
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.lock.Unlock()
+
+	fetchCtx, id := CaptureRequestID(ctx)
+	f.resp, f.err = c.fetchOnce(fetchCtx, r)
+	f.id = *id
+	recordRequestID(ctx, f.id)
+
+	g.lock.Lock()
+	// A change since the request was sent may have replaced the flight
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+	g.lock.Unlock()
+	close(f.done)
+
+	return f.resp, f.err
+}
+
+// forget stops later GET requests joining those in progress which are
+// affected by a change to the resource path is for. Callers already
+// waiting on them still get their results. A nil group does nothing.
+func (g *flightGroup) forget(path string) {
+	if g == nil {
+		return
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	res := resourcePath(path)
+	for key := range g.flights {
+		if affects(res, key) {
+			delete(g.flights, key)
+		}
+	}
+}
+
+// fetchOnce makes the GET request described by r and reads the response.
+func (c *Client) fetchOnce(ctx context.Context, r *apiRequest) (*fetched, error) {
+	ex := &cacheExchange{}
+	respBody, err := c.send(withCacheExchange(ctx, ex), r)
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+	defer respBody.Close()
+
+	data, err := ioutil.ReadAll(respBody)
+	if err != nil {
+		return nil, err
+	}
+	return &fetched{data: data, etag: ex.etag}, nil
+}
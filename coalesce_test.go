@@ -0,0 +1,268 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request coalescing", func() {
+	const (
+		test_uuid string = "a7d2a7a2-2ad3-4d41-8a34-3f5f1b6a2e90"
+		callers   int    = 8
+	)
+
+	var (
+		server  *httptest.Server
+		release chan struct{}
+
+		lock    sync.Mutex
+		fetches int
+	)
+
+	BeforeEach(func() {
+		release = make(chan struct{})
+		fetches = 0
+
+		server = newTestServer(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "GET" {
+					w.Write([]byte(`null`))
+					return
+				}
+
+				lock.Lock()
+				fetches++
+				lock.Unlock()
+
+				<-release
+				w.Write([]byte(`{"uuid": "` + test_uuid + `", "name": "vm",
+					"block_devices": {"devices": []},
+					"network_interfaces": [{"uuid": "iface", "ipv4": "10.0.0.5"}]}`))
+			})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func(opts ...Option) *Client {
+		opts = append([]Option{WithRequestCoalescing(true)}, opts...)
+		client, err := newTestClient(server.URL, opts...)
+		Expect(err).To(BeNil())
+		_, err = client.authToken(context.Background())
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	// getInstances calls GetInstance from each caller once they have all
+	// joined the same request.
+	getInstances := func(client *Client) []Instance {
+		results := make([]Instance, callers)
+		var wg sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				instance, err := client.GetInstance(test_uuid)
+				Expect(err).To(BeNil())
+				results[i] = instance
+			}(i)
+		}
+
+		Eventually(func() int64 {
+			return client.CoalesceStats().Requests
+		}).Should(Equal(int64(callers)))
+		close(release)
+		wg.Wait()
+		return results
+	}
+
+	It("should share one request between identical callers", func() {
+		metrics := NewPrometheusCollector()
+		client := newClient(WithMetrics(metrics))
+
+		results := getInstances(client)
+		lock.Lock()
+		Expect(fetches).To(Equal(1))
+		lock.Unlock()
+		Expect(client.CoalesceStats()).To(Equal(CoalesceStats{
+			Requests: int64(callers),
+			Saved:    int64(callers - 1),
+		}))
+		Expect(metrics.String()).To(ContainSubstring(
+			`shakenfist_client_coalesced_requests_total{route="instances/{uuid}"} 7`))
+
+		for _, instance := range results {
+			Expect(instance.Name).To(Equal("vm"))
+		}
+	})
+
+	It("should give every caller its own copy", func() {
+		results := getInstances(newClient())
+
+		first := &results[0]
+		first.Name = "changed"
+		first.NetworkInterfaces[0].IPv4 = "192.168.0.1"
+		first.BlockDevices["devices"] = "changed"
+
+		for _, instance := range results[1:] {
+			Expect(instance.Name).To(Equal("vm"))
+			Expect(instance.NetworkInterfaces[0].IPv4).To(Equal("10.0.0.5"))
+			Expect(instance.BlockDevices["devices"]).To(Equal([]interface{}{}))
+		}
+	})
+
+	It("should not coalesce requests which are not identical", func() {
+		client := newClient()
+
+		var wg sync.WaitGroup
+		for _, uuid := range []string{"a", "b", "c"} {
+			wg.Add(1)
+			go func(uuid string) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := client.GetInstance(uuid)
+				Expect(err).To(BeNil())
+			}(uuid)
+		}
+
+		Eventually(func() int {
+			lock.Lock()
+			defer lock.Unlock()
+			return fetches
+		}).Should(Equal(3))
+		close(release)
+		wg.Wait()
+		Expect(client.CoalesceStats().Saved).To(BeZero())
+	})
+
+	It("should make its own request when the first caller gives up", func() {
+		client := newClient()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			_, err := client.GetInstanceCtx(ctx, test_uuid)
+			done <- err
+		}()
+		Eventually(func() int64 {
+			return client.CoalesceStats().Requests
+		}).Should(Equal(int64(1)))
+
+		result := make(chan Instance)
+		go func() {
+			defer GinkgoRecover()
+			instance, err := client.GetInstance(test_uuid)
+			Expect(err).To(BeNil())
+			result <- instance
+		}()
+		Eventually(func() int64 {
+			return client.CoalesceStats().Saved
+		}).Should(Equal(int64(1)))
+
+		cancel()
+		Expect(<-done).To(MatchError(ContainSubstring("context canceled")))
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		Expect((<-result).Name).To(Equal("vm"))
+
+		lock.Lock()
+		defer lock.Unlock()
+		Expect(fetches).To(Equal(2))
+	})
+
+	It("should not join a request sent before a change", func() {
+		client := newClient()
+
+		var wg sync.WaitGroup
+		get := func() {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := client.GetInstance(test_uuid)
+				Expect(err).To(BeNil())
+			}()
+		}
+		waitForFetches := func(n int) {
+			Eventually(func() int {
+				lock.Lock()
+				defer lock.Unlock()
+				return fetches
+			}).Should(Equal(n))
+		}
+
+		get()
+		waitForFetches(1)
+		Expect(client.PowerOffInstance(test_uuid)).To(Succeed())
+		get()
+		waitForFetches(2)
+
+		// Later callers join the request sent after the change
+		get()
+		Eventually(func() int64 {
+			return client.CoalesceStats().Saved
+		}).Should(Equal(int64(1)))
+
+		close(release)
+		wg.Wait()
+		lock.Lock()
+		defer lock.Unlock()
+		Expect(fetches).To(Equal(2))
+	})
+
+	It("should not coalesce by default", func() {
+		client, err := newTestClient(server.URL)
+		Expect(err).To(BeNil())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := client.GetInstance(test_uuid)
+				Expect(err).To(BeNil())
+			}()
+		}
+
+		Eventually(func() int {
+			lock.Lock()
+			defer lock.Unlock()
+			return fetches
+		}).Should(Equal(2))
+		close(release)
+		wg.Wait()
+	})
+
+	It("should not coalesce when turned off", func() {
+		client := newClient(WithRequestCoalescing(false))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := client.GetInstance(test_uuid)
+				Expect(err).To(BeNil())
+			}()
+		}
+
+		Eventually(func() int {
+			lock.Lock()
+			defer lock.Unlock()
+			return fetches
+		}).Should(Equal(3))
+		close(release)
+		wg.Wait()
+		Expect(client.CoalesceStats()).To(Equal(CoalesceStats{}))
+	})
+})
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ConsoleOptions control how StreamConsole polls the console of an
+// instance.
+type ConsoleOptions struct {
+	// Interval is the time between polls
+	Interval time.Duration
+
+	// Window is how many bytes from the end of the console are fetched by
+	// each poll. More output than this between polls is lost.
+	Window int
+}
+
+// DefaultConsoleOptions returns the polling used unless WithConsolePolling
+// is given.
+func DefaultConsoleOptions() ConsoleOptions {
+	return ConsoleOptions{
+		Interval: time.Second,
+		Window:   64 * 1024,
+	}
+}
+
+// WithConsolePolling sets how StreamConsole polls the console of an
+// instance.
+func WithConsolePolling(opts ConsoleOptions) Option {
+	return func(c *Client) error {
+		switch {
+		case opts.Interval <= 0:
+			return fmt.Errorf("invalid console polling: interval %v is not "+
+				"positive", opts.Interval)
+		case opts.Window <= 0:
+			return fmt.Errorf("invalid console polling: window %d is not "+
+				"positive", opts.Window)
+		}
+		c.consoleOptions = &opts
+		return nil
+	}
+}
+
+// consoleStream is the reader returned by StreamConsole.
+type consoleStream struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Close stops polling.
+func (s *consoleStream) Close() error {
+	s.cancel()
+	err := s.PipeReader.Close()
+	<-s.done
+	return err
+}
+
+// StreamConsole follows the console of an instance, like tail -f. Reads
+// return the end of the console as it is when the stream starts, and then
+// output as it is written. If the console is truncated or rotated, the
+// stream carries on from the start of the new console.
+//
+// Errors from the server which may be temporary are retried. Reads fail
+// with the error which ended the stream once ctx is done or the server
+// refuses the request. Close stops polling. Polling is set with
+// WithConsolePolling.
+//
+//	console := c.StreamConsole(ctx, uuid)
+//	defer console.Close()
+//	io.Copy(os.Stdout, console)
+func (c *Client) StreamConsole(ctx context.Context, uuid string) io.ReadCloser {
+	opts := DefaultConsoleOptions()
+	if c.consoleOptions != nil {
+		opts = *c.consoleOptions
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r, w := io.Pipe()
+	s := &consoleStream{PipeReader: r, cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(s.done)
+		w.CloseWithError(c.streamConsole(ctx, w, uuid, opts))
+	}()
+	return s
+}
+
+// streamConsole polls the console until ctx is done or it fails with an
+// error which will not go away.
+func (c *Client) streamConsole(ctx context.Context, w io.Writer,
+	uuid string, opts ConsoleOptions) error {
+
+	// The console must come from the server, not the response cache
+	pollCtx := BypassCache(ctx)
+
+	var prev []byte
+	for {
+		data, err := c.GetConsoleDataCtx(pollCtx, uuid, opts.Window)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !watchRetryable(err) {
+			return err
+		}
+
+		if err == nil {
+			cur := []byte(data)
+			delta, restarted := consoleDelta(prev, cur)
+			if restarted {
+				c.logf("console of instance %s does not follow on from the "+
+					"last poll, it was truncated or rotated, or more was "+
+					"written than one poll returns", uuid)
+			}
+			if len(delta) > 0 {
+				if _, err := w.Write(delta); err != nil {
+					return err
+				}
+			}
+			prev = cur
+		}
+
+		if err := sleepCtx(ctx, opts.Interval); err != nil {
+			return err
+		}
+	}
+}
+
+// consoleDelta returns the output in cur, the end of the console, which
+// follows prev, the end of the console when last polled. If cur does not
+// follow on from prev the console has been truncated or rotated, or more
+// was written than one poll returns, and all of cur is returned.
+//
+// Output is matched by content, so when the new output repeats the end of
+// the old some of it may be taken as already seen. Servers may return less
+// than the window asked for, so the length of cur says nothing about
+// whether it is the whole console.
+func consoleDelta(prev, cur []byte) ([]byte, bool) {
+	if len(prev) == 0 {
+		return cur, false
+	}
+
+	// A console which has shrunk has been truncated
+	if len(cur) < len(prev) {
+		return cur, true
+	}
+
+	overlap := suffixPrefix(prev, cur)
+	if overlap == 0 {
+		return cur, true
+	}
+	return cur[overlap:], false
+}
+
+// suffixPrefix returns the length of the longest suffix of a which is a
+// prefix of b, using the Knuth-Morris-Pratt failure function of b.
+func suffixPrefix(a, b []byte) int {
+	if len(b) == 0 {
+		return 0
+	}
+
+	fail := make([]int, len(b))
+	for i, k := 1, 0; i < len(b); i++ {
+		for k > 0 && b[i] != b[k] {
+			k = fail[k-1]
+		}
+		if b[i] == b[k] {
+			k++
+		}
+		fail[i] = k
+	}
+
+	matched := 0
+	for _, ch := range a {
+		if matched == len(b) {
+			matched = fail[matched-1]
+		}
+		for matched > 0 && ch != b[matched] {
+			matched = fail[matched-1]
+		}
+		if ch == b[matched] {
+			matched++
+		}
+	}
+	return matched
+}
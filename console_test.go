@@ -0,0 +1,245 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Console streaming", func() {
+	const (
+		test_uuid string = "9a3f1e2d-5b6c-4d7e-8f90-1a2b3c4d5e6f"
+		window    int    = 32
+	)
+
+	var (
+		server *httptest.Server
+
+		// console is the whole console log, of which the server returns the
+		// last length bytes. A status in failures answers that poll instead.
+		lock     sync.Mutex
+		console  string
+		failures map[int]int
+		polls    int
+	)
+
+	BeforeEach(func() {
+		console = ""
+		failures = map[int]int{}
+		polls = 0
+
+		server = newTestServer(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/" {
+					w.Write([]byte(`{"capabilities": ["query-parameters"]}`))
+					return
+				}
+				Expect(r.URL.Path).To(Equal("/instances/" + test_uuid + "/consoledata"))
+				length, err := strconv.Atoi(r.URL.Query().Get("length"))
+				Expect(err).To(BeNil())
+
+				lock.Lock()
+				defer lock.Unlock()
+				polls++
+				if status, ok := failures[polls]; ok {
+					w.WriteHeader(status)
+					w.Write([]byte(`{"error": "failed", "status": 0}`))
+					return
+				}
+				data := console
+				if len(data) > length {
+					data = data[len(data)-length:]
+				}
+				w.Write([]byte(data))
+			})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func() *Client {
+		client, err := newTestClient(server.URL,
+			WithConsolePolling(ConsoleOptions{
+				Interval: time.Millisecond,
+				Window:   window,
+			}))
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	// write appends to the console log once the stream has polled it.
+	write := func(output string) {
+		lock.Lock()
+		seen := polls
+		lock.Unlock()
+		Eventually(func() int {
+			lock.Lock()
+			defer lock.Unlock()
+			return polls
+		}).Should(BeNumerically(">", seen))
+
+		lock.Lock()
+		defer lock.Unlock()
+		console += output
+	}
+
+	// replace swaps the console log for another once the stream has polled.
+	replace := func(output string) {
+		write("")
+		lock.Lock()
+		defer lock.Unlock()
+		console = output
+	}
+
+	// readUntil reads from r until it has read want.
+	readUntil := func(r io.Reader, want string) string {
+		got := &bytes.Buffer{}
+		buf := make([]byte, 64)
+		deadline := time.Now().Add(5 * time.Second)
+		for got.Len() < len(want) && time.Now().Before(deadline) {
+			n, err := r.Read(buf)
+			Expect(err).To(BeNil())
+			got.Write(buf[:n])
+		}
+		return got.String()
+	}
+
+	It("should follow the console", func() {
+		console = "login: "
+		stream := newClient().StreamConsole(context.Background(), test_uuid)
+		defer stream.Close()
+
+		Expect(readUntil(stream, "login: ")).To(Equal("login: "))
+		write("root\n")
+		Expect(readUntil(stream, "root\n")).To(Equal("root\n"))
+
+		// The console grows past the window
+		write("Password: \n")
+		Expect(readUntil(stream, "Password: \n")).To(Equal("Password: \n"))
+		write("Welcome to cirros\n")
+		Expect(readUntil(stream, "Welcome to cirros\n")).To(
+			Equal("Welcome to cirros\n"))
+		write("$ ")
+		Expect(readUntil(stream, "$ ")).To(Equal("$ "))
+	})
+
+	It("should start again when the console is truncated", func() {
+		console = "first boot of this instance\n"
+		stream := newClient().StreamConsole(context.Background(), test_uuid)
+		defer stream.Close()
+
+		readUntil(stream, console)
+		replace("reboot\n")
+		Expect(readUntil(stream, "reboot\n")).To(Equal("reboot\n"))
+	})
+
+	It("should start again when the console is rotated", func() {
+		console = strings.Repeat("a", window*2)
+		stream := newClient().StreamConsole(context.Background(), test_uuid)
+		defer stream.Close()
+
+		Expect(readUntil(stream, strings.Repeat("a", window))).To(
+			Equal(strings.Repeat("a", window)))
+		replace(strings.Repeat("b", window*2))
+		Expect(readUntil(stream, strings.Repeat("b", window))).To(
+			Equal(strings.Repeat("b", window)))
+	})
+
+	It("should carry on after errors", func() {
+		console = "one\n"
+		failures[2] = http.StatusInternalServerError
+		failures[3] = http.StatusServiceUnavailable
+		stream := newClient().StreamConsole(context.Background(), test_uuid)
+		defer stream.Close()
+
+		readUntil(stream, "one\n")
+		write("two\n")
+		Expect(readUntil(stream, "two\n")).To(Equal("two\n"))
+	})
+
+	It("should end when the server refuses the stream", func() {
+		console = "one\n"
+		failures[2] = http.StatusNotFound
+		stream := newClient().StreamConsole(context.Background(), test_uuid)
+		defer stream.Close()
+
+		data, err := ioutil.ReadAll(stream)
+		Expect(string(data)).To(Equal("one\n"))
+		Expect(IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should end when the context is done", func() {
+		console = "one\n"
+		ctx, cancel := context.WithCancel(context.Background())
+		stream := newClient().StreamConsole(ctx, test_uuid)
+		defer stream.Close()
+
+		readUntil(stream, "one\n")
+		cancel()
+		_, err := ioutil.ReadAll(stream)
+		Expect(err).To(Equal(context.Canceled))
+	})
+
+	It("should stop polling when closed", func() {
+		console = "one\n"
+		stream := newClient().StreamConsole(context.Background(), test_uuid)
+		readUntil(stream, "one\n")
+		Expect(stream.Close()).To(Succeed())
+
+		lock.Lock()
+		stopped := polls
+		lock.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		lock.Lock()
+		defer lock.Unlock()
+		Expect(polls).To(Equal(stopped))
+	})
+
+	It("should reject polling without an interval or window", func() {
+		for _, opts := range []ConsoleOptions{
+			{Window: window},
+			{Interval: -time.Second, Window: window},
+			{Interval: time.Second},
+			{Interval: time.Second, Window: -1},
+		} {
+			_, err := newTestClient(server.URL, WithConsolePolling(opts))
+			Expect(err).To(MatchError(HavePrefix("invalid console polling: ")))
+		}
+	})
+
+	It("should find where new output starts", func() {
+		for _, t := range []struct {
+			prev, cur, delta string
+			restarted        bool
+		}{
+			{"", "boot", "boot", false},
+			{"boot", "boot", "", false},
+			{"boot", "booting", "ing", false},
+			{"boot", "reboot", "reboot", true},
+			{"booting", "boot", "boot", true},
+			{"abcdefgh", "efghijkl", "ijkl", false},
+			{"abcdef", "cdefgh", "gh", false},
+			{"abcdefgh", "abcdefgh", "", false},
+			{"abcdefgh", "ijklmnop", "ijklmnop", true},
+			{"abcdefgh", "abcd", "abcd", true},
+			{"aaaaaaaa", "aaaaaaab", "b", false},
+		} {
+			delta, restarted := consoleDelta([]byte(t.prev), []byte(t.cur))
+			Expect(string(delta)).To(Equal(t.delta), t.prev+" -> "+t.cur)
+			Expect(restarted).To(Equal(t.restarted), t.prev+" -> "+t.cur)
+		}
+		Expect(suffixPrefix([]byte("abab"), []byte("ababab"))).To(Equal(4))
+		Expect(suffixPrefix([]byte("xaab"), []byte("aaba"))).To(Equal(3))
+	})
+})
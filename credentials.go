@@ -0,0 +1,215 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Environment variables read by EnvironmentProvider.
+const (
+	EnvAPIURL    = "SHAKENFIST_API_URL"
+	EnvNamespace = "SHAKENFIST_NAMESPACE"
+	EnvKey       = "SHAKENFIST_KEY"
+)
+
+// Configuration files read by DefaultCredentialProvider, in order.
+const (
+	UserConfigFile   = "~/.shakenfist"
+	SystemConfigFile = "/etc/sf/client.json"
+)
+
+// DefaultAPIURL is the API URL used by DefaultCredentialProvider when no
+// other is found, as in the Python client.
+const DefaultAPIURL = "http://localhost:13000"
+
+// ErrNoCredentials is returned by a CredentialProvider which has no
+// credentials to offer, for example because its file does not exist.
+var ErrNoCredentials = errors.New("no credentials found")
+
+// Credentials are the details needed to connect to a Shaken Fist cluster.
+// The JSON form matches the configuration files used by the Python client.
+type Credentials struct {
+	APIURL    string `json:"apiurl"`
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+
+	// Token is a pre-issued auth token. If set, it is used until the
+	// server rejects it.
+	Token string `json:"token,omitempty"`
+
+	// TLS holds settings for servers using TLS with a private CA, client
+	// certificates or pinning.
+	TLS *TLSSettings `json:"tls,omitempty"`
+}
+
+// CredentialProvider supplies the credentials for a Client.
+type CredentialProvider interface {
+	Credentials() (Credentials, error)
+}
+
+// EnvironmentProvider reads credentials from the SHAKENFIST_API_URL,
+// SHAKENFIST_NAMESPACE and SHAKENFIST_KEY environment variables. Any of
+// them may be unset.
+type EnvironmentProvider struct{}
+
+// Credentials implements CredentialProvider.
+func (EnvironmentProvider) Credentials() (Credentials, error) {
+	creds := Credentials{
+		APIURL:    os.Getenv(EnvAPIURL),
+		Namespace: os.Getenv(EnvNamespace),
+		Key:       os.Getenv(EnvKey),
+	}
+	if creds == (Credentials{}) {
+		return Credentials{}, ErrNoCredentials
+	}
+	return creds, nil
+}
+
+// FileProvider reads credentials from a JSON configuration file. A leading
+// "~/" in Path is replaced with the home directory of the user.
+type FileProvider struct {
+	Path string
+}
+
+// Credentials implements CredentialProvider.
+func (p FileProvider) Credentials() (Credentials, error) {
+	path, err := expandHome(p.Path)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Credentials{}, ErrNoCredentials
+	}
+	if err != nil {
+		return Credentials{}, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+
+	creds := Credentials{}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	return creds, nil
+}
+
+// StaticProvider always returns the same credentials. It is most useful
+// with a pre-issued Token.
+type StaticProvider Credentials
+
+// Credentials implements CredentialProvider.
+func (p StaticProvider) Credentials() (Credentials, error) {
+	return Credentials(p), nil
+}
+
+// ChainProvider asks each provider in turn, and fills in each field of the
+// credentials from the first provider which sets it. Errors other than
+// ErrNoCredentials stop the search.
+type ChainProvider []CredentialProvider
+
+// Credentials implements CredentialProvider.
+func (chain ChainProvider) Credentials() (Credentials, error) {
+	merged := Credentials{}
+	found := false
+	for _, p := range chain {
+		creds, err := p.Credentials()
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			return Credentials{}, err
+		}
+		found = true
+
+		if merged.APIURL == "" {
+			merged.APIURL = creds.APIURL
+		}
+		if merged.Namespace == "" {
+			merged.Namespace = creds.Namespace
+		}
+		if merged.Key == "" {
+			merged.Key = creds.Key
+		}
+		if merged.Token == "" {
+			merged.Token = creds.Token
+		}
+		if merged.TLS == nil {
+			merged.TLS = creds.TLS
+		}
+	}
+	if !found {
+		return Credentials{}, ErrNoCredentials
+	}
+	return merged, nil
+}
+
+// DefaultCredentialProvider looks for credentials with the same precedence
+// as the Python client. Each field is taken from the environment, then
+// ~/.shakenfist, then /etc/sf/client.json, and the API URL defaults to
+// DefaultAPIURL.
+func DefaultCredentialProvider() CredentialProvider {
+	return ChainProvider{
+		EnvironmentProvider{},
+		FileProvider{Path: UserConfigFile},
+		FileProvider{Path: SystemConfigFile},
+		StaticProvider{APIURL: DefaultAPIURL},
+	}
+}
+
+// NewClientFromProvider returns a client using the credentials from p.
+func NewClientFromProvider(p CredentialProvider, opts ...Option) (*Client, error) {
+	creds, err := p.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load credentials: %w", err)
+	}
+	if creds.APIURL == "" {
+		return nil, errors.New("credentials do not include an API URL")
+	}
+
+	// Options given by the caller win over the credentials
+	if creds.TLS != nil {
+		opts = append([]Option{WithTLS(*creds.TLS)}, opts...)
+	}
+
+	c, err := NewClientWithOptions(creds.APIURL, creds.Namespace, creds.Key,
+		opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if creds.Token != "" {
+		token := creds.Token
+		if !strings.HasPrefix(token, "Bearer ") {
+			token = "Bearer " + token
+		}
+		c.setToken(token, tokenExpiry(strings.TrimPrefix(token, "Bearer ")),
+			time.Now())
+	}
+
+	return c, nil
+}
+
+// NewClientFromEnvironment returns a client using the credentials found by
+// DefaultCredentialProvider.
+func NewClientFromEnvironment(opts ...Option) (*Client, error) {
+	return NewClientFromProvider(DefaultCredentialProvider(), opts...)
+}
+
+// expandHome replaces a leading "~/" in path with the home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find home directory: %w", err)
+	}
+	return filepath.Join(home, path[2:]), nil
+}
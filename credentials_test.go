@@ -0,0 +1,216 @@
+package client
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Credential providers", func() {
+	var (
+		tmpDir  string
+		oldEnv  map[string]string
+		envVars = []string{EnvAPIURL, EnvNamespace, EnvKey, "HOME"}
+	)
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "sfcreds")
+		Expect(err).To(BeNil())
+
+		oldEnv = map[string]string{}
+		for _, v := range envVars {
+			oldEnv[v] = os.Getenv(v)
+		}
+		os.Unsetenv(EnvAPIURL)
+		os.Unsetenv(EnvNamespace)
+		os.Unsetenv(EnvKey)
+		os.Setenv("HOME", tmpDir)
+	})
+
+	AfterEach(func() {
+		for k, v := range oldEnv {
+			os.Setenv(k, v)
+		}
+		os.RemoveAll(tmpDir)
+	})
+
+	It("should read credentials from the environment", func() {
+		os.Setenv(EnvAPIURL, "http://sf-1:13000")
+		os.Setenv(EnvNamespace, "envspace")
+		os.Setenv(EnvKey, "envkey")
+
+		creds, err := EnvironmentProvider{}.Credentials()
+		Expect(err).To(BeNil())
+		Expect(creds).To(Equal(Credentials{
+			APIURL:    "http://sf-1:13000",
+			Namespace: "envspace",
+			Key:       "envkey",
+		}))
+	})
+
+	It("should report missing environment credentials", func() {
+		_, err := EnvironmentProvider{}.Credentials()
+		Expect(errors.Is(err, ErrNoCredentials)).To(BeTrue())
+	})
+
+	It("should read a Python client configuration file", func() {
+		writeFile(".shakenfist",
+			`{"namespace": "filespace", "key": "filekey", "apiurl": "http://sf-2:13000"}`)
+
+		creds, err := FileProvider{Path: "~/.shakenfist"}.Credentials()
+		Expect(err).To(BeNil())
+		Expect(creds).To(Equal(Credentials{
+			APIURL:    "http://sf-2:13000",
+			Namespace: "filespace",
+			Key:       "filekey",
+		}))
+	})
+
+	It("should report a missing configuration file", func() {
+		_, err := FileProvider{Path: filepath.Join(tmpDir, "nope")}.Credentials()
+		Expect(errors.Is(err, ErrNoCredentials)).To(BeTrue())
+	})
+
+	It("should fail on a malformed configuration file", func() {
+		path := writeFile("bad.json", `{"namespace": `)
+		_, err := FileProvider{Path: path}.Credentials()
+		Expect(err).ToNot(BeNil())
+		Expect(errors.Is(err, ErrNoCredentials)).To(BeFalse())
+	})
+
+	It("should use the first provider in a chain with credentials", func() {
+		user := writeFile("user.json",
+			`{"namespace": "userspace", "key": "k", "apiurl": "http://user"}`)
+		system := writeFile("system.json",
+			`{"namespace": "systemspace", "key": "k", "apiurl": "http://system"}`)
+
+		chain := ChainProvider{
+			EnvironmentProvider{},
+			FileProvider{Path: user},
+			FileProvider{Path: system},
+		}
+		creds, err := chain.Credentials()
+		Expect(err).To(BeNil())
+		Expect(creds.Namespace).To(Equal("userspace"))
+
+		os.Setenv(EnvNamespace, "envspace")
+		creds, err = chain.Credentials()
+		Expect(err).To(BeNil())
+		Expect(creds.Namespace).To(Equal("envspace"))
+	})
+
+	It("should fill in each field from the first provider setting it", func() {
+		user := writeFile("user.json", `{"apiurl": "http://user", "key": "userkey"}`)
+		system := writeFile("system.json",
+			`{"namespace": "systemspace", "key": "systemkey", "apiurl": "http://system",
+			  "tls": {"ca_file": "/etc/sf/ca.pem"}}`)
+		os.Setenv(EnvNamespace, "envspace")
+		os.Setenv(EnvKey, "envkey")
+
+		creds, err := ChainProvider{
+			EnvironmentProvider{},
+			FileProvider{Path: user},
+			FileProvider{Path: system},
+		}.Credentials()
+		Expect(err).To(BeNil())
+		Expect(creds).To(Equal(Credentials{
+			APIURL:    "http://user",
+			Namespace: "envspace",
+			Key:       "envkey",
+			TLS:       &TLSSettings{CAFile: "/etc/sf/ca.pem"},
+		}))
+	})
+
+	It("should report a chain where no provider has credentials", func() {
+		_, err := ChainProvider{
+			EnvironmentProvider{},
+			FileProvider{Path: filepath.Join(tmpDir, "nope")},
+		}.Credentials()
+		Expect(errors.Is(err, ErrNoCredentials)).To(BeTrue())
+	})
+
+	It("should stop a chain at a broken provider", func() {
+		bad := writeFile("bad.json", `not json`)
+		good := writeFile("good.json",
+			`{"namespace": "goodspace", "apiurl": "http://good"}`)
+
+		_, err := ChainProvider{
+			FileProvider{Path: bad},
+			FileProvider{Path: good},
+		}.Credentials()
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("should prefer the environment over the user configuration file", func() {
+		writeFile(".shakenfist",
+			`{"namespace": "filespace", "key": "filekey", "apiurl": "http://file"}`)
+
+		creds, err := DefaultCredentialProvider().Credentials()
+		Expect(err).To(BeNil())
+		Expect(creds.Namespace).To(Equal("filespace"))
+
+		os.Setenv(EnvAPIURL, "http://env")
+		os.Setenv(EnvNamespace, "envspace")
+		client, err := NewClientFromEnvironment()
+		Expect(err).To(BeNil())
+		Expect(client.server_url).To(Equal("http://env"))
+		Expect(client.namespace).To(Equal("envspace"))
+	})
+
+	It("should combine the environment with the user configuration file", func() {
+		writeFile(".shakenfist", `{"apiurl": "http://file"}`)
+		os.Setenv(EnvNamespace, "envspace")
+		os.Setenv(EnvKey, "envkey")
+
+		client, err := NewClientFromEnvironment()
+		Expect(err).To(BeNil())
+		Expect(client.server_url).To(Equal("http://file"))
+		Expect(client.namespace).To(Equal("envspace"))
+		Expect(client.apiKey).To(Equal("envkey"))
+	})
+
+	It("should default the API URL as the Python client does", func() {
+		os.Setenv(EnvNamespace, "envspace")
+
+		creds, err := DefaultCredentialProvider().Credentials()
+		Expect(err).To(BeNil())
+		Expect(creds.APIURL).To(Equal(DefaultAPIURL))
+	})
+
+	It("should require an API URL", func() {
+		_, err := NewClientFromProvider(StaticProvider{Namespace: "space"})
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("should use a static token without authenticating", func() {
+		const test_url = "http://server:13000"
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			func(req *http.Request) (*http.Response, error) {
+				Expect(req.Header.Get("Authorization")).To(Equal("Bearer XYZ"))
+				return httpmock.NewStringResponse(200, `[]`), nil
+			})
+
+		client, err := NewClientFromProvider(
+			StaticProvider{APIURL: test_url, Token: "XYZ"})
+		Expect(err).To(BeNil())
+
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["POST "+test_url+"/auth"]).To(Equal(0))
+	})
+})
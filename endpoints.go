@@ -0,0 +1,136 @@
+package client
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointStrategy selects which API server a request is sent to when the
+// client knows about more than one.
+type EndpointStrategy int
+
+const (
+	// RoundRobin spreads requests evenly over the healthy endpoints.
+	RoundRobin EndpointStrategy = iota
+
+	// HealthRanked sends requests to the healthy endpoint with the fewest
+	// consecutive failures, preferring the earliest listed.
+	HealthRanked
+)
+
+// DefaultEndpointCoolDown is how long a failing endpoint is avoided.
+const DefaultEndpointCoolDown = 30 * time.Second
+
+// endpoint is one API server and what we have learnt about its health.
+type endpoint struct {
+	url            string
+	failures       int
+	unhealthyUntil time.Time
+}
+
+// endpointPool chooses between the API servers of a cluster. It is safe
+// for concurrent use.
+type endpointPool struct {
+	lock      sync.Mutex
+	endpoints []*endpoint
+	next      int
+	strategy  EndpointStrategy
+	coolDown  time.Duration
+}
+
+func newEndpointPool(urls []string, strategy EndpointStrategy,
+	coolDown time.Duration) *endpointPool {
+
+	p := &endpointPool{strategy: strategy, coolDown: coolDown}
+	seen := map[string]bool{}
+	for _, u := range urls {
+		u = strings.TrimSuffix(u, "/")
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		p.endpoints = append(p.endpoints, &endpoint{url: u})
+	}
+
+	// Requests to a client without a server URL fail when they are sent,
+	// rather than when the client is created.
+	if len(p.endpoints) == 0 {
+		p.endpoints = append(p.endpoints, &endpoint{})
+	}
+	return p
+}
+
+// size returns the number of endpoints in the pool.
+func (p *endpointPool) size() int {
+	return len(p.endpoints)
+}
+
+// pick returns the endpoint the next request should use. If every endpoint
+// is cooling down, the one which recovers soonest is returned.
+func (p *endpointPool) pick() *endpoint {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	var best *endpoint
+
+	switch p.strategy {
+	case HealthRanked:
+		for _, ep := range p.endpoints {
+			if ep.unhealthyUntil.After(now) {
+				continue
+			}
+			if best == nil || ep.failures < best.failures {
+				best = ep
+			}
+		}
+
+	default:
+		for i := 0; i < len(p.endpoints); i++ {
+			ep := p.endpoints[(p.next+i)%len(p.endpoints)]
+			if !ep.unhealthyUntil.After(now) {
+				best = ep
+				p.next = (p.next + i + 1) % len(p.endpoints)
+				break
+			}
+		}
+	}
+
+	if best == nil {
+		for _, ep := range p.endpoints {
+			if best == nil || ep.unhealthyUntil.Before(best.unhealthyUntil) {
+				best = ep
+			}
+		}
+	}
+	return best
+}
+
+// markFailed records a failure and takes the endpoint out of rotation for
+// the cool-down period.
+func (p *endpointPool) markFailed(ep *endpoint) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	ep.failures++
+	ep.unhealthyUntil = time.Now().Add(p.coolDown)
+}
+
+// markHealthy records that the endpoint answered a request.
+func (p *endpointPool) markHealthy(ep *endpoint) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	ep.failures = 0
+	ep.unhealthyUntil = time.Time{}
+}
+
+// isDialError reports whether err happened while connecting, in which case
+// the request never reached the server and can be sent elsewhere.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
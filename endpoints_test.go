@@ -0,0 +1,188 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// apiServer is a minimal local Shaken Fist API server.
+type apiServer struct {
+	*httptest.Server
+	auths       int32
+	gets        int32
+	unavailable int32
+}
+
+func newAPIServer() *apiServer {
+	s := &apiServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(&s.unavailable) != 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			switch r.URL.Path {
+			case "/auth":
+				atomic.AddInt32(&s.auths, 1)
+				w.Write([]byte(`{"access_token":"shared-token"}`))
+			case "/nodes":
+				atomic.AddInt32(&s.gets, 1)
+				if r.Header.Get("Authorization") != "Bearer shared-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Write([]byte(`[]`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	return s
+}
+
+var _ = Describe("Multiple API endpoints", func() {
+	var (
+		servers []*apiServer
+	)
+
+	newClient := func(opts ...Option) *Client {
+		opts = append([]Option{
+			WithEndpoints(servers[1].URL, servers[2].URL),
+		}, opts...)
+
+		client, err := newTestClient(servers[0].URL, opts...)
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	getCounts := func() []int32 {
+		counts := []int32{}
+		for _, s := range servers {
+			counts = append(counts, atomic.LoadInt32(&s.gets))
+		}
+		return counts
+	}
+
+	BeforeEach(func() {
+		servers = []*apiServer{newAPIServer(), newAPIServer(), newAPIServer()}
+	})
+
+	AfterEach(func() {
+		for _, s := range servers {
+			s.Close()
+		}
+	})
+
+	It("should spread requests round robin", func() {
+		client := newClient()
+
+		for i := 0; i < 6; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+
+		Expect(getCounts()).To(Equal([]int32{2, 2, 2}))
+	})
+
+	It("should share one auth token between endpoints", func() {
+		client := newClient()
+
+		for i := 0; i < 6; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+
+		auths := int32(0)
+		for _, s := range servers {
+			auths += atomic.LoadInt32(&s.auths)
+		}
+		Expect(auths).To(Equal(int32(1)))
+	})
+
+	It("should fail over when a server is stopped", func() {
+		client := newClient()
+
+		// The auth request goes to the first server, the GET to the second
+		_, err := client.GetNodes()
+		Expect(err).To(BeNil())
+		Expect(getCounts()).To(Equal([]int32{0, 1, 0}))
+
+		servers[1].Close()
+
+		for i := 0; i < 6; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+
+		counts := getCounts()
+		Expect(counts[1]).To(Equal(int32(1)))
+		Expect(counts[0] + counts[2]).To(Equal(int32(6)))
+
+		// The stopped server is left alone during its cool-down
+		Expect(client.endpoints.endpoints[1].failures).To(Equal(1))
+	})
+
+	It("should fail over to the next server with health ranking", func() {
+		client := newClient(WithEndpointStrategy(HealthRanked))
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+		Expect(getCounts()).To(Equal([]int32{3, 0, 0}))
+
+		servers[0].Close()
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+		counts := getCounts()
+		Expect(counts[1] + counts[2]).To(Equal(int32(3)))
+	})
+
+	It("should return to an unavailable server after the cool-down", func() {
+		client := newClient(WithEndpointCoolDown(100*time.Millisecond),
+			WithRetryPolicy(&RetryPolicy{
+				MaxAttempts:          2,
+				RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+			}))
+
+		_, err := client.GetNodes()
+		Expect(err).To(BeNil())
+
+		// The first server reports it is unavailable. Each request
+		// should still succeed, and the first server is skipped until
+		// its cool-down ends.
+		atomic.StoreInt32(&servers[0].unavailable, 1)
+		for i := 0; i < 4; i++ {
+			_, err = client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+		Expect(atomic.LoadInt32(&servers[0].gets)).To(Equal(int32(0)))
+		Expect(client.endpoints.endpoints[0].failures).To(Equal(1))
+
+		atomic.StoreInt32(&servers[0].unavailable, 0)
+		time.Sleep(150 * time.Millisecond)
+		for i := 0; i < 3; i++ {
+			_, err = client.GetNodes()
+			Expect(err).To(BeNil())
+		}
+		Expect(atomic.LoadInt32(&servers[0].gets)).To(Equal(int32(1)))
+		Expect(client.endpoints.endpoints[0].failures).To(Equal(0))
+	})
+
+	It("should report an error when every server is stopped", func() {
+		client := newClient()
+		for _, s := range servers {
+			s.Close()
+		}
+
+		_, err := client.GetNodes()
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIError is returned when the Shaken Fist API server responds with a non
+// 200 status code. It is wrapped by the errors returned from the Client
+// methods, so use errors.As or the Is* helpers below to inspect it.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+
+	// Message is the "error" field of the response body, if present.
+	Message string
+
+	// Body is the decoded JSON response body. It is nil if the server did
+	// not send a JSON object.
+	Body map[string]interface{}
+
+	// RawBody is the undecoded response body.
+	RawBody string
+
+	// RequestID is the server assigned identifier for the request, if any.
+	RequestID string
+
+	// ClientRequestID is the X-Request-ID the client sent.
+	ClientRequestID string
+
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	detail := e.Message
+	if detail == "" {
+		detail = e.RawBody
+	}
+	return fmt.Sprintf("%s /%s: received non 200 status code: %d - %s",
+		e.Method, e.Path, e.StatusCode, detail)
+}
+
+// newAPIError builds an APIError from a failed response and its body.
+func newAPIError(method, path string, resp *http.Response,
+	body []byte) *APIError {
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     method,
+		Path:       path,
+		RawBody:    string(body),
+		RequestID:  serverRequestID(resp),
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	decoded := map[string]interface{}{}
+	if json.Unmarshal(body, &decoded) == nil {
+		apiErr.Body = decoded
+		if msg, ok := decoded["error"].(string); ok {
+			apiErr.Message = msg
+		}
+		if id, ok := decoded["request_id"].(string); ok && apiErr.RequestID == "" {
+			apiErr.RequestID = id
+		}
+	}
+
+	return apiErr
+}
+
+// statusCode returns the HTTP status code of an APIError wrapped in err, or
+// zero if there is none.
+func statusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err was caused by a 404 response.
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether err was caused by a 409 response.
+func IsConflict(err error) bool {
+	return statusCode(err) == http.StatusConflict
+}
+
+// IsUnauthorized reports whether err was caused by a 401 response.
+func IsUnauthorized(err error) bool {
+	return statusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether err was caused by a 403 response.
+func IsForbidden(err error) bool {
+	return statusCode(err) == http.StatusForbidden
+}
+
+// IsServerError reports whether err was caused by a 5xx response.
+func IsServerError(err error) bool {
+	code := statusCode(err)
+	return code >= 500 && code <= 599
+}
+
+// UnsupportedError is returned, without contacting the server, by methods
+// which need a capability the server does not have.
+type UnsupportedError struct {
+	Capability Capability
+	Version    string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s unsupported by server version %s",
+		e.Capability, e.Version)
+}
+
+// IsUnsupported reports whether err was caused by the server lacking a
+// capability.
+func IsUnsupported(err error) bool {
+	var unsupported *UnsupportedError
+	return errors.As(err, &unsupported)
+}
+
+// ValidationError lists the problems found in a request before it was sent
+// to the server.
+type ValidationError struct {
+	// Subject is what was invalid, such as "instance spec"
+	Subject  string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Subject,
+		strings.Join(e.Problems, "; "))
+}
+
+// IsValidation reports whether err was caused by a request failing
+// validation.
+func IsValidation(err error) bool {
+	var invalid *ValidationError
+	return errors.As(err, &invalid)
+}
+
+// WaitTimeoutError is returned by the wait helpers when their context is
+// done before the resource reaches a target state.
+type WaitTimeoutError struct {
+	Resource ResourceType
+	UUID     string
+	Targets  []State
+
+	// LastState is the state last seen, or empty if none was seen
+	LastState State
+
+	// Err is the context's error
+	Err error
+}
+
+func (e *WaitTimeoutError) Error() string {
+	targets := make([]string, len(e.Targets))
+	for i, t := range e.Targets {
+		targets[i] = string(t)
+	}
+	last := e.LastState
+	if last == "" {
+		last = "unknown"
+	}
+	return fmt.Sprintf("%s %s did not reach %s, last state %s: %v",
+		e.Resource.noun(), e.UUID, strings.Join(targets, " or "), last, e.Err)
+}
+
+func (e *WaitTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// IsWaitTimeout reports whether err was caused by a wait helper giving up.
+func IsWaitTimeout(err error) bool {
+	var timeout *WaitTimeoutError
+	return errors.As(err, &timeout)
+}
+
+// FailureStateError is returned by the wait helpers when a resource reaches
+// a state it will not leave for a target state.
+type FailureStateError struct {
+	Resource ResourceType
+	UUID     string
+	State    State
+}
+
+func (e *FailureStateError) Error() string {
+	return fmt.Sprintf("%s %s is in state %s", e.Resource.noun(), e.UUID,
+		e.State)
+}
+
+// IsFailureState reports whether err was caused by a resource reaching a
+// failure state.
+func IsFailureState(err error) bool {
+	var state *FailureStateError
+	return errors.As(err, &state)
+}
+
+// TransitionError is returned, without contacting the server, by actions on
+// an instance known to be in a state which does not allow them.
+type TransitionError struct {
+	UUID   string
+	State  State
+	Action Action
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot %s instance %s in state %s", e.Action, e.UUID,
+		e.State)
+}
+
+// IsTransition reports whether err was caused by an action not allowed in
+// an instance's state.
+func IsTransition(err error) bool {
+	var transition *TransitionError
+	return errors.As(err, &transition)
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("API errors", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	var (
+		client *Client
+	)
+
+	BeforeEach(func() {
+		// Configure client
+		client = NewClient(test_url, test_namespace, test_key)
+
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewBytesResponder(200, []byte(`{"access_token":"ABC123"}`)))
+	})
+
+	It("should return a typed error for a missing instance", func() {
+		resp := httpmock.NewStringResponse(404,
+			`{"error": "instance not found", "status": 404}`)
+		resp.Header.Set("X-Request-ID", "req-123")
+		httpmock.RegisterResponder("GET", test_url+"/instances/123-456",
+			httpmock.ResponderFromResponse(resp))
+
+		_, err := client.GetInstance("123-456")
+		Expect(err).ToNot(BeNil())
+		Expect(IsNotFound(err)).To(BeTrue())
+		Expect(IsConflict(err)).To(BeFalse())
+
+		var apiErr *APIError
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+		Expect(apiErr.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(apiErr.Method).To(Equal("GET"))
+		Expect(apiErr.Path).To(Equal("instances/123-456"))
+		Expect(apiErr.Message).To(Equal("instance not found"))
+		Expect(apiErr.Body["status"]).To(Equal(float64(404)))
+		Expect(apiErr.RequestID).To(Equal("req-123"))
+		Expect(err.Error()).To(ContainSubstring("404 - instance not found"))
+	})
+
+	It("should detect a conflict through the namespace wrappers", func() {
+		httpmock.RegisterResponder("POST", test_url+"/auth/namespaces",
+			httpmock.NewStringResponder(409,
+				`{"error": "namespace exists", "status": 409}`))
+
+		err := client.CreateNamespace("bobspace")
+		Expect(IsConflict(err)).To(BeTrue())
+	})
+
+	It("should detect a missing key through the metadata wrappers", func() {
+		httpmock.RegisterResponder("DELETE",
+			test_url+"/networks/123-456/metadata/color",
+			httpmock.NewStringResponder(404, `{"error": "key not found"}`))
+
+		err := client.DeleteNetworkMetadata("123-456", "color")
+		Expect(IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should detect a rejected API key", func() {
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewStringResponder(401, `{"error": "unauthorized"}`))
+
+		_, err := client.GetNetworks()
+		Expect(IsUnauthorized(err)).To(BeTrue())
+	})
+
+	It("should keep the raw body when it is not JSON", func() {
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			httpmock.NewStringResponder(502, `Bad Gateway`))
+
+		_, err := client.GetNodes()
+		Expect(IsServerError(err)).To(BeTrue())
+
+		var apiErr *APIError
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+		Expect(apiErr.Body).To(BeNil())
+		Expect(apiErr.RawBody).To(Equal("Bad Gateway"))
+	})
+
+	It("should not report plain errors as API errors", func() {
+		err := errors.New("something else")
+		Expect(IsNotFound(err)).To(BeFalse())
+		Expect(IsServerError(nil)).To(BeFalse())
+	})
+})
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 
 	client "github.com/shakenfist/client-go"
 )
@@ -40,11 +39,11 @@ func printEvent(event client.Event) {
 }
 
 func main() {
-	c := client.NewClient(
-		os.Getenv("SHAKENFIST_API_URL"),
-		os.Getenv("SHAKENFIST_NAMESPACE"),
-		os.Getenv("SHAKENFIST_KEY"),
-	)
+	c, err := client.NewClientFromEnvironment()
+	if err != nil {
+		fmt.Println("Unable to configure client: ", err)
+		return
+	}
 
 	fmt.Println("*********************")
 	fmt.Println("*** Get Artifacts ***")
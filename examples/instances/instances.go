@@ -1,8 +1,8 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"time"
 
 	client "github.com/shakenfist/client-go"
@@ -54,11 +54,11 @@ func printInterface(iface client.NetworkInterface) {
 }
 
 func main() {
-	c := client.NewClient(
-		os.Getenv("SHAKENFIST_API_URL"),
-		os.Getenv("SHAKENFIST_NAMESPACE"),
-		os.Getenv("SHAKENFIST_KEY"),
-	)
+	c, err := client.NewClientFromEnvironment()
+	if err != nil {
+		fmt.Println("Unable to configure client: ", err)
+		return
+	}
 
 	fmt.Println("*******************************")
 	fmt.Println("*** Get a list of instances ***")
@@ -86,27 +86,29 @@ func main() {
 	fmt.Println("************************")
 	fmt.Println("*** Wait for network ***")
 	fmt.Println("************************")
-	for {
-		net, err := c.GetNetwork(networkUUID)
-		if err != nil {
-			fmt.Println("GetNetwork request error: ", err)
-			return
-		}
-		if net.State != "initial" {
-			break
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	_, err = c.WaitForNetworkState(ctx, networkUUID,
+		[]client.State{client.StateCreated},
+		[]client.State{client.StateError, client.StateDeleted})
+	if err != nil {
+		fmt.Println("WaitForNetworkState error: ", err)
+		return
 	}
 
 	fmt.Println("**************************")
 	fmt.Println("*** Create an instance ***")
 	fmt.Println("**************************")
-	instance, err := c.CreateInstance("golang", 1, 1,
-		[]client.NetworkSpec{{NetworkUUID: networkUUID}},
-		[]client.DiskSpec{{Base: "cirros", Size: 8, Type: "disk", Bus: ""}},
-		client.VideoSpec{Model: "cirrus", Memory: 16384},
-		"", "", "", "", false, false, "")
+	instance, err := c.CreateInstanceFromSpec(client.InstanceSpec{
+		Name:     "golang",
+		CPUs:     1,
+		Memory:   1024,
+		Networks: []client.NetworkSpec{{NetworkUUID: networkUUID}},
+		Disks:    []client.DiskSpec{{Base: "cirros", Size: 8, Type: "disk"}},
+		Video:    client.VideoSpec{Model: "cirrus", Memory: 16384},
+	})
 	if err != nil {
-		fmt.Println("CreateInstance request error: ", err)
+		fmt.Println("CreateInstanceFromSpec request error: ", err)
 		return
 	}
 	printInstance(instance)
@@ -199,15 +201,10 @@ func main() {
 	fmt.Println("**********************************")
 	fmt.Println("*** Wait for instance deletion ***")
 	fmt.Println("**********************************")
-	for {
-		net, err := c.GetInstance(instance.UUID)
-		if err != nil {
-			fmt.Println("GetInstance request error: ", err)
-			return
-		}
-		if net.State == "deleted" || net.State == "error" {
-			break
-		}
+	err = c.WaitForDeleted(ctx, client.TypeInstance, instance.UUID)
+	if err != nil && !client.IsFailureState(err) {
+		fmt.Println("WaitForDeleted error: ", err)
+		return
 	}
 
 	fmt.Println("**************************")
@@ -13,11 +13,11 @@ func main() {
 		return
 	}
 
-	c := client.NewClient(
-		os.Getenv("SHAKENFIST_API_URL"),
-		os.Getenv("SHAKENFIST_NAMESPACE"),
-		os.Getenv("SHAKENFIST_KEY"),
-	)
+	c, err := client.NewClientFromEnvironment()
+	if err != nil {
+		fmt.Println("Unable to configure client: ", err)
+		return
+	}
 
 	fmt.Println("******************************")
 	fmt.Println("*** Get list of namespaces ***")
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	client "github.com/shakenfist/client-go"
@@ -22,11 +21,11 @@ func printNetwork(network client.Network) {
 }
 
 func main() {
-	c := client.NewClient(
-		os.Getenv("SHAKENFIST_API_URL"),
-		os.Getenv("SHAKENFIST_NAMESPACE"),
-		os.Getenv("SHAKENFIST_KEY"),
-	)
+	c, err := client.NewClientFromEnvironment()
+	if err != nil {
+		fmt.Println("Unable to configure client: ", err)
+		return
+	}
 
 	fmt.Println("******************************")
 	fmt.Println("*** Get a list of networks ***")
@@ -1,6 +1,6 @@
 module github.com/shakenfist/client-go
 
-go 1.14
+go 1.15
 
 require (
 	github.com/jarcoal/httpmock v1.0.5
@@ -8,4 +8,5 @@ require (
 	github.com/onsi/ginkgo v1.14.0
 	github.com/onsi/gomega v1.10.1
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
+	gopkg.in/yaml.v2 v2.3.0
 )
@@ -1,8 +1,7 @@
 package client
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 )
 
 // ImageRequest defines a link to an image.
@@ -12,15 +11,15 @@ type imageRequest struct {
 
 // CacheImage will cache an image.
 func (c *Client) CacheImage(imageURL string) error {
+	return c.CacheImageCtx(context.Background(), imageURL)
+}
+
+// CacheImageCtx is CacheImage with a caller supplied context.
+func (c *Client) CacheImageCtx(ctx context.Context, imageURL string) error {
 	request := &imageRequest{
 		URL: imageURL,
 	}
-	post, err := json.Marshal(request)
-	if err != nil {
-		return err
-	}
-
-	err = c.doRequestJSON("images", "POST", *bytes.NewBuffer(post), nil)
+	err := c.doRequestJSON(ctx, "images", "POST", request, nil)
 
 	return err
 }
@@ -39,8 +38,13 @@ type ImageMeta struct {
 
 // GetImageMeta retrieves a list of Image metadata
 func (c *Client) GetImageMeta() ([]ImageMeta, error) {
+	return c.GetImageMetaCtx(context.Background())
+}
+
+// GetImageMetaCtx is GetImageMeta with a caller supplied context.
+func (c *Client) GetImageMetaCtx(ctx context.Context) ([]ImageMeta, error) {
 	images := []ImageMeta{}
-	err := c.doRequestJSON("images", "GET", bytes.Buffer{}, &images)
+	err := c.doRequestJSON(ctx, "images", "GET", nil, &images)
 
 	return images, err
 }
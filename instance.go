@@ -2,22 +2,23 @@ package client
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
+	"strconv"
 )
 
 // DiskSpec is a definition of an instance disk.
 type DiskSpec struct {
-	Base string `json:"base"`
-	Size int    `json:"size"`
-	Bus  string `json:"bus"`
-	Type string `json:"type"`
+	Base string `json:"base" yaml:"base,omitempty"`
+	Size int    `json:"size" yaml:"size,omitempty"`
+	Bus  string `json:"bus" yaml:"bus,omitempty"`
+	Type string `json:"type" yaml:"type,omitempty"`
 }
 
 // VideoSpec defines the type of video card in an instance.
 type VideoSpec struct {
-	Model  string `json:"model"`
-	Memory int    `json:"memory"` // Memory size in KB
+	Model  string `json:"model" yaml:"model,omitempty"`
+	Memory int    `json:"memory" yaml:"memory,omitempty"` // Memory size in KB
 }
 
 // Instance is a definition of an instance.
@@ -32,9 +33,9 @@ type Instance struct {
 	Namespace         string                 `json:"namespace"`
 	NetworkInterfaces []NetworkInterface     `json:"network_interfaces"`
 	Node              string                 `json:"node"`
-	PowerState        string                 `json:"power_state"`
+	PowerState        PowerState             `json:"power_state"`
 	SSHKey            string                 `json:"ssh_key"`
-	State             string                 `json:"state"`
+	State             State                  `json:"state"`
 	StateUpdated      float64                `json:"state_updated"`
 	SecureBoot        bool                   `json:"secure_boot"`
 	UEFI              bool                   `json:"uefi"`
@@ -46,16 +47,30 @@ type Instance struct {
 
 // GetInstances fetches a list of instances.
 func (c *Client) GetInstances() ([]Instance, error) {
+	return c.GetInstancesCtx(context.Background())
+}
+
+// GetInstancesCtx is GetInstances with a caller supplied context.
+func (c *Client) GetInstancesCtx(ctx context.Context) ([]Instance, error) {
 	instances := []Instance{}
-	err := c.doRequestJSON("instances", "GET", bytes.Buffer{}, &instances)
+	err := c.doRequestJSON(ctx, "instances", "GET", nil, &instances)
+	for _, instance := range instances {
+		c.instanceStates.observe(instance.UUID, instance.State)
+	}
 
 	return instances, err
 }
 
 // GetInstance fetches a specific instance by UUID.
 func (c *Client) GetInstance(uuid string) (Instance, error) {
+	return c.GetInstanceCtx(context.Background(), uuid)
+}
+
+// GetInstanceCtx is GetInstance with a caller supplied context.
+func (c *Client) GetInstanceCtx(ctx context.Context, uuid string) (Instance, error) {
 	instance := Instance{}
-	err := c.doRequestJSON("instances/"+uuid, "GET", bytes.Buffer{}, &instance)
+	err := c.doRequestJSON(ctx, "instances/"+uuid, "GET", nil, &instance)
+	c.instanceStates.observe(instance.UUID, instance.State)
 
 	return instance, err
 }
@@ -76,41 +91,53 @@ type createInstanceRequest struct {
 	UserData      string        `json:"user_data"`
 }
 
-// CreateInstance creates a new instance.
+// CreateInstance creates a new instance. CreateInstanceFromSpec is easier
+// to call correctly, and checks the arguments before sending them.
 func (c *Client) CreateInstance(name string, cpus int, memory int,
 	networks []NetworkSpec, disks []DiskSpec, video VideoSpec, sshKey string,
 	userData string, nameSpace string, metadata string, secureBoot bool,
 	uefi bool, nvramTemplate string) (Instance, error) {
 
-	request := &createInstanceRequest{
+	return c.CreateInstanceCtx(context.Background(), name, cpus, memory,
+		networks, disks, video, sshKey, userData, nameSpace, metadata,
+		secureBoot, uefi, nvramTemplate)
+}
+
+// CreateInstanceCtx is CreateInstance with a caller supplied context.
+func (c *Client) CreateInstanceCtx(ctx context.Context, name string, cpus int,
+	memory int, networks []NetworkSpec, disks []DiskSpec, video VideoSpec,
+	sshKey string, userData string, nameSpace string, metadata string,
+	secureBoot bool, uefi bool, nvramTemplate string) (Instance, error) {
+
+	return c.createInstance(ctx, InstanceSpec{
 		Name:          name,
 		CPUs:          cpus,
 		Memory:        memory,
-		Metadata:      metadata,
-		NameSpace:     nameSpace,
-		Network:       networks,
-		NVRAMTemplate: nvramTemplate,
-		Disk:          disks,
+		Networks:      networks,
+		Disks:         disks,
 		Video:         video,
-		SecureBoot:    secureBoot,
 		SSHKey:        sshKey,
-		UEFI:          uefi,
 		UserData:      userData,
-	}
-	post, err := json.Marshal(request)
-	if err != nil {
-		return Instance{}, err
-	}
-
-	instance := Instance{}
-	err = c.doRequestJSON("instances", "POST", *bytes.NewBuffer(post), &instance)
-
-	return instance, err
+		Namespace:     nameSpace,
+		Metadata:      metadata,
+		SecureBoot:    secureBoot,
+		UEFI:          uefi,
+		NVRAMTemplate: nvramTemplate,
+	})
 }
 
 // SnapshotInstance takes a snapshot of an instance.
 func (c *Client) SnapshotInstance(uuid string, all bool, device string) error {
+	return c.SnapshotInstanceCtx(context.Background(), uuid, all, device)
+}
+
+// SnapshotInstanceCtx is SnapshotInstance with a caller supplied context.
+func (c *Client) SnapshotInstanceCtx(ctx context.Context,
+	uuid string, all bool, device string) error {
 
+	if err := c.instanceStates.check(uuid, ActionSnapshot); err != nil {
+		return err
+	}
 	path := "instances/" + uuid + "/snapshot"
 
 	request := &struct {
@@ -120,12 +147,7 @@ func (c *Client) SnapshotInstance(uuid string, all bool, device string) error {
 		All:    all,
 		Device: device,
 	}
-	post, err := json.Marshal(request)
-	if err != nil {
-		return err
-	}
-
-	err = c.doRequestJSON(path, "POST", *bytes.NewBuffer(post), nil)
+	err := c.doRequestJSON(ctx, path, "POST", request, nil)
 
 	return err
 }
@@ -139,55 +161,97 @@ type Snapshot struct {
 
 // GetInstanceSnapshots fetches a list of instance snapshots.
 func (c *Client) GetInstanceSnapshots(uuid string) ([]Snapshot, error) {
+	return c.GetInstanceSnapshotsCtx(context.Background(), uuid)
+}
+
+// GetInstanceSnapshotsCtx is GetInstanceSnapshots with a caller supplied
+// context.
+func (c *Client) GetInstanceSnapshotsCtx(ctx context.Context,
+	uuid string) ([]Snapshot, error) {
+
 	snapshots := []Snapshot{}
 	path := "instances/" + uuid + "/snapshot"
-	err := c.doRequestJSON(path, "GET", bytes.Buffer{}, &snapshots)
+	err := c.doRequestJSON(ctx, path, "GET", nil, &snapshots)
 
 	return snapshots, err
 }
 
 // RebootInstance reboots an instance.
 func (c *Client) RebootInstance(uuid string) error {
-	return c.postRequest("instances", uuid, "reboot")
+	return c.RebootInstanceCtx(context.Background(), uuid)
+}
+
+// RebootInstanceCtx is RebootInstance with a caller supplied context.
+func (c *Client) RebootInstanceCtx(ctx context.Context, uuid string) error {
+	return c.instanceAction(ctx, uuid, ActionReboot, "reboot")
 }
 
 // PowerOffInstance powers on an instance.
 func (c *Client) PowerOffInstance(uuid string) error {
-	return c.postRequest("instances", uuid, "poweroff")
+	return c.PowerOffInstanceCtx(context.Background(), uuid)
+}
+
+// PowerOffInstanceCtx is PowerOffInstance with a caller supplied context.
+func (c *Client) PowerOffInstanceCtx(ctx context.Context, uuid string) error {
+	return c.instanceAction(ctx, uuid, ActionPowerOff, "poweroff")
 }
 
 // PowerOnInstance powers on an instance.
 func (c *Client) PowerOnInstance(uuid string) error {
-	return c.postRequest("instances", uuid, "poweron")
+	return c.PowerOnInstanceCtx(context.Background(), uuid)
+}
+
+// PowerOnInstanceCtx is PowerOnInstance with a caller supplied context.
+func (c *Client) PowerOnInstanceCtx(ctx context.Context, uuid string) error {
+	return c.instanceAction(ctx, uuid, ActionPowerOn, "poweron")
 }
 
 // PauseInstance will pause an instance.
 func (c *Client) PauseInstance(uuid string) error {
-	return c.postRequest("instances", uuid, "pause")
+	return c.PauseInstanceCtx(context.Background(), uuid)
+}
+
+// PauseInstanceCtx is PauseInstance with a caller supplied context.
+func (c *Client) PauseInstanceCtx(ctx context.Context, uuid string) error {
+	return c.instanceAction(ctx, uuid, ActionPause, "pause")
 }
 
 // UnPauseInstance will unpause an instance.
 func (c *Client) UnPauseInstance(uuid string) error {
-	return c.postRequest("instances", uuid, "unpause")
+	return c.UnPauseInstanceCtx(context.Background(), uuid)
+}
+
+// UnPauseInstanceCtx is UnPauseInstance with a caller supplied context.
+func (c *Client) UnPauseInstanceCtx(ctx context.Context, uuid string) error {
+	return c.instanceAction(ctx, uuid, ActionUnpause, "unpause")
 }
 
 // DeleteInstance deletes an instance.
 func (c *Client) DeleteInstance(uuid string, namespace string) error {
-	var err error
-	var req []byte
+	return c.DeleteInstanceCtx(context.Background(), uuid, namespace)
+}
+
+// DeleteInstanceCtx is DeleteInstance with a caller supplied context.
+func (c *Client) DeleteInstanceCtx(ctx context.Context,
+	uuid string, namespace string) error {
+
+	if err := c.instanceStates.check(uuid, ActionDelete); err != nil {
+		return err
+	}
 
+	// Without a namespace the request has no body
+	var req interface{}
 	if namespace != "" {
-		n := &struct {
+		req = &struct {
 			Namespace string `json:"namespace"`
 		}{
 			Namespace: namespace,
 		}
-		req, err = json.Marshal(n)
-		if err != nil {
-			return fmt.Errorf("Unable to marshal data: %v", err)
-		}
 	}
-	err = c.doRequestJSON("instances/"+uuid, "DELETE", *bytes.NewBuffer(req), nil)
+	err := c.doRequestJSON(ctx, "instances/"+uuid, "DELETE", req, nil)
+	if err == nil {
+		c.instanceStates.observe(uuid, StateDeleted)
+	}
 	return err
 }
 
@@ -199,20 +263,25 @@ type deleteAllRequest struct {
 // DeleteAllInstances deletes all instances within a namespace. Specifying
 // namespace "system" will delete all instances in a cluster.
 func (c *Client) DeleteAllInstances(namespace string) ([]string, error) {
+	return c.DeleteAllInstancesCtx(context.Background(), namespace)
+}
+
+// DeleteAllInstancesCtx is DeleteAllInstances with a caller supplied context.
+func (c *Client) DeleteAllInstancesCtx(ctx context.Context,
+	namespace string) ([]string, error) {
+
 	instances := []string{}
 
 	n := deleteAllRequest{
 		Namespace: namespace,
 		Confirm:   true,
 	}
-	req, err := json.Marshal(n)
-	if err != nil {
-		return instances, fmt.Errorf("Unable to marshal data: %v", err)
+	err := c.doRequestJSON(ctx, "instances",
+		"DELETE", n, &instances)
+	for _, uuid := range instances {
+		c.instanceStates.observe(uuid, StateDeleted)
 	}
 
-	err = c.doRequestJSON("instances",
-		"DELETE", *bytes.NewBuffer(req), &instances)
-
 	return instances, err
 }
 
@@ -228,8 +297,15 @@ type Event struct {
 
 // GetInstanceEvents fetches events that have occurred on a specific instance.
 func (c *Client) GetInstanceEvents(uuid string) ([]Event, error) {
+	return c.GetInstanceEventsCtx(context.Background(), uuid)
+}
+
+// GetInstanceEventsCtx is GetInstanceEvents with a caller supplied context.
+func (c *Client) GetInstanceEventsCtx(ctx context.Context,
+	uuid string) ([]Event, error) {
+
 	events := []Event{}
-	err := c.getRequest("instances", uuid, "events", &events)
+	err := c.getRequest(ctx, "instances", uuid, "events", &events)
 	return events, err
 }
 
@@ -239,25 +315,31 @@ type consoleDataReq struct {
 
 // GetConsoleData retrieves the last n bytes of console data from an instance.
 func (c *Client) GetConsoleData(uuid string, n int) (string, error) {
+	return c.GetConsoleDataCtx(context.Background(), uuid, n)
+}
+
+// GetConsoleDataCtx is GetConsoleData with a caller supplied context.
+func (c *Client) GetConsoleDataCtx(ctx context.Context,
+	uuid string, n int) (string, error) {
+
 	path := "instances/" + uuid + "/consoledata"
 
-	req := &consoleDataReq{
-		Length: n,
-	}
-	reqData, err := json.Marshal(req)
-	if err != nil {
-		return "", fmt.Errorf("cannot marshal consoledata request: %v", err)
-	}
+	req := newAPIRequest("GET", path).
+		param("length", strconv.Itoa(n)).
+		withFallback(&consoleDataReq{
+			Length: n,
+		})
 
-	resp, err := c.doRequest(path, "GET", *bytes.NewBuffer(reqData))
+	resp, err := c.send(ctx, req)
 	if err != nil {
-		return "", fmt.Errorf("cannot retrieve console data: %v", err)
+		return "", fmt.Errorf("cannot retrieve console data: %w", err)
 	}
+	defer resp.Close()
 
 	buf := new(bytes.Buffer)
 	_, err = buf.ReadFrom(resp)
 	if err != nil {
-		return "", fmt.Errorf("cannot read http response buffer: %v", err)
+		return "", fmt.Errorf("cannot read http response buffer: %w", err)
 	}
 	d := buf.String()
 
@@ -266,6 +348,14 @@ func (c *Client) GetConsoleData(uuid string, n int) (string, error) {
 
 // SetInstanceMetadataItem sets a metadata key on an instance to value.
 func (c *Client) SetInstanceMetadataItem(uuid string, key string, value string) error {
+	return c.SetInstanceMetadataItemCtx(context.Background(), uuid, key, value)
+}
+
+// SetInstanceMetadataItemCtx is SetInstanceMetadataItem with a caller
+// supplied context.
+func (c *Client) SetInstanceMetadataItemCtx(ctx context.Context,
+	uuid string, key string, value string) error {
+
 	path := "instances/" + uuid + "/metadata/" + key
 
 	request := &struct {
@@ -274,22 +364,36 @@ func (c *Client) SetInstanceMetadataItem(uuid string, key string, value string)
 		Value: value,
 	}
 
-	put, err := json.Marshal(request)
-	if err != nil {
-		return err
-	}
-
-	return c.doRequestJSON(path, "PUT", *bytes.NewBuffer(put), nil)
+	return c.doRequestJSON(ctx, path, "PUT", request, nil)
 }
 
 // DeleteInstanceMetadataItem deletes an individual metadata key on an instance.
 func (c *Client) DeleteInstanceMetadataItem(uuid string, key string) error {
+	return c.DeleteInstanceMetadataItemCtx(context.Background(), uuid, key)
+}
+
+// DeleteInstanceMetadataItemCtx is DeleteInstanceMetadataItem with a caller
+// supplied context.
+func (c *Client) DeleteInstanceMetadataItemCtx(ctx context.Context,
+	uuid string, key string) error {
+
 	path := "instances/" + uuid + "/metadata/" + key
-	return c.doRequestJSON(path, "DELETE", bytes.Buffer{}, nil)
+	return c.doRequestJSON(ctx, path, "DELETE", nil, nil)
 }
 
 // UpdateLabel changes the name of a blob label
 func (c *Client) UpdateLabel(labelName string, blobUUID string) error {
+	return c.UpdateLabelCtx(context.Background(), labelName, blobUUID)
+}
+
+// UpdateLabelCtx is UpdateLabel with a caller supplied context.
+func (c *Client) UpdateLabelCtx(ctx context.Context,
+	labelName string, blobUUID string) error {
+
+	if err := c.require(ctx, CapabilityLabels); err != nil {
+		return err
+	}
+
 	path := "label/" + labelName
 
 	request := &struct {
@@ -298,10 +402,5 @@ func (c *Client) UpdateLabel(labelName string, blobUUID string) error {
 		BlobUUID: blobUUID,
 	}
 
-	put, err := json.Marshal(request)
-	if err != nil {
-		return err
-	}
-
-	return c.doRequestJSON(path, "PUT", *bytes.NewBuffer(put), nil)
+	return c.doRequestJSON(ctx, path, "PUT", request, nil)
 }
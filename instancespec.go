@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// InstanceSpec describes an instance to create. It can be kept in a JSON or
+// YAML file.
+type InstanceSpec struct {
+	Name string `json:"name" yaml:"name"`
+	CPUs int    `json:"cpus" yaml:"cpus"`
+
+	// Memory is in MB
+	Memory int `json:"memory" yaml:"memory"`
+
+	Networks []NetworkSpec `json:"networks,omitempty" yaml:"networks,omitempty"`
+	Disks    []DiskSpec    `json:"disks" yaml:"disks"`
+
+	// Video defaults to DefaultVideo if it is not set
+	Video VideoSpec `json:"video" yaml:"video,omitempty"`
+
+	SSHKey        string `json:"ssh_key,omitempty" yaml:"ssh-key,omitempty"`
+	UserData      string `json:"user_data,omitempty" yaml:"user-data,omitempty"`
+	Namespace     string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Metadata      string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	SecureBoot    bool   `json:"secure_boot,omitempty" yaml:"secure-boot,omitempty"`
+	UEFI          bool   `json:"uefi,omitempty" yaml:"uefi,omitempty"`
+	NVRAMTemplate string `json:"nvram_template,omitempty" yaml:"nvram-template,omitempty"`
+}
+
+// DefaultVideo is the video card of an InstanceSpec which does not set one.
+var DefaultVideo = VideoSpec{Model: "cirrus", Memory: 16384}
+
+// DiskBuses are the buses a disk can be attached to. An empty bus lets the
+// server choose.
+var DiskBuses = []string{"", "ide", "nvme", "sata", "scsi", "usb", "virtio"}
+
+// DiskTypes are the kinds of disk. An empty type is a disk.
+var DiskTypes = []string{"", "cdrom", "disk"}
+
+// VideoModels are the video cards an instance can have.
+var VideoModels = []string{"cirrus", "none", "qxl", "vga", "virtio"}
+
+// Validate checks the spec, returning a ValidationError listing every
+// problem found.
+func (s InstanceSpec) Validate() error {
+	var problems []string
+	if s.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if s.CPUs <= 0 {
+		problems = append(problems, "cpus must be greater than zero")
+	}
+	if s.Memory <= 0 {
+		problems = append(problems, "memory must be greater than zero")
+	}
+	for i, n := range s.Networks {
+		if n.NetworkUUID == "" {
+			problems = append(problems,
+				fmt.Sprintf("network %d has no network UUID", i))
+		}
+	}
+
+	if len(s.Disks) == 0 {
+		problems = append(problems, "at least one disk is required")
+	}
+	for i, d := range s.Disks {
+		if !oneOf(d.Bus, DiskBuses) {
+			problems = append(problems, fmt.Sprintf(
+				"disk %d has unknown bus %q, expected one of %s",
+				i, d.Bus, choices(DiskBuses)))
+		}
+		if !oneOf(d.Type, DiskTypes) {
+			problems = append(problems, fmt.Sprintf(
+				"disk %d has unknown type %q, expected one of %s",
+				i, d.Type, choices(DiskTypes)))
+		}
+	}
+
+	if s.Video != (VideoSpec{}) && !oneOf(s.Video.Model, VideoModels) {
+		problems = append(problems, fmt.Sprintf(
+			"unknown video model %q, expected one of %s",
+			s.Video.Model, choices(VideoModels)))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Subject: "instance spec", Problems: problems}
+	}
+	return nil
+}
+
+// oneOf reports whether value is in allowed.
+func oneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+// choices formats the non-empty allowed values for an error message.
+func choices(allowed []string) string {
+	var quoted []string
+	for _, a := range allowed {
+		if a != "" {
+			quoted = append(quoted, fmt.Sprintf("%q", a))
+		}
+	}
+	sort.Strings(quoted)
+	return strings.Join(quoted, ", ")
+}
+
+// CreateInstanceFromSpec validates a spec and creates the instance it
+// describes.
+func (c *Client) CreateInstanceFromSpec(spec InstanceSpec) (Instance, error) {
+	return c.CreateInstanceFromSpecCtx(context.Background(), spec)
+}
+
+// CreateInstanceFromSpecCtx is CreateInstanceFromSpec with a caller supplied
+// context.
+func (c *Client) CreateInstanceFromSpecCtx(ctx context.Context,
+	spec InstanceSpec) (Instance, error) {
+
+	if err := spec.Validate(); err != nil {
+		return Instance{}, err
+	}
+	if spec.Video == (VideoSpec{}) {
+		spec.Video = DefaultVideo
+	}
+	return c.createInstance(ctx, spec)
+}
+
+// createInstance creates an instance without validating the spec.
+func (c *Client) createInstance(ctx context.Context,
+	spec InstanceSpec) (Instance, error) {
+
+	request := &createInstanceRequest{
+		Name:          spec.Name,
+		CPUs:          spec.CPUs,
+		Memory:        spec.Memory,
+		Metadata:      spec.Metadata,
+		NameSpace:     spec.Namespace,
+		Network:       spec.Networks,
+		NVRAMTemplate: spec.NVRAMTemplate,
+		Disk:          spec.Disks,
+		Video:         spec.Video,
+		SecureBoot:    spec.SecureBoot,
+		SSHKey:        spec.SSHKey,
+		UEFI:          spec.UEFI,
+		UserData:      spec.UserData,
+	}
+	instance := Instance{}
+	err := c.doRequestJSON(ctx, "instances", "POST", request, &instance)
+
+	return instance, err
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Instance specs", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	var (
+		client *Client
+		spec   InstanceSpec
+	)
+
+	BeforeEach(func() {
+		// Configure client
+		client = NewClient(test_url, test_namespace, test_key)
+
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewBytesResponder(200, []byte(`{"access_token":"ABC123"}`)))
+
+		spec = InstanceSpec{
+			Name:     "web-1",
+			CPUs:     2,
+			Memory:   2048,
+			Networks: []NetworkSpec{{NetworkUUID: "net-1", Model: "virtio"}},
+			Disks: []DiskSpec{
+				{Base: "debian:11", Size: 20, Bus: "virtio", Type: "disk"},
+				{Base: "https://example.com/seed.iso", Type: "cdrom"},
+			},
+			Video:     VideoSpec{Model: "qxl", Memory: 65536},
+			SSHKey:    "ssh-ed25519 AAAA",
+			UserData:  "I2Nsb3VkLWNvbmZpZw==",
+			Namespace: "web",
+			UEFI:      true,
+		}
+	})
+
+	It("should create an instance from a spec", func() {
+		var sent map[string]interface{}
+		httpmock.RegisterResponder("POST", test_url+"/instances",
+			func(req *http.Request) (*http.Response, error) {
+				body, err := ioutil.ReadAll(req.Body)
+				Expect(err).To(BeNil())
+				Expect(json.Unmarshal(body, &sent)).To(Succeed())
+				return httpmock.NewStringResponse(200,
+					`{"uuid": "123-456", "name": "web-1"}`), nil
+			})
+
+		inst, err := client.CreateInstanceFromSpec(spec)
+		Expect(err).To(BeNil())
+		Expect(inst.UUID).To(Equal("123-456"))
+
+		Expect(sent["name"]).To(Equal("web-1"))
+		Expect(sent["cpus"]).To(Equal(float64(2)))
+		Expect(sent["memory"]).To(Equal(float64(2048)))
+		Expect(sent["namespace"]).To(Equal("web"))
+		Expect(sent["uefi"]).To(Equal(true))
+		Expect(sent["network"]).To(Equal([]interface{}{map[string]interface{}{
+			"network_uuid": "net-1", "address": "", "macaddress": "",
+			"model": "virtio"}}))
+		Expect(sent["disk"]).To(HaveLen(2))
+		Expect(sent["video"]).To(Equal(map[string]interface{}{
+			"model": "qxl", "memory": float64(65536)}))
+	})
+
+	It("should use the default video card", func() {
+		var sent createInstanceRequest
+		httpmock.RegisterResponder("POST", test_url+"/instances",
+			func(req *http.Request) (*http.Response, error) {
+				Expect(json.NewDecoder(req.Body).Decode(&sent)).To(Succeed())
+				return httpmock.NewStringResponse(200, `{}`), nil
+			})
+
+		spec.Video = VideoSpec{}
+		_, err := client.CreateInstanceFromSpec(spec)
+		Expect(err).To(BeNil())
+		Expect(sent.Video).To(Equal(DefaultVideo))
+	})
+
+	It("should report every problem with a spec at once", func() {
+		bad := InstanceSpec{
+			Memory: -1,
+			Video:  VideoSpec{Model: "matrox"},
+		}
+		err := bad.Validate()
+		Expect(IsValidation(err)).To(BeTrue())
+
+		invalid := err.(*ValidationError)
+		Expect(invalid.Problems).To(Equal([]string{
+			"name is required",
+			"cpus must be greater than zero",
+			"memory must be greater than zero",
+			"at least one disk is required",
+			`unknown video model "matrox", expected one of "cirrus", "none", "qxl", "vga", "virtio"`,
+		}))
+		Expect(err.Error()).To(HavePrefix("invalid instance spec: name is required; "))
+
+		spec.Disks = append(spec.Disks, DiskSpec{Size: 8, Bus: "floppy", Type: "tape"})
+		spec.Networks = append(spec.Networks, NetworkSpec{})
+		Expect(spec.Validate()).To(MatchError(
+			`invalid instance spec: network 1 has no network UUID; ` +
+				`disk 2 has unknown bus "floppy", expected one of "ide", "nvme", "sata", "scsi", "usb", "virtio"; ` +
+				`disk 2 has unknown type "tape", expected one of "cdrom", "disk"`))
+	})
+
+	It("should not send an invalid spec", func() {
+		spec.CPUs = 0
+		_, err := client.CreateInstanceFromSpec(spec)
+		Expect(IsValidation(err)).To(BeTrue())
+		Expect(httpmock.GetTotalCallCount()).To(Equal(0))
+	})
+
+	It("should round trip through JSON", func() {
+		data, err := json.Marshal(spec)
+		Expect(err).To(BeNil())
+
+		var decoded InstanceSpec
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(spec))
+	})
+
+	It("should round trip through YAML", func() {
+		data, err := yaml.Marshal(spec)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(ContainSubstring("ssh-key: ssh-ed25519 AAAA\n"))
+		Expect(string(data)).To(ContainSubstring("- network-uuid: net-1\n"))
+
+		var decoded InstanceSpec
+		Expect(yaml.Unmarshal(data, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(spec))
+	})
+
+	It("should read a spec kept in YAML", func() {
+		data := []byte(`name: db-1
+cpus: 4
+memory: 8192
+networks:
+  - network-uuid: net-2
+disks:
+  - base: ubuntu:22.04
+    size: 50
+    bus: virtio
+video:
+  model: vga
+secure-boot: true
+`)
+		var decoded InstanceSpec
+		Expect(yaml.Unmarshal(data, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(InstanceSpec{
+			Name:       "db-1",
+			CPUs:       4,
+			Memory:     8192,
+			Networks:   []NetworkSpec{{NetworkUUID: "net-2"}},
+			Disks:      []DiskSpec{{Base: "ubuntu:22.04", Size: 50, Bus: "virtio"}},
+			Video:      VideoSpec{Model: "vga"},
+			SecureBoot: true,
+		}))
+		Expect(decoded.Validate()).To(Succeed())
+	})
+})
@@ -0,0 +1,339 @@
+// Command mockgen writes fakes of the interfaces in a source file of the
+// client package. Each fake has a function field for every method, and
+// records the calls made to it.
+//
+// It only uses the standard library, so it needs no tools installed. Run it
+// with "go generate" from the root of the module.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	source := flag.String("source", "api.go", "file declaring the interfaces")
+	out := flag.String("out", "mock/mock.go", "file to write the fakes to")
+	pkg := flag.String("package", "mock", "package name of the fakes")
+	importPath := flag.String("import", "github.com/shakenfist/client-go",
+		"import path of the package declaring the interfaces")
+	qualifier := flag.String("qualifier", "client",
+		"name the fakes use for the package declaring the interfaces")
+	flag.Parse()
+
+	src, err := generate(*source, *pkg, *importPath, *qualifier)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// method is an interface method.
+type method struct {
+	name    string
+	params  []param
+	results []string
+}
+
+// param is a method parameter.
+type param struct {
+	name     string
+	typ      string
+	variadic bool
+}
+
+// generator holds the state for one source file.
+type generator struct {
+	qualifier  string
+	interfaces map[string]*ast.InterfaceType
+	imports    map[string]string
+	used       map[string]bool
+}
+
+// generate returns the source of the fakes for the interfaces in source.
+func generate(source, pkg, importPath, qualifier string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, source, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		qualifier:  qualifier,
+		interfaces: map[string]*ast.InterfaceType{},
+		imports:    map[string]string{},
+		used:       map[string]bool{},
+	}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		g.imports[name] = path
+	}
+
+	var names []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if it, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.IsExported() {
+				g.interfaces[ts.Name.Name] = it
+				names = append(names, ts.Name.Name)
+			}
+		}
+	}
+
+	body := &bytes.Buffer{}
+	for _, name := range names {
+		methods, err := g.methods(name)
+		if err != nil {
+			return nil, err
+		}
+		g.writeFake(body, name, methods)
+	}
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "// Code generated by internal/mockgen from %s. DO NOT EDIT.\n\n",
+		filepath.Base(source))
+	fmt.Fprintf(out, "package %s\n\n", pkg)
+	fmt.Fprintln(out, "import (")
+	var stdlib []string
+	for name := range g.used {
+		stdlib = append(stdlib, strconv.Quote(g.imports[name]))
+	}
+	sort.Strings(stdlib)
+	for _, path := range stdlib {
+		fmt.Fprintf(out, "\t%s\n", path)
+	}
+	fmt.Fprintf(out, "\n\t%s %q\n)\n", qualifier, importPath)
+	out.Write(body.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+// methods returns the methods of an interface, including those of the
+// interfaces it embeds.
+func (g *generator) methods(name string) ([]method, error) {
+	it, ok := g.interfaces[name]
+	if !ok {
+		return nil, fmt.Errorf("interface %s is not declared in the source file", name)
+	}
+
+	var methods []method
+	for _, field := range it.Methods.List {
+		if len(field.Names) == 0 {
+			ident, ok := field.Type.(*ast.Ident)
+			if !ok {
+				return nil, fmt.Errorf("%s embeds an interface from another package",
+					name)
+			}
+			embedded, err := g.methods(ident.Name)
+			if err != nil {
+				return nil, err
+			}
+			methods = append(methods, embedded...)
+			continue
+		}
+
+		ft := field.Type.(*ast.FuncType)
+		m := method{name: field.Names[0].Name}
+		for i, p := range ft.Params.List {
+			typ, variadic := p.Type, false
+			if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+				typ, variadic = ellipsis.Elt, true
+			}
+			names := p.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("p%d", i))}
+			}
+			for _, n := range names {
+				if n.Name == "m" {
+					return nil, fmt.Errorf("%s.%s has a parameter named m, "+
+						"which is the receiver of the fake", name, m.name)
+				}
+				m.params = append(m.params, param{n.Name, g.typeString(typ), variadic})
+			}
+		}
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				count := len(r.Names)
+				if count == 0 {
+					count = 1
+				}
+				for i := 0; i < count; i++ {
+					m.results = append(m.results, g.typeString(r.Type))
+				}
+			}
+		}
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
+
+// typeString formats a type as it is written in the fakes' package.
+func (g *generator) typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if t.IsExported() {
+			return g.qualifier + "." + t.Name
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		pkg := t.X.(*ast.Ident).Name
+		g.used[pkg] = true
+		return pkg + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + g.typeString(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + g.typeString(t.Elt)
+		}
+		return "[" + t.Len.(*ast.BasicLit).Value + "]" + g.typeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + g.typeString(t.Key) + "]" + g.typeString(t.Value)
+	case *ast.Ellipsis:
+		return "..." + g.typeString(t.Elt)
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + g.typeString(t.Value)
+		case ast.RECV:
+			return "<-chan " + g.typeString(t.Value)
+		}
+		return "chan " + g.typeString(t.Value)
+	case *ast.InterfaceType:
+		if len(t.Methods.List) == 0 {
+			return "interface{}"
+		}
+	case *ast.StructType:
+		if len(t.Fields.List) == 0 {
+			return "struct{}"
+		}
+	case *ast.FuncType:
+		var params, results []string
+		for _, p := range t.Params.List {
+			for range fieldNames(p) {
+				params = append(params, g.typeString(p.Type))
+			}
+		}
+		if t.Results != nil {
+			for _, r := range t.Results.List {
+				for range fieldNames(r) {
+					results = append(results, g.typeString(r.Type))
+				}
+			}
+		}
+		return "func(" + strings.Join(params, ", ") + ")" + resultList(results)
+	}
+	log.Fatalf("unsupported type %T", expr)
+	return ""
+}
+
+// fieldNames returns the names of a field, or one empty name if it has none.
+func fieldNames(f *ast.Field) []string {
+	if len(f.Names) == 0 {
+		return []string{""}
+	}
+	names := make([]string, len(f.Names))
+	for i, n := range f.Names {
+		names[i] = n.Name
+	}
+	return names
+}
+
+// resultList formats the results of a function.
+func resultList(results []string) string {
+	switch len(results) {
+	case 0:
+		return ""
+	case 1:
+		return " " + results[0]
+	}
+	return " (" + strings.Join(results, ", ") + ")"
+}
+
+// writeFake writes the fake for an interface.
+func (g *generator) writeFake(out *bytes.Buffer, name string, methods []method) {
+	fmt.Fprintf(out, "\n// %s is a fake %s.%s. Each method calls the field of the\n",
+		name, g.qualifier, name)
+	fmt.Fprintln(out, "// same name with Func appended, or returns zero values if it is nil.")
+	fmt.Fprintf(out, "type %s struct {\n\tRecorder\n\n", name)
+	for _, m := range methods {
+		fmt.Fprintf(out, "\t%sFunc func(%s)%s\n", m.name, m.paramTypes(),
+			resultList(m.results))
+	}
+	fmt.Fprintln(out, "}")
+	fmt.Fprintf(out, "\nvar _ %s.%s = (*%s)(nil)\n", g.qualifier, name, name)
+
+	for _, m := range methods {
+		var decls, args, record []string
+		for _, p := range m.params {
+			typ := p.typ
+			arg := p.name
+			if p.variadic {
+				typ = "..." + typ
+				arg += "..."
+			}
+			decls = append(decls, p.name+" "+typ)
+			args = append(args, arg)
+			record = append(record, p.name)
+		}
+
+		fmt.Fprintf(out, "\n// %s implements %s.%s.\n", m.name, g.qualifier, name)
+		fmt.Fprintf(out, "func (m *%s) %s(%s)%s {\n", name, m.name,
+			strings.Join(decls, ", "), resultList(m.results))
+		fmt.Fprintf(out, "\tm.record(%s)\n",
+			strings.Join(append([]string{strconv.Quote(m.name)}, record...), ", "))
+
+		call := fmt.Sprintf("m.%sFunc(%s)", m.name, strings.Join(args, ", "))
+		fmt.Fprintf(out, "\tif m.%sFunc != nil {\n", m.name)
+		if len(m.results) == 0 {
+			fmt.Fprintf(out, "\t\t%s\n\t\treturn\n\t}\n}\n", call)
+			continue
+		}
+		fmt.Fprintf(out, "\t\treturn %s\n\t}\n", call)
+
+		if len(m.results) == 1 && m.results[0] == "error" {
+			fmt.Fprintln(out, "\treturn nil\n}")
+			continue
+		}
+		var zeros []string
+		for i, r := range m.results {
+			zero := fmt.Sprintf("r%d", i)
+			fmt.Fprintf(out, "\tvar %s %s\n", zero, r)
+			zeros = append(zeros, zero)
+		}
+		fmt.Fprintf(out, "\treturn %s\n}\n", strings.Join(zeros, ", "))
+	}
+}
+
+// paramTypes formats the parameter types of a method.
+func (m method) paramTypes() string {
+	var types []string
+	for _, p := range m.params {
+		if p.variadic {
+			types = append(types, "..."+p.typ)
+		} else {
+			types = append(types, p.typ)
+		}
+	}
+	return strings.Join(types, ", ")
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mock generation", func() {
+	It("should match the committed fakes", func() {
+		want, err := generate("../../api.go", "mock",
+			"github.com/shakenfist/client-go", "client")
+		Expect(err).To(BeNil())
+
+		got, err := ioutil.ReadFile("../../mock/mock.go")
+		Expect(err).To(BeNil())
+		Expect(string(got)).To(Equal(string(want)),
+			"mock/mock.go is out of date, run go generate")
+	})
+
+	It("should handle other kinds of parameters", func() {
+		dir, err := ioutil.TempDir("", "mockgen")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "api.go")
+		Expect(ioutil.WriteFile(path, []byte(`package client
+
+import "io"
+
+type Base interface {
+	Close() error
+}
+
+type Uploader interface {
+	Base
+	Upload(r io.Reader, tags ...string) (*Blob, map[string][]byte)
+	Watch(done <-chan struct{}, fn func(int) bool)
+}
+`), 0600)).To(Succeed())
+
+		src, err := generate(path, "fake", "example.com/client", "client")
+		Expect(err).To(BeNil())
+		Expect(string(src)).To(ContainSubstring(
+			"UploadFunc func(io.Reader, ...string) (*client.Blob, map[string][]byte)"))
+		Expect(string(src)).To(ContainSubstring(
+			"func (m *Uploader) Upload(r io.Reader, tags ...string) (*client.Blob, map[string][]byte) {"))
+		Expect(string(src)).To(ContainSubstring("return m.UploadFunc(r, tags...)"))
+		Expect(string(src)).To(MatchRegexp(
+			`WatchFunc +func\(<-chan struct\{\}, func\(int\) bool\)`))
+		Expect(string(src)).To(ContainSubstring("CloseFunc func() error"))
+		Expect(string(src)).To(ContainSubstring("var _ client.Uploader = (*Uploader)(nil)"))
+	})
+})
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replaces credentials in logged requests and responses.
+const redacted = "REDACTED"
+
+// maxLoggedBody limits how much of a body is kept in debug mode.
+const maxLoggedBody = 64 * 1024
+
+// redactedFields are JSON body fields which are never logged.
+var redactedFields = map[string]bool{
+	"key":          true,
+	"access_token": true,
+}
+
+// RequestLogEntry describes one HTTP round trip made by the client.
+type RequestLogEntry struct {
+	Method   string
+	Path     string
+	Endpoint string
+
+	// StatusCode is zero if no response was received.
+	StatusCode int
+	Duration   time.Duration
+	Attempt    int
+
+	// RequestID is the X-Request-ID sent, shared by every request for a
+	// call.
+	RequestID string
+
+	RequestSize  int64
+	ResponseSize int64
+	Err          error
+
+	// Headers and bodies are only filled in when debug logging is enabled.
+	// Credentials are always redacted.
+	RequestHeaders http.Header
+	RequestBody    string
+	ResponseBody   string
+}
+
+// RequestLogger receives an entry for every HTTP request the client makes.
+type RequestLogger interface {
+	LogRequest(entry RequestLogEntry)
+}
+
+// RequestLoggerFunc adapts a function to the RequestLogger interface. It is
+// the simplest way to feed entries into a structured logging library.
+type RequestLoggerFunc func(entry RequestLogEntry)
+
+// LogRequest implements RequestLogger.
+func (f RequestLoggerFunc) LogRequest(entry RequestLogEntry) {
+	f(entry)
+}
+
+// PrintfRequestLogger returns a RequestLogger which writes each entry to l
+// as a line of key=value pairs.
+func PrintfRequestLogger(l Logger) RequestLogger {
+	return RequestLoggerFunc(func(e RequestLogEntry) {
+		fields := []string{
+			"method=" + e.Method,
+			"path=" + e.Path,
+			fmt.Sprintf("status=%d", e.StatusCode),
+			fmt.Sprintf("duration=%v", e.Duration),
+			fmt.Sprintf("attempt=%d", e.Attempt),
+			fmt.Sprintf("request_bytes=%d", e.RequestSize),
+			fmt.Sprintf("response_bytes=%d", e.ResponseSize),
+		}
+		if e.Endpoint != "" {
+			fields = append(fields, "endpoint="+e.Endpoint)
+		}
+		if e.Err != nil {
+			fields = append(fields, fmt.Sprintf("error=%q", e.Err.Error()))
+		}
+		if e.RequestHeaders != nil {
+			fields = append(fields, fmt.Sprintf("request_headers=%q",
+				fmt.Sprint(e.RequestHeaders)))
+		}
+		if e.RequestBody != "" {
+			fields = append(fields, fmt.Sprintf("request_body=%q", e.RequestBody))
+		}
+		if e.ResponseBody != "" {
+			fields = append(fields, fmt.Sprintf("response_body=%q", e.ResponseBody))
+		}
+		l.Printf("%s", strings.Join(fields, " "))
+	})
+}
+
+// WithRequestLogger sets a logger which receives an entry for every HTTP
+// request made by the client.
+func WithRequestLogger(l RequestLogger) Option {
+	return func(c *Client) error {
+		c.requestLogger = l
+		return nil
+	}
+}
+
+// WithDebugLogging adds headers and bodies to request log entries. Auth
+// headers, API keys and tokens are redacted.
+func WithDebugLogging() Option {
+	return func(c *Client) error {
+		c.debugLogging = true
+		return nil
+	}
+}
+
+// logRequest passes an entry to the request logger, if there is one.
+func (c *Client) logRequest(entry RequestLogEntry) {
+	if c.requestLogger != nil {
+		c.requestLogger.LogRequest(entry)
+	}
+}
+
+// redactHeaders returns a copy of h with credentials removed.
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	if out.Get("Authorization") != "" {
+		out.Set("Authorization", redacted)
+	}
+	return out
+}
+
+// redactBody returns a body for logging with credential fields removed.
+// Bodies which are not JSON are summarised rather than logged, as they
+// cannot be checked for secrets.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return fmt.Sprintf("<%d bytes of non-JSON data>", len(body))
+	}
+
+	out, err := json.Marshal(redactValue(decoded))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	if len(out) > maxLoggedBody {
+		out = append(out[:maxLoggedBody], "..."...)
+	}
+	return string(out)
+}
+
+// redactValue walks a decoded JSON value replacing credential fields.
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if redactedFields[k] {
+				t[k] = redacted
+			} else {
+				t[k] = redactValue(val)
+			}
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redactValue(t[i])
+		}
+	}
+	return v
+}
+
+// loggedBody wraps a successful response body, counting the bytes read so
+// the request can be logged once the caller has finished with it.
+type loggedBody struct {
+	io.ReadCloser
+	client  *Client
+	entry   RequestLogEntry
+	capture *strings.Builder
+	once    sync.Once
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.entry.ResponseSize += int64(n)
+	if b.capture != nil && b.capture.Len() < maxLoggedBody {
+		b.capture.Write(p[:n])
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	// In debug mode log the body even if the caller did not need it
+	if b.capture != nil {
+		io.CopyN(ioutil.Discard, b, maxLoggedBody)
+	}
+
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+// finish logs the request, exactly once.
+func (b *loggedBody) finish() {
+	b.once.Do(func() {
+		if b.capture != nil {
+			b.entry.ResponseBody = redactBody([]byte(b.capture.String()))
+		}
+		b.client.logRequest(b.entry)
+	})
+}
+
+type attemptKey struct{}
+
+// withAttempt records which attempt of a request ctx belongs to.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// attemptFrom returns the attempt number recorded in ctx, or 1.
+func attemptFrom(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
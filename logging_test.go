@@ -0,0 +1,153 @@
+package client
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request logging", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	var (
+		lock    sync.Mutex
+		entries []RequestLogEntry
+		logger  RequestLogger
+	)
+
+	newClient := func(opts ...Option) *Client {
+		opts = append([]Option{WithRequestLogger(logger)}, opts...)
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			opts...)
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	BeforeEach(func() {
+		entries = []RequestLogEntry{}
+		logger = RequestLoggerFunc(func(e RequestLogEntry) {
+			lock.Lock()
+			defer lock.Unlock()
+			entries = append(entries, e)
+		})
+
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewBytesResponder(200, []byte(`{"access_token":"ABC123"}`)))
+	})
+
+	It("should log every request", func() {
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			httpmock.NewStringResponder(200, `[{"name": "sf-1"}]`))
+
+		_, err := newClient().GetNodes()
+		Expect(err).To(BeNil())
+
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Method).To(Equal("POST"))
+		Expect(entries[0].Path).To(Equal("auth"))
+		Expect(entries[0].RequestSize).To(BeNumerically(">", 0))
+
+		Expect(entries[1].Method).To(Equal("GET"))
+		Expect(entries[1].Path).To(Equal("nodes"))
+		Expect(entries[1].Endpoint).To(Equal(test_url))
+		Expect(entries[1].StatusCode).To(Equal(200))
+		Expect(entries[1].Attempt).To(Equal(1))
+		Expect(entries[1].RequestSize).To(Equal(int64(0)))
+		Expect(entries[1].ResponseSize).To(Equal(int64(18)))
+		Expect(entries[1].Duration).To(BeNumerically(">=", 0))
+		Expect(entries[1].Err).To(BeNil())
+
+		// Bodies are only logged in debug mode
+		Expect(entries[1].RequestHeaders).To(BeNil())
+		Expect(entries[1].ResponseBody).To(Equal(""))
+	})
+
+	It("should log failed attempts with their attempt number", func() {
+		calls := 0
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					return httpmock.NewStringResponse(503, `{"error":"busy"}`), nil
+				}
+				return httpmock.NewStringResponse(200, `[]`), nil
+			})
+
+		policy := DefaultRetryPolicy()
+		policy.InitialBackoff = time.Millisecond
+		_, err := newClient(WithRetryPolicy(policy)).GetNodes()
+		Expect(err).To(BeNil())
+
+		Expect(entries).To(HaveLen(3))
+		Expect(entries[1].StatusCode).To(Equal(503))
+		Expect(entries[1].Attempt).To(Equal(1))
+		Expect(IsServerError(entries[1].Err)).To(BeTrue())
+		Expect(entries[1].ResponseSize).To(Equal(int64(16)))
+		Expect(entries[2].StatusCode).To(Equal(200))
+		Expect(entries[2].Attempt).To(Equal(2))
+	})
+
+	It("should dump bodies in debug mode with credentials redacted", func() {
+		httpmock.RegisterResponder("POST",
+			test_url+"/auth/namespaces/bobspace/keys",
+			httpmock.NewStringResponder(200, `{"status": "ok"}`))
+
+		err := newClient(WithDebugLogging()).CreateNamespaceKey(
+			"bobspace", "deploy", "supersecret")
+		Expect(err).To(BeNil())
+
+		Expect(entries).To(HaveLen(2))
+
+		auth := entries[0]
+		Expect(auth.RequestBody).To(MatchJSON(
+			`{"namespace": "testspace", "key": "REDACTED"}`))
+		Expect(auth.ResponseBody).To(MatchJSON(`{"access_token": "REDACTED"}`))
+
+		create := entries[1]
+		Expect(create.RequestHeaders.Get("Authorization")).To(Equal("REDACTED"))
+		Expect(create.RequestHeaders.Get("Content-Type")).To(
+			Equal("application/json"))
+		Expect(create.RequestBody).To(MatchJSON(
+			`{"key_name": "deploy", "key": "REDACTED"}`))
+		Expect(create.ResponseBody).To(MatchJSON(`{"status": "ok"}`))
+	})
+
+	It("should never write credentials through the printf adapter", func() {
+		httpmock.RegisterResponder("PUT",
+			test_url+"/auth/namespaces/bobspace/keys/deploy",
+			httpmock.NewStringResponder(500, `{"error": "oops"}`))
+
+		out := &bytes.Buffer{}
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithRequestLogger(PrintfRequestLogger(log.New(out, "", 0))),
+			WithDebugLogging(), WithRetryPolicy(nil))
+		Expect(err).To(BeNil())
+
+		err = client.UpdateNamespaceKey("bobspace", "deploy", "supersecret")
+		Expect(err).ToNot(BeNil())
+
+		Expect(out.String()).To(ContainSubstring("method=PUT"))
+		Expect(out.String()).To(ContainSubstring("status=500"))
+		Expect(out.String()).ToNot(ContainSubstring("supersecret"))
+		Expect(out.String()).ToNot(ContainSubstring(test_key))
+		Expect(out.String()).ToNot(ContainSubstring("ABC123"))
+	})
+
+	It("should summarise bodies which are not JSON", func() {
+		Expect(redactBody([]byte("key=secret"))).To(
+			Equal("<10 bytes of non-JSON data>"))
+		Expect(redactBody(nil)).To(Equal(""))
+		Expect(redactBody([]byte(`[{"key": "x", "keep": "y"}]`))).To(
+			MatchJSON(`[{"key": "REDACTED", "keep": "y"}]`))
+	})
+})
@@ -3,8 +3,7 @@ package client
 // Metadata key-value set and retrieval on a specific instance.
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 )
 
@@ -16,55 +15,128 @@ func (c *Client) GetNamespaceMetadata(uuid string) (Metadata, error) {
 	return c.GetMetadata(TypeNamespace, uuid)
 }
 
+// GetNamespaceMetadataCtx is GetNamespaceMetadata with a caller supplied context.
+func (c *Client) GetNamespaceMetadataCtx(ctx context.Context,
+	uuid string) (Metadata, error) {
+
+	return c.GetMetadataCtx(ctx, TypeNamespace, uuid)
+}
+
 // SetNamespaceMetadata sets metadata for the namespace
 func (c *Client) SetNamespaceMetadata(uuid, key, value string) error {
 	return c.SetMetadata(TypeNamespace, uuid, key, value)
 }
 
+// SetNamespaceMetadataCtx is SetNamespaceMetadata with a caller supplied context.
+func (c *Client) SetNamespaceMetadataCtx(ctx context.Context,
+	uuid, key, value string) error {
+
+	return c.SetMetadataCtx(ctx, TypeNamespace, uuid, key, value)
+}
+
 // Delete metadata from the namespace
 func (c *Client) DeleteNamespaceMetadata(uuid, key string) error {
 	return c.DeleteMetadata(TypeNamespace, uuid, key)
 }
 
+// DeleteNamespaceMetadataCtx is DeleteNamespaceMetadata with a caller
+// supplied context.
+func (c *Client) DeleteNamespaceMetadataCtx(ctx context.Context,
+	uuid, key string) error {
+
+	return c.DeleteMetadataCtx(ctx, TypeNamespace, uuid, key)
+}
+
 // GetInstanceMetadata retrieves metadata for the instance
 func (c *Client) GetInstanceMetadata(uuid string) (Metadata, error) {
 	return c.GetMetadata(TypeInstance, uuid)
 }
 
+// GetInstanceMetadataCtx is GetInstanceMetadata with a caller supplied context.
+func (c *Client) GetInstanceMetadataCtx(ctx context.Context,
+	uuid string) (Metadata, error) {
+
+	return c.GetMetadataCtx(ctx, TypeInstance, uuid)
+}
+
 // SetInstanceMetadata sets metadata for the instance
 func (c *Client) SetInstanceMetadata(uuid, key, value string) error {
 	return c.SetMetadata(TypeInstance, uuid, key, value)
 }
 
+// SetInstanceMetadataCtx is SetInstanceMetadata with a caller supplied context.
+func (c *Client) SetInstanceMetadataCtx(ctx context.Context,
+	uuid, key, value string) error {
+
+	return c.SetMetadataCtx(ctx, TypeInstance, uuid, key, value)
+}
+
 // Delete metadata from the instance
 func (c *Client) DeleteInstanceMetadata(uuid, key string) error {
 	return c.DeleteMetadata(TypeInstance, uuid, key)
 }
 
+// DeleteInstanceMetadataCtx is DeleteInstanceMetadata with a caller supplied
+// context.
+func (c *Client) DeleteInstanceMetadataCtx(ctx context.Context,
+	uuid, key string) error {
+
+	return c.DeleteMetadataCtx(ctx, TypeInstance, uuid, key)
+}
+
 // GetNetworkMetadata retrieves metadata for the network
 func (c *Client) GetNetworkMetadata(uuid string) (Metadata, error) {
 	return c.GetMetadata(TypeNetwork, uuid)
 }
 
+// GetNetworkMetadataCtx is GetNetworkMetadata with a caller supplied context.
+func (c *Client) GetNetworkMetadataCtx(ctx context.Context,
+	uuid string) (Metadata, error) {
+
+	return c.GetMetadataCtx(ctx, TypeNetwork, uuid)
+}
+
 // SetNetworkMetadata sets metadata for the network
 func (c *Client) SetNetworkMetadata(uuid, key, value string) error {
 	return c.SetMetadata(TypeNetwork, uuid, key, value)
 }
 
+// SetNetworkMetadataCtx is SetNetworkMetadata with a caller supplied context.
+func (c *Client) SetNetworkMetadataCtx(ctx context.Context,
+	uuid, key, value string) error {
+
+	return c.SetMetadataCtx(ctx, TypeNetwork, uuid, key, value)
+}
+
 // Delete metadata from the Network
 func (c *Client) DeleteNetworkMetadata(uuid, key string) error {
 	return c.DeleteMetadata(TypeNetwork, uuid, key)
 }
 
+// DeleteNetworkMetadataCtx is DeleteNetworkMetadata with a caller supplied
+// context.
+func (c *Client) DeleteNetworkMetadataCtx(ctx context.Context,
+	uuid, key string) error {
+
+	return c.DeleteMetadataCtx(ctx, TypeNetwork, uuid, key)
+}
+
 //
 // Common functions
 //
 
 // GetMetadata retrieves the metadata attached to an instance.
 func (c *Client) GetMetadata(res ResourceType, uuid string) (Metadata, error) {
+	return c.GetMetadataCtx(context.Background(), res, uuid)
+}
+
+// GetMetadataCtx is GetMetadata with a caller supplied context.
+func (c *Client) GetMetadataCtx(ctx context.Context,
+	res ResourceType, uuid string) (Metadata, error) {
+
 	meta := Metadata{}
-	if err := c.getRequest(res.String(), uuid, "metadata", &meta); err != nil {
-		return meta, fmt.Errorf("unable to retrieve metadata: %v", err)
+	if err := c.getRequest(ctx, res.String(), uuid, "metadata", &meta); err != nil {
+		return meta, fmt.Errorf("unable to retrieve metadata: %w", err)
 	}
 
 	return meta, nil
@@ -76,20 +148,22 @@ type reqMeta struct {
 
 // SetMetadata sets key-value metadata on an instance.
 func (c *Client) SetMetadata(res ResourceType, uuid, key, value string) error {
+	return c.SetMetadataCtx(context.Background(), res, uuid, key, value)
+}
+
+// SetMetadataCtx is SetMetadata with a caller supplied context.
+func (c *Client) SetMetadataCtx(ctx context.Context,
+	res ResourceType, uuid, key, value string) error {
+
 	path := res.String() + "/" + uuid + "/metadata/" + key
 
 	req := &reqMeta{
 		Value: value,
 	}
 
-	post, err := json.Marshal(req)
+	err := c.doRequestJSON(ctx, path, "PUT", req, nil)
 	if err != nil {
-		return fmt.Errorf("cannot marshal data into JSON: %v", err)
-	}
-
-	err = c.doRequestJSON(path, "PUT", *bytes.NewBuffer(post), nil)
-	if err != nil {
-		return fmt.Errorf("unable to set metadata: %v", err)
+		return fmt.Errorf("unable to set metadata: %w", err)
 	}
 
 	return nil
@@ -97,10 +171,17 @@ func (c *Client) SetMetadata(res ResourceType, uuid, key, value string) error {
 
 // DeleteMetadata retrieves the metadata attached to an instance.
 func (c *Client) DeleteMetadata(res ResourceType, uuid, key string) error {
+	return c.DeleteMetadataCtx(context.Background(), res, uuid, key)
+}
+
+// DeleteMetadataCtx is DeleteMetadata with a caller supplied context.
+func (c *Client) DeleteMetadataCtx(ctx context.Context,
+	res ResourceType, uuid, key string) error {
+
 	path := res.String() + "/" + uuid + "/metadata/" + key
 
-	if err := c.doRequestJSON(path, "DELETE", bytes.Buffer{}, nil); err != nil {
-		return fmt.Errorf("unable to delete metadata: %v", err)
+	if err := c.doRequestJSON(ctx, path, "DELETE", nil, nil); err != nil {
+		return fmt.Errorf("unable to delete metadata: %w", err)
 	}
 
 	return nil
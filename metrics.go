@@ -0,0 +1,289 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestMetric describes one HTTP request for metrics collection. Route is
+// the path with identifiers replaced by placeholders, for example
+// "instances/{uuid}/metadata/{key}", so it is safe to use as a label.
+type RequestMetric struct {
+	Endpoint    string
+	Method      string
+	Route       string
+	StatusClass string // "2xx", "4xx", "5xx" or "error" if there was no response
+	Retries     int
+	Duration    time.Duration
+}
+
+// MetricsCollector receives a metric for every HTTP request the client
+// makes.
+type MetricsCollector interface {
+	ObserveRequest(m RequestMetric)
+}
+
+// WithMetrics sets a collector for request metrics.
+func WithMetrics(collector MetricsCollector) Option {
+	return func(c *Client) error {
+		c.metrics = collector
+		return nil
+	}
+}
+
+// observeRequest passes the metric for a request to the collector, if
+// there is one.
+func (c *Client) observeRequest(ctx context.Context, endpoint, path,
+	method string, statusCode int, duration time.Duration) {
+
+	if c.metrics == nil {
+		return
+	}
+
+	c.metrics.ObserveRequest(RequestMetric{
+		Endpoint:    endpoint,
+		Method:      method,
+		Route:       routeTemplate(path),
+		StatusClass: statusClass(statusCode),
+		Retries:     attemptFrom(ctx) - 1,
+		Duration:    duration,
+	})
+}
+
+// statusClass groups status codes for use as a metric label.
+func statusClass(code int) string {
+	if code == 0 {
+		return "error"
+	}
+	return fmt.Sprintf("%dxx", code/100)
+}
+
+// routes are the API paths the client uses. A segment in braces matches
+// any value and "*" keeps the value as is, for fixed action names.
+var routes = [][]string{
+	{""},
+	{"auth"},
+	{"auth", "namespaces"},
+	{"auth", "namespaces", "{namespace}", "keys", "{key_name}"},
+	{"auth", "namespaces", "{namespace}", "metadata", "{key}"},
+	{"auth", "namespaces", "{namespace}", "*"},
+	{"auth", "namespaces", "{namespace}"},
+	{"instances", "{uuid}", "metadata", "{key}"},
+	{"instances", "{uuid}", "*"},
+	{"instances", "{uuid}"},
+	{"networks", "{uuid}", "metadata", "{key}"},
+	{"networks", "{uuid}", "*"},
+	{"networks", "{uuid}"},
+	{"interfaces", "{uuid}", "*"},
+	{"interfaces", "{uuid}"},
+	{"artifacts", "{uuid}", "*"},
+	{"artifacts", "{uuid}"},
+	{"label", "{name}"},
+	{"upload", "{uuid}", "truncate", "{offset}"},
+	{"upload", "{uuid}"},
+	{"admin", "locks"},
+	{"artifacts"},
+	{"images"},
+	{"instances"},
+	{"interfaces"},
+	{"networks"},
+	{"nodes"},
+	{"upload"},
+}
+
+// routeTemplate replaces the identifiers in an API path with placeholders.
+// Paths which match no route are all reported as "other", so a path built
+// from user input cannot add labels without limit.
+func routeTemplate(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, route := range routes {
+		if len(route) != len(segments) {
+			continue
+		}
+
+		out := make([]string, len(route))
+		matched := true
+		for i, want := range route {
+			switch {
+			case want == "*":
+				out[i] = segments[i]
+			case strings.HasPrefix(want, "{"):
+				out[i] = want
+			case want == segments[i]:
+				out[i] = want
+			default:
+				matched = false
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			return strings.Join(out, "/")
+		}
+	}
+
+	return "other"
+}
+
+// DefaultLatencyBuckets are the histogram bucket bounds, in seconds, used
+// by NewPrometheusCollector.
+var DefaultLatencyBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+// PrometheusCollector is a MetricsCollector which serves the metrics it
+// collects in the Prometheus text exposition format.
+type PrometheusCollector struct {
+	lock      sync.Mutex
+	buckets   []float64
+	series    map[seriesKey]*series
+	coalesced map[string]uint64
+}
+
+type seriesKey struct {
+	endpoint, method, route, statusClass string
+	retries                              int
+}
+
+type series struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// NewPrometheusCollector returns an empty collector using
+// DefaultLatencyBuckets.
+func NewPrometheusCollector() *PrometheusCollector {
+	return &PrometheusCollector{
+		buckets:   DefaultLatencyBuckets,
+		series:    map[seriesKey]*series{},
+		coalesced: map[string]uint64{},
+	}
+}
+
+// ObserveRequest implements MetricsCollector.
+func (p *PrometheusCollector) ObserveRequest(m RequestMetric) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	key := seriesKey{m.Endpoint, m.Method, m.Route, m.StatusClass, m.Retries}
+	s, ok := p.series[key]
+	if !ok {
+		s = &series{buckets: make([]uint64, len(p.buckets))}
+		p.series[key] = s
+	}
+
+	secs := m.Duration.Seconds()
+	s.count++
+	s.sum += secs
+	for i, bound := range p.buckets {
+		if secs <= bound {
+			s.buckets[i]++
+		}
+	}
+}
+
+// ObserveCoalesced implements CoalescedRequestCollector.
+func (p *PrometheusCollector) ObserveCoalesced(route string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.coalesced[route]++
+}
+
+// ServeHTTP writes the collected metrics in the Prometheus text format.
+func (p *PrometheusCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprint(w, p.String())
+}
+
+// String returns the collected metrics in the Prometheus text format.
+func (p *PrometheusCollector) String() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	keys := make([]seriesKey, 0, len(p.series))
+	for k := range p.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.endpoint != b.endpoint {
+			return a.endpoint < b.endpoint
+		}
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		if a.statusClass != b.statusClass {
+			return a.statusClass < b.statusClass
+		}
+		return a.retries < b.retries
+	})
+
+	out := &strings.Builder{}
+	fmt.Fprintln(out, "# HELP shakenfist_client_requests_total "+
+		"Requests made to the Shaken Fist API.")
+	fmt.Fprintln(out, "# TYPE shakenfist_client_requests_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(out, "shakenfist_client_requests_total{%s} %d\n",
+			k.labels(), p.series[k].count)
+	}
+
+	fmt.Fprintln(out, "# HELP shakenfist_client_request_duration_seconds "+
+		"Time taken for the Shaken Fist API to respond.")
+	fmt.Fprintln(out, "# TYPE shakenfist_client_request_duration_seconds histogram")
+	for _, k := range keys {
+		s := p.series[k]
+		labels := k.labels()
+		for i, bound := range p.buckets {
+			fmt.Fprintf(out,
+				"shakenfist_client_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels, strconv.FormatFloat(bound, 'g', -1, 64), s.buckets[i])
+		}
+		fmt.Fprintf(out,
+			"shakenfist_client_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n",
+			labels, s.count)
+		fmt.Fprintf(out, "shakenfist_client_request_duration_seconds_sum{%s} %s\n",
+			labels, strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(out, "shakenfist_client_request_duration_seconds_count{%s} %d\n",
+			labels, s.count)
+	}
+
+	if len(p.coalesced) > 0 {
+		routes := make([]string, 0, len(p.coalesced))
+		for route := range p.coalesced {
+			routes = append(routes, route)
+		}
+		sort.Strings(routes)
+
+		fmt.Fprintln(out, "# HELP shakenfist_client_coalesced_requests_total "+
+			"Requests saved by sharing an identical request in progress.")
+		fmt.Fprintln(out, "# TYPE shakenfist_client_coalesced_requests_total counter")
+		for _, route := range routes {
+			fmt.Fprintf(out, "shakenfist_client_coalesced_requests_total{route=%q} %d\n",
+				route, p.coalesced[route])
+		}
+	}
+
+	return out.String()
+}
+
+// labels formats the key as Prometheus labels.
+func (k seriesKey) labels() string {
+	return fmt.Sprintf(
+		"endpoint=%q,method=%q,route=%q,status_class=%q,retries=\"%d\"",
+		k.endpoint, k.method, k.route, k.statusClass, k.retries)
+}
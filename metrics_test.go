@@ -0,0 +1,183 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// collectorFunc adapts a function to the MetricsCollector interface.
+type collectorFunc func(m RequestMetric)
+
+func (f collectorFunc) ObserveRequest(m RequestMetric) {
+	f(m)
+}
+
+var _ = Describe("Metrics", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	var (
+		lock      sync.Mutex
+		metrics   []RequestMetric
+		collector MetricsCollector
+	)
+
+	BeforeEach(func() {
+		metrics = []RequestMetric{}
+		collector = collectorFunc(func(m RequestMetric) {
+			lock.Lock()
+			defer lock.Unlock()
+			metrics = append(metrics, m)
+		})
+
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewBytesResponder(200, []byte(`{"access_token":"ABC123"}`)))
+	})
+
+	It("should template routes", func() {
+		Expect(routeTemplate("nodes")).To(Equal("nodes"))
+		Expect(routeTemplate("auth/namespaces")).To(Equal("auth/namespaces"))
+		Expect(routeTemplate("instances/a7b1c9e2")).To(Equal("instances/{uuid}"))
+		Expect(routeTemplate("instances/a7b1c9e2/metadata/owner")).To(
+			Equal("instances/{uuid}/metadata/{key}"))
+		Expect(routeTemplate("instances/a7b1c9e2/poweron")).To(
+			Equal("instances/{uuid}/poweron"))
+		Expect(routeTemplate("auth/namespaces/bob/keys/deploy")).To(
+			Equal("auth/namespaces/{namespace}/keys/{key_name}"))
+		Expect(routeTemplate("artifacts/a7b1c9e2/versions?x=1")).To(
+			Equal("artifacts/{uuid}/versions"))
+		Expect(routeTemplate("upload/a7b1c9e2")).To(Equal("upload/{uuid}"))
+		Expect(routeTemplate("upload/a7b1c9e2/truncate/1048576")).To(
+			Equal("upload/{uuid}/truncate/{offset}"))
+		Expect(routeTemplate("a/b/c/d/e/f")).To(Equal("other"))
+		Expect(routeTemplate("widgets/a7b1c9e2")).To(Equal("other"))
+	})
+
+	It("should observe every request with its route and status class", func() {
+		httpmock.RegisterResponder("GET",
+			test_url+"/instances/a7b1c9e2/metadata",
+			httpmock.NewStringResponder(200, `{"owner": "bob"}`))
+		httpmock.RegisterResponder("DELETE",
+			test_url+"/instances/a7b1c9e2/metadata/owner",
+			httpmock.NewStringResponder(404, `{"error": "not found"}`))
+
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithMetrics(collector))
+		Expect(err).To(BeNil())
+
+		_, err = client.GetInstanceMetadata("a7b1c9e2")
+		Expect(err).To(BeNil())
+		err = client.DeleteInstanceMetadata("a7b1c9e2", "owner")
+		Expect(IsNotFound(err)).To(BeTrue())
+
+		Expect(metrics).To(HaveLen(3))
+		Expect(metrics[0].Route).To(Equal("auth"))
+		Expect(metrics[0].Method).To(Equal("POST"))
+
+		Expect(metrics[1].Endpoint).To(Equal(test_url))
+		Expect(metrics[1].Method).To(Equal("GET"))
+		Expect(metrics[1].Route).To(Equal("instances/{uuid}/metadata"))
+		Expect(metrics[1].StatusClass).To(Equal("2xx"))
+		Expect(metrics[1].Retries).To(Equal(0))
+
+		Expect(metrics[2].Method).To(Equal("DELETE"))
+		Expect(metrics[2].Route).To(Equal("instances/{uuid}/metadata/{key}"))
+		Expect(metrics[2].StatusClass).To(Equal("4xx"))
+	})
+
+	It("should count retries", func() {
+		calls := 0
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					return httpmock.NewStringResponse(503, `{}`), nil
+				}
+				return httpmock.NewStringResponse(200, `[]`), nil
+			})
+
+		policy := DefaultRetryPolicy()
+		policy.InitialBackoff = time.Millisecond
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithMetrics(collector), WithRetryPolicy(policy))
+		Expect(err).To(BeNil())
+
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+
+		Expect(metrics).To(HaveLen(3))
+		Expect(metrics[1].StatusClass).To(Equal("5xx"))
+		Expect(metrics[1].Retries).To(Equal(0))
+		Expect(metrics[2].StatusClass).To(Equal("2xx"))
+		Expect(metrics[2].Retries).To(Equal(1))
+	})
+
+	It("should observe requests which receive no response", func() {
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			httpmock.NewErrorResponder(http.ErrHandlerTimeout))
+
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithMetrics(collector), WithRetryPolicy(nil))
+		Expect(err).To(BeNil())
+
+		_, err = client.GetNodes()
+		Expect(err).NotTo(BeNil())
+
+		Expect(metrics).To(HaveLen(2))
+		Expect(metrics[1].StatusClass).To(Equal("error"))
+	})
+
+	It("should serve metrics in Prometheus text format", func() {
+		p := NewPrometheusCollector()
+		p.ObserveRequest(RequestMetric{Endpoint: test_url, Method: "GET",
+			Route: "nodes", StatusClass: "2xx", Duration: 30 * time.Millisecond})
+		p.ObserveRequest(RequestMetric{Endpoint: test_url, Method: "GET",
+			Route: "nodes", StatusClass: "2xx", Duration: 3 * time.Second})
+
+		server := httptest.NewServer(p)
+		defer server.Close()
+
+		hc := &http.Client{Transport: &http.Transport{}}
+		resp, err := hc.Get(server.URL)
+		Expect(err).To(BeNil())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/plain"))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).To(BeNil())
+
+		labels := `endpoint="http://server:13000",method="GET",route="nodes",` +
+			`status_class="2xx",retries="0"`
+		Expect(string(body)).To(ContainSubstring(
+			"# TYPE shakenfist_client_requests_total counter\n"))
+		Expect(string(body)).To(ContainSubstring(
+			"shakenfist_client_requests_total{" + labels + "} 2\n"))
+		Expect(string(body)).To(ContainSubstring(
+			"# TYPE shakenfist_client_request_duration_seconds histogram\n"))
+		Expect(string(body)).To(ContainSubstring(
+			"shakenfist_client_request_duration_seconds_bucket{" + labels +
+				`,le="0.025"} 0` + "\n"))
+		Expect(string(body)).To(ContainSubstring(
+			"shakenfist_client_request_duration_seconds_bucket{" + labels +
+				`,le="0.05"} 1` + "\n"))
+		Expect(string(body)).To(ContainSubstring(
+			"shakenfist_client_request_duration_seconds_bucket{" + labels +
+				`,le="+Inf"} 2` + "\n"))
+		Expect(string(body)).To(ContainSubstring(
+			"shakenfist_client_request_duration_seconds_sum{" + labels +
+				"} 3.03\n"))
+		Expect(string(body)).To(ContainSubstring(
+			"shakenfist_client_request_duration_seconds_count{" + labels +
+				"} 2\n"))
+	})
+})
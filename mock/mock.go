@@ -0,0 +1,2995 @@
+// Code generated by internal/mockgen from api.go. DO NOT EDIT.
+
+package mock
+
+import (
+	"context"
+	"io"
+
+	client "github.com/shakenfist/client-go"
+)
+
+// Instances is a fake client.Instances. Each method calls the field of the
+// same name with Func appended, or returns zero values if it is nil.
+type Instances struct {
+	Recorder
+
+	GetInstancesFunc              func() ([]client.Instance, error)
+	GetInstancesCtxFunc           func(context.Context) ([]client.Instance, error)
+	GetInstanceFunc               func(string) (client.Instance, error)
+	GetInstanceCtxFunc            func(context.Context, string) (client.Instance, error)
+	CreateInstanceFunc            func(string, int, int, []client.NetworkSpec, []client.DiskSpec, client.VideoSpec, string, string, string, string, bool, bool, string) (client.Instance, error)
+	CreateInstanceCtxFunc         func(context.Context, string, int, int, []client.NetworkSpec, []client.DiskSpec, client.VideoSpec, string, string, string, string, bool, bool, string) (client.Instance, error)
+	CreateInstanceFromSpecFunc    func(client.InstanceSpec) (client.Instance, error)
+	CreateInstanceFromSpecCtxFunc func(context.Context, client.InstanceSpec) (client.Instance, error)
+	SnapshotInstanceFunc          func(string, bool, string) error
+	SnapshotInstanceCtxFunc       func(context.Context, string, bool, string) error
+	GetInstanceSnapshotsFunc      func(string) ([]client.Snapshot, error)
+	GetInstanceSnapshotsCtxFunc   func(context.Context, string) ([]client.Snapshot, error)
+	RebootInstanceFunc            func(string) error
+	RebootInstanceCtxFunc         func(context.Context, string) error
+	PowerOffInstanceFunc          func(string) error
+	PowerOffInstanceCtxFunc       func(context.Context, string) error
+	PowerOnInstanceFunc           func(string) error
+	PowerOnInstanceCtxFunc        func(context.Context, string) error
+	PauseInstanceFunc             func(string) error
+	PauseInstanceCtxFunc          func(context.Context, string) error
+	UnPauseInstanceFunc           func(string) error
+	UnPauseInstanceCtxFunc        func(context.Context, string) error
+	DeleteInstanceFunc            func(string, string) error
+	DeleteInstanceCtxFunc         func(context.Context, string, string) error
+	DeleteAllInstancesFunc        func(string) ([]string, error)
+	DeleteAllInstancesCtxFunc     func(context.Context, string) ([]string, error)
+	GetInstanceEventsFunc         func(string) ([]client.Event, error)
+	GetInstanceEventsCtxFunc      func(context.Context, string) ([]client.Event, error)
+	GetConsoleDataFunc            func(string, int) (string, error)
+	GetConsoleDataCtxFunc         func(context.Context, string, int) (string, error)
+	WaitForInstanceStateFunc      func(context.Context, string, []client.State, []client.State) (client.Instance, error)
+	StreamConsoleFunc             func(context.Context, string) io.ReadCloser
+}
+
+var _ client.Instances = (*Instances)(nil)
+
+// GetInstances implements client.Instances.
+func (m *Instances) GetInstances() ([]client.Instance, error) {
+	m.record("GetInstances")
+	if m.GetInstancesFunc != nil {
+		return m.GetInstancesFunc()
+	}
+	var r0 []client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// GetInstancesCtx implements client.Instances.
+func (m *Instances) GetInstancesCtx(ctx context.Context) ([]client.Instance, error) {
+	m.record("GetInstancesCtx", ctx)
+	if m.GetInstancesCtxFunc != nil {
+		return m.GetInstancesCtxFunc(ctx)
+	}
+	var r0 []client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// GetInstance implements client.Instances.
+func (m *Instances) GetInstance(uuid string) (client.Instance, error) {
+	m.record("GetInstance", uuid)
+	if m.GetInstanceFunc != nil {
+		return m.GetInstanceFunc(uuid)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceCtx implements client.Instances.
+func (m *Instances) GetInstanceCtx(ctx context.Context, uuid string) (client.Instance, error) {
+	m.record("GetInstanceCtx", ctx, uuid)
+	if m.GetInstanceCtxFunc != nil {
+		return m.GetInstanceCtxFunc(ctx, uuid)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// CreateInstance implements client.Instances.
+func (m *Instances) CreateInstance(name string, cpus int, memory int, networks []client.NetworkSpec, disks []client.DiskSpec, video client.VideoSpec, sshKey string, userData string, nameSpace string, metadata string, secureBoot bool, uefi bool, nvramTemplate string) (client.Instance, error) {
+	m.record("CreateInstance", name, cpus, memory, networks, disks, video, sshKey, userData, nameSpace, metadata, secureBoot, uefi, nvramTemplate)
+	if m.CreateInstanceFunc != nil {
+		return m.CreateInstanceFunc(name, cpus, memory, networks, disks, video, sshKey, userData, nameSpace, metadata, secureBoot, uefi, nvramTemplate)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// CreateInstanceCtx implements client.Instances.
+func (m *Instances) CreateInstanceCtx(ctx context.Context, name string, cpus int, memory int, networks []client.NetworkSpec, disks []client.DiskSpec, video client.VideoSpec, sshKey string, userData string, nameSpace string, metadata string, secureBoot bool, uefi bool, nvramTemplate string) (client.Instance, error) {
+	m.record("CreateInstanceCtx", ctx, name, cpus, memory, networks, disks, video, sshKey, userData, nameSpace, metadata, secureBoot, uefi, nvramTemplate)
+	if m.CreateInstanceCtxFunc != nil {
+		return m.CreateInstanceCtxFunc(ctx, name, cpus, memory, networks, disks, video, sshKey, userData, nameSpace, metadata, secureBoot, uefi, nvramTemplate)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// CreateInstanceFromSpec implements client.Instances.
+func (m *Instances) CreateInstanceFromSpec(spec client.InstanceSpec) (client.Instance, error) {
+	m.record("CreateInstanceFromSpec", spec)
+	if m.CreateInstanceFromSpecFunc != nil {
+		return m.CreateInstanceFromSpecFunc(spec)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// CreateInstanceFromSpecCtx implements client.Instances.
+func (m *Instances) CreateInstanceFromSpecCtx(ctx context.Context, spec client.InstanceSpec) (client.Instance, error) {
+	m.record("CreateInstanceFromSpecCtx", ctx, spec)
+	if m.CreateInstanceFromSpecCtxFunc != nil {
+		return m.CreateInstanceFromSpecCtxFunc(ctx, spec)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// SnapshotInstance implements client.Instances.
+func (m *Instances) SnapshotInstance(uuid string, all bool, device string) error {
+	m.record("SnapshotInstance", uuid, all, device)
+	if m.SnapshotInstanceFunc != nil {
+		return m.SnapshotInstanceFunc(uuid, all, device)
+	}
+	return nil
+}
+
+// SnapshotInstanceCtx implements client.Instances.
+func (m *Instances) SnapshotInstanceCtx(ctx context.Context, uuid string, all bool, device string) error {
+	m.record("SnapshotInstanceCtx", ctx, uuid, all, device)
+	if m.SnapshotInstanceCtxFunc != nil {
+		return m.SnapshotInstanceCtxFunc(ctx, uuid, all, device)
+	}
+	return nil
+}
+
+// GetInstanceSnapshots implements client.Instances.
+func (m *Instances) GetInstanceSnapshots(uuid string) ([]client.Snapshot, error) {
+	m.record("GetInstanceSnapshots", uuid)
+	if m.GetInstanceSnapshotsFunc != nil {
+		return m.GetInstanceSnapshotsFunc(uuid)
+	}
+	var r0 []client.Snapshot
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceSnapshotsCtx implements client.Instances.
+func (m *Instances) GetInstanceSnapshotsCtx(ctx context.Context, uuid string) ([]client.Snapshot, error) {
+	m.record("GetInstanceSnapshotsCtx", ctx, uuid)
+	if m.GetInstanceSnapshotsCtxFunc != nil {
+		return m.GetInstanceSnapshotsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Snapshot
+	var r1 error
+	return r0, r1
+}
+
+// RebootInstance implements client.Instances.
+func (m *Instances) RebootInstance(uuid string) error {
+	m.record("RebootInstance", uuid)
+	if m.RebootInstanceFunc != nil {
+		return m.RebootInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// RebootInstanceCtx implements client.Instances.
+func (m *Instances) RebootInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("RebootInstanceCtx", ctx, uuid)
+	if m.RebootInstanceCtxFunc != nil {
+		return m.RebootInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// PowerOffInstance implements client.Instances.
+func (m *Instances) PowerOffInstance(uuid string) error {
+	m.record("PowerOffInstance", uuid)
+	if m.PowerOffInstanceFunc != nil {
+		return m.PowerOffInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// PowerOffInstanceCtx implements client.Instances.
+func (m *Instances) PowerOffInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("PowerOffInstanceCtx", ctx, uuid)
+	if m.PowerOffInstanceCtxFunc != nil {
+		return m.PowerOffInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// PowerOnInstance implements client.Instances.
+func (m *Instances) PowerOnInstance(uuid string) error {
+	m.record("PowerOnInstance", uuid)
+	if m.PowerOnInstanceFunc != nil {
+		return m.PowerOnInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// PowerOnInstanceCtx implements client.Instances.
+func (m *Instances) PowerOnInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("PowerOnInstanceCtx", ctx, uuid)
+	if m.PowerOnInstanceCtxFunc != nil {
+		return m.PowerOnInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// PauseInstance implements client.Instances.
+func (m *Instances) PauseInstance(uuid string) error {
+	m.record("PauseInstance", uuid)
+	if m.PauseInstanceFunc != nil {
+		return m.PauseInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// PauseInstanceCtx implements client.Instances.
+func (m *Instances) PauseInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("PauseInstanceCtx", ctx, uuid)
+	if m.PauseInstanceCtxFunc != nil {
+		return m.PauseInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// UnPauseInstance implements client.Instances.
+func (m *Instances) UnPauseInstance(uuid string) error {
+	m.record("UnPauseInstance", uuid)
+	if m.UnPauseInstanceFunc != nil {
+		return m.UnPauseInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// UnPauseInstanceCtx implements client.Instances.
+func (m *Instances) UnPauseInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("UnPauseInstanceCtx", ctx, uuid)
+	if m.UnPauseInstanceCtxFunc != nil {
+		return m.UnPauseInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// DeleteInstance implements client.Instances.
+func (m *Instances) DeleteInstance(uuid string, namespace string) error {
+	m.record("DeleteInstance", uuid, namespace)
+	if m.DeleteInstanceFunc != nil {
+		return m.DeleteInstanceFunc(uuid, namespace)
+	}
+	return nil
+}
+
+// DeleteInstanceCtx implements client.Instances.
+func (m *Instances) DeleteInstanceCtx(ctx context.Context, uuid string, namespace string) error {
+	m.record("DeleteInstanceCtx", ctx, uuid, namespace)
+	if m.DeleteInstanceCtxFunc != nil {
+		return m.DeleteInstanceCtxFunc(ctx, uuid, namespace)
+	}
+	return nil
+}
+
+// DeleteAllInstances implements client.Instances.
+func (m *Instances) DeleteAllInstances(namespace string) ([]string, error) {
+	m.record("DeleteAllInstances", namespace)
+	if m.DeleteAllInstancesFunc != nil {
+		return m.DeleteAllInstancesFunc(namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// DeleteAllInstancesCtx implements client.Instances.
+func (m *Instances) DeleteAllInstancesCtx(ctx context.Context, namespace string) ([]string, error) {
+	m.record("DeleteAllInstancesCtx", ctx, namespace)
+	if m.DeleteAllInstancesCtxFunc != nil {
+		return m.DeleteAllInstancesCtxFunc(ctx, namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceEvents implements client.Instances.
+func (m *Instances) GetInstanceEvents(uuid string) ([]client.Event, error) {
+	m.record("GetInstanceEvents", uuid)
+	if m.GetInstanceEventsFunc != nil {
+		return m.GetInstanceEventsFunc(uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceEventsCtx implements client.Instances.
+func (m *Instances) GetInstanceEventsCtx(ctx context.Context, uuid string) ([]client.Event, error) {
+	m.record("GetInstanceEventsCtx", ctx, uuid)
+	if m.GetInstanceEventsCtxFunc != nil {
+		return m.GetInstanceEventsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetConsoleData implements client.Instances.
+func (m *Instances) GetConsoleData(uuid string, n int) (string, error) {
+	m.record("GetConsoleData", uuid, n)
+	if m.GetConsoleDataFunc != nil {
+		return m.GetConsoleDataFunc(uuid, n)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetConsoleDataCtx implements client.Instances.
+func (m *Instances) GetConsoleDataCtx(ctx context.Context, uuid string, n int) (string, error) {
+	m.record("GetConsoleDataCtx", ctx, uuid, n)
+	if m.GetConsoleDataCtxFunc != nil {
+		return m.GetConsoleDataCtxFunc(ctx, uuid, n)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// WaitForInstanceState implements client.Instances.
+func (m *Instances) WaitForInstanceState(ctx context.Context, uuid string, targets []client.State, failures []client.State) (client.Instance, error) {
+	m.record("WaitForInstanceState", ctx, uuid, targets, failures)
+	if m.WaitForInstanceStateFunc != nil {
+		return m.WaitForInstanceStateFunc(ctx, uuid, targets, failures)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// StreamConsole implements client.Instances.
+func (m *Instances) StreamConsole(ctx context.Context, uuid string) io.ReadCloser {
+	m.record("StreamConsole", ctx, uuid)
+	if m.StreamConsoleFunc != nil {
+		return m.StreamConsoleFunc(ctx, uuid)
+	}
+	var r0 io.ReadCloser
+	return r0
+}
+
+// Networks is a fake client.Networks. Each method calls the field of the
+// same name with Func appended, or returns zero values if it is nil.
+type Networks struct {
+	Recorder
+
+	GetNetworksFunc              func() ([]client.Network, error)
+	GetNetworksCtxFunc           func(context.Context) ([]client.Network, error)
+	GetNetworkFunc               func(string) (client.Network, error)
+	GetNetworkCtxFunc            func(context.Context, string) (client.Network, error)
+	CreateNetworkFunc            func(string, bool, bool, string) (client.Network, error)
+	CreateNetworkCtxFunc         func(context.Context, string, bool, bool, string) (client.Network, error)
+	DeleteNetworkFunc            func(string) error
+	DeleteNetworkCtxFunc         func(context.Context, string) error
+	DeleteAllNetworksFunc        func(string) ([]string, error)
+	DeleteAllNetworksCtxFunc     func(context.Context, string) ([]string, error)
+	GetNetworkEventsFunc         func(string) ([]client.Event, error)
+	GetNetworkEventsCtxFunc      func(context.Context, string) ([]client.Event, error)
+	GetInstanceInterfacesFunc    func(string) ([]client.NetworkInterface, error)
+	GetInstanceInterfacesCtxFunc func(context.Context, string) ([]client.NetworkInterface, error)
+	GetNetworkInterfacesFunc     func(string) ([]client.NetworkInterface, error)
+	GetNetworkInterfacesCtxFunc  func(context.Context, string) ([]client.NetworkInterface, error)
+	GetInterfaceFunc             func(string) (client.NetworkInterface, error)
+	GetInterfaceCtxFunc          func(context.Context, string) (client.NetworkInterface, error)
+	FloatInterfaceFunc           func(string) error
+	FloatInterfaceCtxFunc        func(context.Context, string) error
+	DefloatInterfaceFunc         func(string) error
+	DefloatInterfaceCtxFunc      func(context.Context, string) error
+	WaitForNetworkStateFunc      func(context.Context, string, []client.State, []client.State) (client.Network, error)
+}
+
+var _ client.Networks = (*Networks)(nil)
+
+// GetNetworks implements client.Networks.
+func (m *Networks) GetNetworks() ([]client.Network, error) {
+	m.record("GetNetworks")
+	if m.GetNetworksFunc != nil {
+		return m.GetNetworksFunc()
+	}
+	var r0 []client.Network
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworksCtx implements client.Networks.
+func (m *Networks) GetNetworksCtx(ctx context.Context) ([]client.Network, error) {
+	m.record("GetNetworksCtx", ctx)
+	if m.GetNetworksCtxFunc != nil {
+		return m.GetNetworksCtxFunc(ctx)
+	}
+	var r0 []client.Network
+	var r1 error
+	return r0, r1
+}
+
+// GetNetwork implements client.Networks.
+func (m *Networks) GetNetwork(uuid string) (client.Network, error) {
+	m.record("GetNetwork", uuid)
+	if m.GetNetworkFunc != nil {
+		return m.GetNetworkFunc(uuid)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkCtx implements client.Networks.
+func (m *Networks) GetNetworkCtx(ctx context.Context, uuid string) (client.Network, error) {
+	m.record("GetNetworkCtx", ctx, uuid)
+	if m.GetNetworkCtxFunc != nil {
+		return m.GetNetworkCtxFunc(ctx, uuid)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// CreateNetwork implements client.Networks.
+func (m *Networks) CreateNetwork(netblock string, provideDHCP bool, provideNAT bool, name string) (client.Network, error) {
+	m.record("CreateNetwork", netblock, provideDHCP, provideNAT, name)
+	if m.CreateNetworkFunc != nil {
+		return m.CreateNetworkFunc(netblock, provideDHCP, provideNAT, name)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// CreateNetworkCtx implements client.Networks.
+func (m *Networks) CreateNetworkCtx(ctx context.Context, netblock string, provideDHCP bool, provideNAT bool, name string) (client.Network, error) {
+	m.record("CreateNetworkCtx", ctx, netblock, provideDHCP, provideNAT, name)
+	if m.CreateNetworkCtxFunc != nil {
+		return m.CreateNetworkCtxFunc(ctx, netblock, provideDHCP, provideNAT, name)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// DeleteNetwork implements client.Networks.
+func (m *Networks) DeleteNetwork(uuid string) error {
+	m.record("DeleteNetwork", uuid)
+	if m.DeleteNetworkFunc != nil {
+		return m.DeleteNetworkFunc(uuid)
+	}
+	return nil
+}
+
+// DeleteNetworkCtx implements client.Networks.
+func (m *Networks) DeleteNetworkCtx(ctx context.Context, uuid string) error {
+	m.record("DeleteNetworkCtx", ctx, uuid)
+	if m.DeleteNetworkCtxFunc != nil {
+		return m.DeleteNetworkCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// DeleteAllNetworks implements client.Networks.
+func (m *Networks) DeleteAllNetworks(namespace string) ([]string, error) {
+	m.record("DeleteAllNetworks", namespace)
+	if m.DeleteAllNetworksFunc != nil {
+		return m.DeleteAllNetworksFunc(namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// DeleteAllNetworksCtx implements client.Networks.
+func (m *Networks) DeleteAllNetworksCtx(ctx context.Context, namespace string) ([]string, error) {
+	m.record("DeleteAllNetworksCtx", ctx, namespace)
+	if m.DeleteAllNetworksCtxFunc != nil {
+		return m.DeleteAllNetworksCtxFunc(ctx, namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkEvents implements client.Networks.
+func (m *Networks) GetNetworkEvents(uuid string) ([]client.Event, error) {
+	m.record("GetNetworkEvents", uuid)
+	if m.GetNetworkEventsFunc != nil {
+		return m.GetNetworkEventsFunc(uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkEventsCtx implements client.Networks.
+func (m *Networks) GetNetworkEventsCtx(ctx context.Context, uuid string) ([]client.Event, error) {
+	m.record("GetNetworkEventsCtx", ctx, uuid)
+	if m.GetNetworkEventsCtxFunc != nil {
+		return m.GetNetworkEventsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceInterfaces implements client.Networks.
+func (m *Networks) GetInstanceInterfaces(uuid string) ([]client.NetworkInterface, error) {
+	m.record("GetInstanceInterfaces", uuid)
+	if m.GetInstanceInterfacesFunc != nil {
+		return m.GetInstanceInterfacesFunc(uuid)
+	}
+	var r0 []client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceInterfacesCtx implements client.Networks.
+func (m *Networks) GetInstanceInterfacesCtx(ctx context.Context, uuid string) ([]client.NetworkInterface, error) {
+	m.record("GetInstanceInterfacesCtx", ctx, uuid)
+	if m.GetInstanceInterfacesCtxFunc != nil {
+		return m.GetInstanceInterfacesCtxFunc(ctx, uuid)
+	}
+	var r0 []client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkInterfaces implements client.Networks.
+func (m *Networks) GetNetworkInterfaces(uuid string) ([]client.NetworkInterface, error) {
+	m.record("GetNetworkInterfaces", uuid)
+	if m.GetNetworkInterfacesFunc != nil {
+		return m.GetNetworkInterfacesFunc(uuid)
+	}
+	var r0 []client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkInterfacesCtx implements client.Networks.
+func (m *Networks) GetNetworkInterfacesCtx(ctx context.Context, uuid string) ([]client.NetworkInterface, error) {
+	m.record("GetNetworkInterfacesCtx", ctx, uuid)
+	if m.GetNetworkInterfacesCtxFunc != nil {
+		return m.GetNetworkInterfacesCtxFunc(ctx, uuid)
+	}
+	var r0 []client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetInterface implements client.Networks.
+func (m *Networks) GetInterface(uuid string) (client.NetworkInterface, error) {
+	m.record("GetInterface", uuid)
+	if m.GetInterfaceFunc != nil {
+		return m.GetInterfaceFunc(uuid)
+	}
+	var r0 client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetInterfaceCtx implements client.Networks.
+func (m *Networks) GetInterfaceCtx(ctx context.Context, uuid string) (client.NetworkInterface, error) {
+	m.record("GetInterfaceCtx", ctx, uuid)
+	if m.GetInterfaceCtxFunc != nil {
+		return m.GetInterfaceCtxFunc(ctx, uuid)
+	}
+	var r0 client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// FloatInterface implements client.Networks.
+func (m *Networks) FloatInterface(interfaceUUID string) error {
+	m.record("FloatInterface", interfaceUUID)
+	if m.FloatInterfaceFunc != nil {
+		return m.FloatInterfaceFunc(interfaceUUID)
+	}
+	return nil
+}
+
+// FloatInterfaceCtx implements client.Networks.
+func (m *Networks) FloatInterfaceCtx(ctx context.Context, interfaceUUID string) error {
+	m.record("FloatInterfaceCtx", ctx, interfaceUUID)
+	if m.FloatInterfaceCtxFunc != nil {
+		return m.FloatInterfaceCtxFunc(ctx, interfaceUUID)
+	}
+	return nil
+}
+
+// DefloatInterface implements client.Networks.
+func (m *Networks) DefloatInterface(interfaceUUID string) error {
+	m.record("DefloatInterface", interfaceUUID)
+	if m.DefloatInterfaceFunc != nil {
+		return m.DefloatInterfaceFunc(interfaceUUID)
+	}
+	return nil
+}
+
+// DefloatInterfaceCtx implements client.Networks.
+func (m *Networks) DefloatInterfaceCtx(ctx context.Context, interfaceUUID string) error {
+	m.record("DefloatInterfaceCtx", ctx, interfaceUUID)
+	if m.DefloatInterfaceCtxFunc != nil {
+		return m.DefloatInterfaceCtxFunc(ctx, interfaceUUID)
+	}
+	return nil
+}
+
+// WaitForNetworkState implements client.Networks.
+func (m *Networks) WaitForNetworkState(ctx context.Context, uuid string, targets []client.State, failures []client.State) (client.Network, error) {
+	m.record("WaitForNetworkState", ctx, uuid, targets, failures)
+	if m.WaitForNetworkStateFunc != nil {
+		return m.WaitForNetworkStateFunc(ctx, uuid, targets, failures)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// Namespaces is a fake client.Namespaces. Each method calls the field of the
+// same name with Func appended, or returns zero values if it is nil.
+type Namespaces struct {
+	Recorder
+
+	GetNamespacesFunc         func() ([]string, error)
+	GetNamespacesCtxFunc      func(context.Context) ([]string, error)
+	CreateNamespaceFunc       func(string) error
+	CreateNamespaceCtxFunc    func(context.Context, string) error
+	DeleteNamespaceFunc       func(string) error
+	DeleteNamespaceCtxFunc    func(context.Context, string) error
+	GetNamespaceKeysFunc      func(string) ([]string, error)
+	GetNamespaceKeysCtxFunc   func(context.Context, string) ([]string, error)
+	CreateNamespaceKeyFunc    func(string, string, string) error
+	CreateNamespaceKeyCtxFunc func(context.Context, string, string, string) error
+	UpdateNamespaceKeyFunc    func(string, string, string) error
+	UpdateNamespaceKeyCtxFunc func(context.Context, string, string, string) error
+	DeleteNamespaceKeyFunc    func(string, string) error
+	DeleteNamespaceKeyCtxFunc func(context.Context, string, string) error
+}
+
+var _ client.Namespaces = (*Namespaces)(nil)
+
+// GetNamespaces implements client.Namespaces.
+func (m *Namespaces) GetNamespaces() ([]string, error) {
+	m.record("GetNamespaces")
+	if m.GetNamespacesFunc != nil {
+		return m.GetNamespacesFunc()
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetNamespacesCtx implements client.Namespaces.
+func (m *Namespaces) GetNamespacesCtx(ctx context.Context) ([]string, error) {
+	m.record("GetNamespacesCtx", ctx)
+	if m.GetNamespacesCtxFunc != nil {
+		return m.GetNamespacesCtxFunc(ctx)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// CreateNamespace implements client.Namespaces.
+func (m *Namespaces) CreateNamespace(namespace string) error {
+	m.record("CreateNamespace", namespace)
+	if m.CreateNamespaceFunc != nil {
+		return m.CreateNamespaceFunc(namespace)
+	}
+	return nil
+}
+
+// CreateNamespaceCtx implements client.Namespaces.
+func (m *Namespaces) CreateNamespaceCtx(ctx context.Context, namespace string) error {
+	m.record("CreateNamespaceCtx", ctx, namespace)
+	if m.CreateNamespaceCtxFunc != nil {
+		return m.CreateNamespaceCtxFunc(ctx, namespace)
+	}
+	return nil
+}
+
+// DeleteNamespace implements client.Namespaces.
+func (m *Namespaces) DeleteNamespace(namespace string) error {
+	m.record("DeleteNamespace", namespace)
+	if m.DeleteNamespaceFunc != nil {
+		return m.DeleteNamespaceFunc(namespace)
+	}
+	return nil
+}
+
+// DeleteNamespaceCtx implements client.Namespaces.
+func (m *Namespaces) DeleteNamespaceCtx(ctx context.Context, namespace string) error {
+	m.record("DeleteNamespaceCtx", ctx, namespace)
+	if m.DeleteNamespaceCtxFunc != nil {
+		return m.DeleteNamespaceCtxFunc(ctx, namespace)
+	}
+	return nil
+}
+
+// GetNamespaceKeys implements client.Namespaces.
+func (m *Namespaces) GetNamespaceKeys(namespace string) ([]string, error) {
+	m.record("GetNamespaceKeys", namespace)
+	if m.GetNamespaceKeysFunc != nil {
+		return m.GetNamespaceKeysFunc(namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetNamespaceKeysCtx implements client.Namespaces.
+func (m *Namespaces) GetNamespaceKeysCtx(ctx context.Context, namespace string) ([]string, error) {
+	m.record("GetNamespaceKeysCtx", ctx, namespace)
+	if m.GetNamespaceKeysCtxFunc != nil {
+		return m.GetNamespaceKeysCtxFunc(ctx, namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// CreateNamespaceKey implements client.Namespaces.
+func (m *Namespaces) CreateNamespaceKey(namespace string, keyName string, key string) error {
+	m.record("CreateNamespaceKey", namespace, keyName, key)
+	if m.CreateNamespaceKeyFunc != nil {
+		return m.CreateNamespaceKeyFunc(namespace, keyName, key)
+	}
+	return nil
+}
+
+// CreateNamespaceKeyCtx implements client.Namespaces.
+func (m *Namespaces) CreateNamespaceKeyCtx(ctx context.Context, namespace string, keyName string, key string) error {
+	m.record("CreateNamespaceKeyCtx", ctx, namespace, keyName, key)
+	if m.CreateNamespaceKeyCtxFunc != nil {
+		return m.CreateNamespaceKeyCtxFunc(ctx, namespace, keyName, key)
+	}
+	return nil
+}
+
+// UpdateNamespaceKey implements client.Namespaces.
+func (m *Namespaces) UpdateNamespaceKey(namespace string, keyName string, key string) error {
+	m.record("UpdateNamespaceKey", namespace, keyName, key)
+	if m.UpdateNamespaceKeyFunc != nil {
+		return m.UpdateNamespaceKeyFunc(namespace, keyName, key)
+	}
+	return nil
+}
+
+// UpdateNamespaceKeyCtx implements client.Namespaces.
+func (m *Namespaces) UpdateNamespaceKeyCtx(ctx context.Context, namespace string, keyName string, key string) error {
+	m.record("UpdateNamespaceKeyCtx", ctx, namespace, keyName, key)
+	if m.UpdateNamespaceKeyCtxFunc != nil {
+		return m.UpdateNamespaceKeyCtxFunc(ctx, namespace, keyName, key)
+	}
+	return nil
+}
+
+// DeleteNamespaceKey implements client.Namespaces.
+func (m *Namespaces) DeleteNamespaceKey(namespace string, keyName string) error {
+	m.record("DeleteNamespaceKey", namespace, keyName)
+	if m.DeleteNamespaceKeyFunc != nil {
+		return m.DeleteNamespaceKeyFunc(namespace, keyName)
+	}
+	return nil
+}
+
+// DeleteNamespaceKeyCtx implements client.Namespaces.
+func (m *Namespaces) DeleteNamespaceKeyCtx(ctx context.Context, namespace string, keyName string) error {
+	m.record("DeleteNamespaceKeyCtx", ctx, namespace, keyName)
+	if m.DeleteNamespaceKeyCtxFunc != nil {
+		return m.DeleteNamespaceKeyCtxFunc(ctx, namespace, keyName)
+	}
+	return nil
+}
+
+// MetadataStore is a fake client.MetadataStore. Each method calls the field of the
+// same name with Func appended, or returns zero values if it is nil.
+type MetadataStore struct {
+	Recorder
+
+	GetMetadataFunc                   func(client.ResourceType, string) (client.Metadata, error)
+	GetMetadataCtxFunc                func(context.Context, client.ResourceType, string) (client.Metadata, error)
+	SetMetadataFunc                   func(client.ResourceType, string, string, string) error
+	SetMetadataCtxFunc                func(context.Context, client.ResourceType, string, string, string) error
+	DeleteMetadataFunc                func(client.ResourceType, string, string) error
+	DeleteMetadataCtxFunc             func(context.Context, client.ResourceType, string, string) error
+	GetInstanceMetadataFunc           func(string) (client.Metadata, error)
+	GetInstanceMetadataCtxFunc        func(context.Context, string) (client.Metadata, error)
+	SetInstanceMetadataFunc           func(string, string, string) error
+	SetInstanceMetadataCtxFunc        func(context.Context, string, string, string) error
+	DeleteInstanceMetadataFunc        func(string, string) error
+	DeleteInstanceMetadataCtxFunc     func(context.Context, string, string) error
+	SetInstanceMetadataItemFunc       func(string, string, string) error
+	SetInstanceMetadataItemCtxFunc    func(context.Context, string, string, string) error
+	DeleteInstanceMetadataItemFunc    func(string, string) error
+	DeleteInstanceMetadataItemCtxFunc func(context.Context, string, string) error
+	GetNetworkMetadataFunc            func(string) (client.Metadata, error)
+	GetNetworkMetadataCtxFunc         func(context.Context, string) (client.Metadata, error)
+	SetNetworkMetadataFunc            func(string, string, string) error
+	SetNetworkMetadataCtxFunc         func(context.Context, string, string, string) error
+	DeleteNetworkMetadataFunc         func(string, string) error
+	DeleteNetworkMetadataCtxFunc      func(context.Context, string, string) error
+	GetNamespaceMetadataFunc          func(string) (client.Metadata, error)
+	GetNamespaceMetadataCtxFunc       func(context.Context, string) (client.Metadata, error)
+	SetNamespaceMetadataFunc          func(string, string, string) error
+	SetNamespaceMetadataCtxFunc       func(context.Context, string, string, string) error
+	DeleteNamespaceMetadataFunc       func(string, string) error
+	DeleteNamespaceMetadataCtxFunc    func(context.Context, string, string) error
+}
+
+var _ client.MetadataStore = (*MetadataStore)(nil)
+
+// GetMetadata implements client.MetadataStore.
+func (m *MetadataStore) GetMetadata(res client.ResourceType, uuid string) (client.Metadata, error) {
+	m.record("GetMetadata", res, uuid)
+	if m.GetMetadataFunc != nil {
+		return m.GetMetadataFunc(res, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// GetMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) GetMetadataCtx(ctx context.Context, res client.ResourceType, uuid string) (client.Metadata, error) {
+	m.record("GetMetadataCtx", ctx, res, uuid)
+	if m.GetMetadataCtxFunc != nil {
+		return m.GetMetadataCtxFunc(ctx, res, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// SetMetadata implements client.MetadataStore.
+func (m *MetadataStore) SetMetadata(res client.ResourceType, uuid string, key string, value string) error {
+	m.record("SetMetadata", res, uuid, key, value)
+	if m.SetMetadataFunc != nil {
+		return m.SetMetadataFunc(res, uuid, key, value)
+	}
+	return nil
+}
+
+// SetMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) SetMetadataCtx(ctx context.Context, res client.ResourceType, uuid string, key string, value string) error {
+	m.record("SetMetadataCtx", ctx, res, uuid, key, value)
+	if m.SetMetadataCtxFunc != nil {
+		return m.SetMetadataCtxFunc(ctx, res, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteMetadata implements client.MetadataStore.
+func (m *MetadataStore) DeleteMetadata(res client.ResourceType, uuid string, key string) error {
+	m.record("DeleteMetadata", res, uuid, key)
+	if m.DeleteMetadataFunc != nil {
+		return m.DeleteMetadataFunc(res, uuid, key)
+	}
+	return nil
+}
+
+// DeleteMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) DeleteMetadataCtx(ctx context.Context, res client.ResourceType, uuid string, key string) error {
+	m.record("DeleteMetadataCtx", ctx, res, uuid, key)
+	if m.DeleteMetadataCtxFunc != nil {
+		return m.DeleteMetadataCtxFunc(ctx, res, uuid, key)
+	}
+	return nil
+}
+
+// GetInstanceMetadata implements client.MetadataStore.
+func (m *MetadataStore) GetInstanceMetadata(uuid string) (client.Metadata, error) {
+	m.record("GetInstanceMetadata", uuid)
+	if m.GetInstanceMetadataFunc != nil {
+		return m.GetInstanceMetadataFunc(uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) GetInstanceMetadataCtx(ctx context.Context, uuid string) (client.Metadata, error) {
+	m.record("GetInstanceMetadataCtx", ctx, uuid)
+	if m.GetInstanceMetadataCtxFunc != nil {
+		return m.GetInstanceMetadataCtxFunc(ctx, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// SetInstanceMetadata implements client.MetadataStore.
+func (m *MetadataStore) SetInstanceMetadata(uuid string, key string, value string) error {
+	m.record("SetInstanceMetadata", uuid, key, value)
+	if m.SetInstanceMetadataFunc != nil {
+		return m.SetInstanceMetadataFunc(uuid, key, value)
+	}
+	return nil
+}
+
+// SetInstanceMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) SetInstanceMetadataCtx(ctx context.Context, uuid string, key string, value string) error {
+	m.record("SetInstanceMetadataCtx", ctx, uuid, key, value)
+	if m.SetInstanceMetadataCtxFunc != nil {
+		return m.SetInstanceMetadataCtxFunc(ctx, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteInstanceMetadata implements client.MetadataStore.
+func (m *MetadataStore) DeleteInstanceMetadata(uuid string, key string) error {
+	m.record("DeleteInstanceMetadata", uuid, key)
+	if m.DeleteInstanceMetadataFunc != nil {
+		return m.DeleteInstanceMetadataFunc(uuid, key)
+	}
+	return nil
+}
+
+// DeleteInstanceMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) DeleteInstanceMetadataCtx(ctx context.Context, uuid string, key string) error {
+	m.record("DeleteInstanceMetadataCtx", ctx, uuid, key)
+	if m.DeleteInstanceMetadataCtxFunc != nil {
+		return m.DeleteInstanceMetadataCtxFunc(ctx, uuid, key)
+	}
+	return nil
+}
+
+// SetInstanceMetadataItem implements client.MetadataStore.
+func (m *MetadataStore) SetInstanceMetadataItem(uuid string, key string, value string) error {
+	m.record("SetInstanceMetadataItem", uuid, key, value)
+	if m.SetInstanceMetadataItemFunc != nil {
+		return m.SetInstanceMetadataItemFunc(uuid, key, value)
+	}
+	return nil
+}
+
+// SetInstanceMetadataItemCtx implements client.MetadataStore.
+func (m *MetadataStore) SetInstanceMetadataItemCtx(ctx context.Context, uuid string, key string, value string) error {
+	m.record("SetInstanceMetadataItemCtx", ctx, uuid, key, value)
+	if m.SetInstanceMetadataItemCtxFunc != nil {
+		return m.SetInstanceMetadataItemCtxFunc(ctx, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteInstanceMetadataItem implements client.MetadataStore.
+func (m *MetadataStore) DeleteInstanceMetadataItem(uuid string, key string) error {
+	m.record("DeleteInstanceMetadataItem", uuid, key)
+	if m.DeleteInstanceMetadataItemFunc != nil {
+		return m.DeleteInstanceMetadataItemFunc(uuid, key)
+	}
+	return nil
+}
+
+// DeleteInstanceMetadataItemCtx implements client.MetadataStore.
+func (m *MetadataStore) DeleteInstanceMetadataItemCtx(ctx context.Context, uuid string, key string) error {
+	m.record("DeleteInstanceMetadataItemCtx", ctx, uuid, key)
+	if m.DeleteInstanceMetadataItemCtxFunc != nil {
+		return m.DeleteInstanceMetadataItemCtxFunc(ctx, uuid, key)
+	}
+	return nil
+}
+
+// GetNetworkMetadata implements client.MetadataStore.
+func (m *MetadataStore) GetNetworkMetadata(uuid string) (client.Metadata, error) {
+	m.record("GetNetworkMetadata", uuid)
+	if m.GetNetworkMetadataFunc != nil {
+		return m.GetNetworkMetadataFunc(uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) GetNetworkMetadataCtx(ctx context.Context, uuid string) (client.Metadata, error) {
+	m.record("GetNetworkMetadataCtx", ctx, uuid)
+	if m.GetNetworkMetadataCtxFunc != nil {
+		return m.GetNetworkMetadataCtxFunc(ctx, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// SetNetworkMetadata implements client.MetadataStore.
+func (m *MetadataStore) SetNetworkMetadata(uuid string, key string, value string) error {
+	m.record("SetNetworkMetadata", uuid, key, value)
+	if m.SetNetworkMetadataFunc != nil {
+		return m.SetNetworkMetadataFunc(uuid, key, value)
+	}
+	return nil
+}
+
+// SetNetworkMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) SetNetworkMetadataCtx(ctx context.Context, uuid string, key string, value string) error {
+	m.record("SetNetworkMetadataCtx", ctx, uuid, key, value)
+	if m.SetNetworkMetadataCtxFunc != nil {
+		return m.SetNetworkMetadataCtxFunc(ctx, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteNetworkMetadata implements client.MetadataStore.
+func (m *MetadataStore) DeleteNetworkMetadata(uuid string, key string) error {
+	m.record("DeleteNetworkMetadata", uuid, key)
+	if m.DeleteNetworkMetadataFunc != nil {
+		return m.DeleteNetworkMetadataFunc(uuid, key)
+	}
+	return nil
+}
+
+// DeleteNetworkMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) DeleteNetworkMetadataCtx(ctx context.Context, uuid string, key string) error {
+	m.record("DeleteNetworkMetadataCtx", ctx, uuid, key)
+	if m.DeleteNetworkMetadataCtxFunc != nil {
+		return m.DeleteNetworkMetadataCtxFunc(ctx, uuid, key)
+	}
+	return nil
+}
+
+// GetNamespaceMetadata implements client.MetadataStore.
+func (m *MetadataStore) GetNamespaceMetadata(uuid string) (client.Metadata, error) {
+	m.record("GetNamespaceMetadata", uuid)
+	if m.GetNamespaceMetadataFunc != nil {
+		return m.GetNamespaceMetadataFunc(uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// GetNamespaceMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) GetNamespaceMetadataCtx(ctx context.Context, uuid string) (client.Metadata, error) {
+	m.record("GetNamespaceMetadataCtx", ctx, uuid)
+	if m.GetNamespaceMetadataCtxFunc != nil {
+		return m.GetNamespaceMetadataCtxFunc(ctx, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// SetNamespaceMetadata implements client.MetadataStore.
+func (m *MetadataStore) SetNamespaceMetadata(uuid string, key string, value string) error {
+	m.record("SetNamespaceMetadata", uuid, key, value)
+	if m.SetNamespaceMetadataFunc != nil {
+		return m.SetNamespaceMetadataFunc(uuid, key, value)
+	}
+	return nil
+}
+
+// SetNamespaceMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) SetNamespaceMetadataCtx(ctx context.Context, uuid string, key string, value string) error {
+	m.record("SetNamespaceMetadataCtx", ctx, uuid, key, value)
+	if m.SetNamespaceMetadataCtxFunc != nil {
+		return m.SetNamespaceMetadataCtxFunc(ctx, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteNamespaceMetadata implements client.MetadataStore.
+func (m *MetadataStore) DeleteNamespaceMetadata(uuid string, key string) error {
+	m.record("DeleteNamespaceMetadata", uuid, key)
+	if m.DeleteNamespaceMetadataFunc != nil {
+		return m.DeleteNamespaceMetadataFunc(uuid, key)
+	}
+	return nil
+}
+
+// DeleteNamespaceMetadataCtx implements client.MetadataStore.
+func (m *MetadataStore) DeleteNamespaceMetadataCtx(ctx context.Context, uuid string, key string) error {
+	m.record("DeleteNamespaceMetadataCtx", ctx, uuid, key)
+	if m.DeleteNamespaceMetadataCtxFunc != nil {
+		return m.DeleteNamespaceMetadataCtxFunc(ctx, uuid, key)
+	}
+	return nil
+}
+
+// Artifacts is a fake client.Artifacts. Each method calls the field of the
+// same name with Func appended, or returns zero values if it is nil.
+type Artifacts struct {
+	Recorder
+
+	CacheArtifactFunc          func(string) error
+	CacheArtifactCtxFunc       func(context.Context, string) error
+	GetArtifactFunc            func(string) (client.Artifact, error)
+	GetArtifactCtxFunc         func(context.Context, string) (client.Artifact, error)
+	GetArtifactsFunc           func(string) ([]client.Artifact, error)
+	GetArtifactsCtxFunc        func(context.Context, string) ([]client.Artifact, error)
+	GetArtifactEventsFunc      func(string) ([]client.Event, error)
+	GetArtifactEventsCtxFunc   func(context.Context, string) ([]client.Event, error)
+	GetArtifactVersionsFunc    func(string) ([]client.Blob, error)
+	GetArtifactVersionsCtxFunc func(context.Context, string) ([]client.Blob, error)
+	GetBlobsFunc               func(string) ([]client.Blob, error)
+	GetBlobsCtxFunc            func(context.Context, string) ([]client.Blob, error)
+	UpdateLabelFunc            func(string, string) error
+	UpdateLabelCtxFunc         func(context.Context, string, string) error
+	CacheImageFunc             func(string) error
+	CacheImageCtxFunc          func(context.Context, string) error
+	GetImageMetaFunc           func() ([]client.ImageMeta, error)
+	GetImageMetaCtxFunc        func(context.Context) ([]client.ImageMeta, error)
+}
+
+var _ client.Artifacts = (*Artifacts)(nil)
+
+// CacheArtifact implements client.Artifacts.
+func (m *Artifacts) CacheArtifact(image_url string) error {
+	m.record("CacheArtifact", image_url)
+	if m.CacheArtifactFunc != nil {
+		return m.CacheArtifactFunc(image_url)
+	}
+	return nil
+}
+
+// CacheArtifactCtx implements client.Artifacts.
+func (m *Artifacts) CacheArtifactCtx(ctx context.Context, image_url string) error {
+	m.record("CacheArtifactCtx", ctx, image_url)
+	if m.CacheArtifactCtxFunc != nil {
+		return m.CacheArtifactCtxFunc(ctx, image_url)
+	}
+	return nil
+}
+
+// GetArtifact implements client.Artifacts.
+func (m *Artifacts) GetArtifact(uuid string) (client.Artifact, error) {
+	m.record("GetArtifact", uuid)
+	if m.GetArtifactFunc != nil {
+		return m.GetArtifactFunc(uuid)
+	}
+	var r0 client.Artifact
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactCtx implements client.Artifacts.
+func (m *Artifacts) GetArtifactCtx(ctx context.Context, uuid string) (client.Artifact, error) {
+	m.record("GetArtifactCtx", ctx, uuid)
+	if m.GetArtifactCtxFunc != nil {
+		return m.GetArtifactCtxFunc(ctx, uuid)
+	}
+	var r0 client.Artifact
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifacts implements client.Artifacts.
+func (m *Artifacts) GetArtifacts(node string) ([]client.Artifact, error) {
+	m.record("GetArtifacts", node)
+	if m.GetArtifactsFunc != nil {
+		return m.GetArtifactsFunc(node)
+	}
+	var r0 []client.Artifact
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactsCtx implements client.Artifacts.
+func (m *Artifacts) GetArtifactsCtx(ctx context.Context, node string) ([]client.Artifact, error) {
+	m.record("GetArtifactsCtx", ctx, node)
+	if m.GetArtifactsCtxFunc != nil {
+		return m.GetArtifactsCtxFunc(ctx, node)
+	}
+	var r0 []client.Artifact
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactEvents implements client.Artifacts.
+func (m *Artifacts) GetArtifactEvents(uuid string) ([]client.Event, error) {
+	m.record("GetArtifactEvents", uuid)
+	if m.GetArtifactEventsFunc != nil {
+		return m.GetArtifactEventsFunc(uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactEventsCtx implements client.Artifacts.
+func (m *Artifacts) GetArtifactEventsCtx(ctx context.Context, uuid string) ([]client.Event, error) {
+	m.record("GetArtifactEventsCtx", ctx, uuid)
+	if m.GetArtifactEventsCtxFunc != nil {
+		return m.GetArtifactEventsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactVersions implements client.Artifacts.
+func (m *Artifacts) GetArtifactVersions(uuid string) ([]client.Blob, error) {
+	m.record("GetArtifactVersions", uuid)
+	if m.GetArtifactVersionsFunc != nil {
+		return m.GetArtifactVersionsFunc(uuid)
+	}
+	var r0 []client.Blob
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactVersionsCtx implements client.Artifacts.
+func (m *Artifacts) GetArtifactVersionsCtx(ctx context.Context, uuid string) ([]client.Blob, error) {
+	m.record("GetArtifactVersionsCtx", ctx, uuid)
+	if m.GetArtifactVersionsCtxFunc != nil {
+		return m.GetArtifactVersionsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Blob
+	var r1 error
+	return r0, r1
+}
+
+// GetBlobs implements client.Artifacts.
+func (m *Artifacts) GetBlobs(node string) ([]client.Blob, error) {
+	m.record("GetBlobs", node)
+	if m.GetBlobsFunc != nil {
+		return m.GetBlobsFunc(node)
+	}
+	var r0 []client.Blob
+	var r1 error
+	return r0, r1
+}
+
+// GetBlobsCtx implements client.Artifacts.
+func (m *Artifacts) GetBlobsCtx(ctx context.Context, node string) ([]client.Blob, error) {
+	m.record("GetBlobsCtx", ctx, node)
+	if m.GetBlobsCtxFunc != nil {
+		return m.GetBlobsCtxFunc(ctx, node)
+	}
+	var r0 []client.Blob
+	var r1 error
+	return r0, r1
+}
+
+// UpdateLabel implements client.Artifacts.
+func (m *Artifacts) UpdateLabel(labelName string, blobUUID string) error {
+	m.record("UpdateLabel", labelName, blobUUID)
+	if m.UpdateLabelFunc != nil {
+		return m.UpdateLabelFunc(labelName, blobUUID)
+	}
+	return nil
+}
+
+// UpdateLabelCtx implements client.Artifacts.
+func (m *Artifacts) UpdateLabelCtx(ctx context.Context, labelName string, blobUUID string) error {
+	m.record("UpdateLabelCtx", ctx, labelName, blobUUID)
+	if m.UpdateLabelCtxFunc != nil {
+		return m.UpdateLabelCtxFunc(ctx, labelName, blobUUID)
+	}
+	return nil
+}
+
+// CacheImage implements client.Artifacts.
+func (m *Artifacts) CacheImage(imageURL string) error {
+	m.record("CacheImage", imageURL)
+	if m.CacheImageFunc != nil {
+		return m.CacheImageFunc(imageURL)
+	}
+	return nil
+}
+
+// CacheImageCtx implements client.Artifacts.
+func (m *Artifacts) CacheImageCtx(ctx context.Context, imageURL string) error {
+	m.record("CacheImageCtx", ctx, imageURL)
+	if m.CacheImageCtxFunc != nil {
+		return m.CacheImageCtxFunc(ctx, imageURL)
+	}
+	return nil
+}
+
+// GetImageMeta implements client.Artifacts.
+func (m *Artifacts) GetImageMeta() ([]client.ImageMeta, error) {
+	m.record("GetImageMeta")
+	if m.GetImageMetaFunc != nil {
+		return m.GetImageMetaFunc()
+	}
+	var r0 []client.ImageMeta
+	var r1 error
+	return r0, r1
+}
+
+// GetImageMetaCtx implements client.Artifacts.
+func (m *Artifacts) GetImageMetaCtx(ctx context.Context) ([]client.ImageMeta, error) {
+	m.record("GetImageMetaCtx", ctx)
+	if m.GetImageMetaCtxFunc != nil {
+		return m.GetImageMetaCtxFunc(ctx)
+	}
+	var r0 []client.ImageMeta
+	var r1 error
+	return r0, r1
+}
+
+// Uploads is a fake client.Uploads. Each method calls the field of the
+// same name with Func appended, or returns zero values if it is nil.
+type Uploads struct {
+	Recorder
+
+	CreateUploadFunc      func() (client.Upload, error)
+	CreateUploadCtxFunc   func(context.Context) (client.Upload, error)
+	SendUploadFunc        func(string, io.ReaderAt, int64) (int64, error)
+	SendUploadCtxFunc     func(context.Context, string, io.ReaderAt, int64) (int64, error)
+	SendUploadFileFunc    func(string, string) (int64, error)
+	SendUploadFileCtxFunc func(context.Context, string, string) (int64, error)
+	TruncateUploadFunc    func(string, int64) error
+	TruncateUploadCtxFunc func(context.Context, string, int64) error
+}
+
+var _ client.Uploads = (*Uploads)(nil)
+
+// CreateUpload implements client.Uploads.
+func (m *Uploads) CreateUpload() (client.Upload, error) {
+	m.record("CreateUpload")
+	if m.CreateUploadFunc != nil {
+		return m.CreateUploadFunc()
+	}
+	var r0 client.Upload
+	var r1 error
+	return r0, r1
+}
+
+// CreateUploadCtx implements client.Uploads.
+func (m *Uploads) CreateUploadCtx(ctx context.Context) (client.Upload, error) {
+	m.record("CreateUploadCtx", ctx)
+	if m.CreateUploadCtxFunc != nil {
+		return m.CreateUploadCtxFunc(ctx)
+	}
+	var r0 client.Upload
+	var r1 error
+	return r0, r1
+}
+
+// SendUpload implements client.Uploads.
+func (m *Uploads) SendUpload(uuid string, data io.ReaderAt, size int64) (int64, error) {
+	m.record("SendUpload", uuid, data, size)
+	if m.SendUploadFunc != nil {
+		return m.SendUploadFunc(uuid, data, size)
+	}
+	var r0 int64
+	var r1 error
+	return r0, r1
+}
+
+// SendUploadCtx implements client.Uploads.
+func (m *Uploads) SendUploadCtx(ctx context.Context, uuid string, data io.ReaderAt, size int64) (int64, error) {
+	m.record("SendUploadCtx", ctx, uuid, data, size)
+	if m.SendUploadCtxFunc != nil {
+		return m.SendUploadCtxFunc(ctx, uuid, data, size)
+	}
+	var r0 int64
+	var r1 error
+	return r0, r1
+}
+
+// SendUploadFile implements client.Uploads.
+func (m *Uploads) SendUploadFile(uuid string, path string) (int64, error) {
+	m.record("SendUploadFile", uuid, path)
+	if m.SendUploadFileFunc != nil {
+		return m.SendUploadFileFunc(uuid, path)
+	}
+	var r0 int64
+	var r1 error
+	return r0, r1
+}
+
+// SendUploadFileCtx implements client.Uploads.
+func (m *Uploads) SendUploadFileCtx(ctx context.Context, uuid string, path string) (int64, error) {
+	m.record("SendUploadFileCtx", ctx, uuid, path)
+	if m.SendUploadFileCtxFunc != nil {
+		return m.SendUploadFileCtxFunc(ctx, uuid, path)
+	}
+	var r0 int64
+	var r1 error
+	return r0, r1
+}
+
+// TruncateUpload implements client.Uploads.
+func (m *Uploads) TruncateUpload(uuid string, offset int64) error {
+	m.record("TruncateUpload", uuid, offset)
+	if m.TruncateUploadFunc != nil {
+		return m.TruncateUploadFunc(uuid, offset)
+	}
+	return nil
+}
+
+// TruncateUploadCtx implements client.Uploads.
+func (m *Uploads) TruncateUploadCtx(ctx context.Context, uuid string, offset int64) error {
+	m.record("TruncateUploadCtx", ctx, uuid, offset)
+	if m.TruncateUploadCtxFunc != nil {
+		return m.TruncateUploadCtxFunc(ctx, uuid, offset)
+	}
+	return nil
+}
+
+// Watcher is a fake client.Watcher. Each method calls the field of the
+// same name with Func appended, or returns zero values if it is nil.
+type Watcher struct {
+	Recorder
+
+	WaitForDeletedFunc func(context.Context, client.ResourceType, string) error
+	WatchEventsFunc    func(context.Context, client.ResourceType, string, ...client.EventFilter) *client.EventWatch
+}
+
+var _ client.Watcher = (*Watcher)(nil)
+
+// WaitForDeleted implements client.Watcher.
+func (m *Watcher) WaitForDeleted(ctx context.Context, res client.ResourceType, uuid string) error {
+	m.record("WaitForDeleted", ctx, res, uuid)
+	if m.WaitForDeletedFunc != nil {
+		return m.WaitForDeletedFunc(ctx, res, uuid)
+	}
+	return nil
+}
+
+// WatchEvents implements client.Watcher.
+func (m *Watcher) WatchEvents(ctx context.Context, res client.ResourceType, uuid string, filters ...client.EventFilter) *client.EventWatch {
+	m.record("WatchEvents", ctx, res, uuid, filters)
+	if m.WatchEventsFunc != nil {
+		return m.WatchEventsFunc(ctx, res, uuid, filters...)
+	}
+	var r0 *client.EventWatch
+	return r0
+}
+
+// Admin is a fake client.Admin. Each method calls the field of the
+// same name with Func appended, or returns zero values if it is nil.
+type Admin struct {
+	Recorder
+
+	GetNodesFunc         func() ([]client.Node, error)
+	GetNodesCtxFunc      func(context.Context) ([]client.Node, error)
+	GetLocksFunc         func() (client.Locks, error)
+	GetLocksCtxFunc      func(context.Context) (client.Locks, error)
+	GetServerInfoFunc    func() (client.ServerInfo, error)
+	GetServerInfoCtxFunc func(context.Context) (client.ServerInfo, error)
+}
+
+var _ client.Admin = (*Admin)(nil)
+
+// GetNodes implements client.Admin.
+func (m *Admin) GetNodes() ([]client.Node, error) {
+	m.record("GetNodes")
+	if m.GetNodesFunc != nil {
+		return m.GetNodesFunc()
+	}
+	var r0 []client.Node
+	var r1 error
+	return r0, r1
+}
+
+// GetNodesCtx implements client.Admin.
+func (m *Admin) GetNodesCtx(ctx context.Context) ([]client.Node, error) {
+	m.record("GetNodesCtx", ctx)
+	if m.GetNodesCtxFunc != nil {
+		return m.GetNodesCtxFunc(ctx)
+	}
+	var r0 []client.Node
+	var r1 error
+	return r0, r1
+}
+
+// GetLocks implements client.Admin.
+func (m *Admin) GetLocks() (client.Locks, error) {
+	m.record("GetLocks")
+	if m.GetLocksFunc != nil {
+		return m.GetLocksFunc()
+	}
+	var r0 client.Locks
+	var r1 error
+	return r0, r1
+}
+
+// GetLocksCtx implements client.Admin.
+func (m *Admin) GetLocksCtx(ctx context.Context) (client.Locks, error) {
+	m.record("GetLocksCtx", ctx)
+	if m.GetLocksCtxFunc != nil {
+		return m.GetLocksCtxFunc(ctx)
+	}
+	var r0 client.Locks
+	var r1 error
+	return r0, r1
+}
+
+// GetServerInfo implements client.Admin.
+func (m *Admin) GetServerInfo() (client.ServerInfo, error) {
+	m.record("GetServerInfo")
+	if m.GetServerInfoFunc != nil {
+		return m.GetServerInfoFunc()
+	}
+	var r0 client.ServerInfo
+	var r1 error
+	return r0, r1
+}
+
+// GetServerInfoCtx implements client.Admin.
+func (m *Admin) GetServerInfoCtx(ctx context.Context) (client.ServerInfo, error) {
+	m.record("GetServerInfoCtx", ctx)
+	if m.GetServerInfoCtxFunc != nil {
+		return m.GetServerInfoCtxFunc(ctx)
+	}
+	var r0 client.ServerInfo
+	var r1 error
+	return r0, r1
+}
+
+// API is a fake client.API. Each method calls the field of the
+// same name with Func appended, or returns zero values if it is nil.
+type API struct {
+	Recorder
+
+	GetInstancesFunc                  func() ([]client.Instance, error)
+	GetInstancesCtxFunc               func(context.Context) ([]client.Instance, error)
+	GetInstanceFunc                   func(string) (client.Instance, error)
+	GetInstanceCtxFunc                func(context.Context, string) (client.Instance, error)
+	CreateInstanceFunc                func(string, int, int, []client.NetworkSpec, []client.DiskSpec, client.VideoSpec, string, string, string, string, bool, bool, string) (client.Instance, error)
+	CreateInstanceCtxFunc             func(context.Context, string, int, int, []client.NetworkSpec, []client.DiskSpec, client.VideoSpec, string, string, string, string, bool, bool, string) (client.Instance, error)
+	CreateInstanceFromSpecFunc        func(client.InstanceSpec) (client.Instance, error)
+	CreateInstanceFromSpecCtxFunc     func(context.Context, client.InstanceSpec) (client.Instance, error)
+	SnapshotInstanceFunc              func(string, bool, string) error
+	SnapshotInstanceCtxFunc           func(context.Context, string, bool, string) error
+	GetInstanceSnapshotsFunc          func(string) ([]client.Snapshot, error)
+	GetInstanceSnapshotsCtxFunc       func(context.Context, string) ([]client.Snapshot, error)
+	RebootInstanceFunc                func(string) error
+	RebootInstanceCtxFunc             func(context.Context, string) error
+	PowerOffInstanceFunc              func(string) error
+	PowerOffInstanceCtxFunc           func(context.Context, string) error
+	PowerOnInstanceFunc               func(string) error
+	PowerOnInstanceCtxFunc            func(context.Context, string) error
+	PauseInstanceFunc                 func(string) error
+	PauseInstanceCtxFunc              func(context.Context, string) error
+	UnPauseInstanceFunc               func(string) error
+	UnPauseInstanceCtxFunc            func(context.Context, string) error
+	DeleteInstanceFunc                func(string, string) error
+	DeleteInstanceCtxFunc             func(context.Context, string, string) error
+	DeleteAllInstancesFunc            func(string) ([]string, error)
+	DeleteAllInstancesCtxFunc         func(context.Context, string) ([]string, error)
+	GetInstanceEventsFunc             func(string) ([]client.Event, error)
+	GetInstanceEventsCtxFunc          func(context.Context, string) ([]client.Event, error)
+	GetConsoleDataFunc                func(string, int) (string, error)
+	GetConsoleDataCtxFunc             func(context.Context, string, int) (string, error)
+	WaitForInstanceStateFunc          func(context.Context, string, []client.State, []client.State) (client.Instance, error)
+	StreamConsoleFunc                 func(context.Context, string) io.ReadCloser
+	GetNetworksFunc                   func() ([]client.Network, error)
+	GetNetworksCtxFunc                func(context.Context) ([]client.Network, error)
+	GetNetworkFunc                    func(string) (client.Network, error)
+	GetNetworkCtxFunc                 func(context.Context, string) (client.Network, error)
+	CreateNetworkFunc                 func(string, bool, bool, string) (client.Network, error)
+	CreateNetworkCtxFunc              func(context.Context, string, bool, bool, string) (client.Network, error)
+	DeleteNetworkFunc                 func(string) error
+	DeleteNetworkCtxFunc              func(context.Context, string) error
+	DeleteAllNetworksFunc             func(string) ([]string, error)
+	DeleteAllNetworksCtxFunc          func(context.Context, string) ([]string, error)
+	GetNetworkEventsFunc              func(string) ([]client.Event, error)
+	GetNetworkEventsCtxFunc           func(context.Context, string) ([]client.Event, error)
+	GetInstanceInterfacesFunc         func(string) ([]client.NetworkInterface, error)
+	GetInstanceInterfacesCtxFunc      func(context.Context, string) ([]client.NetworkInterface, error)
+	GetNetworkInterfacesFunc          func(string) ([]client.NetworkInterface, error)
+	GetNetworkInterfacesCtxFunc       func(context.Context, string) ([]client.NetworkInterface, error)
+	GetInterfaceFunc                  func(string) (client.NetworkInterface, error)
+	GetInterfaceCtxFunc               func(context.Context, string) (client.NetworkInterface, error)
+	FloatInterfaceFunc                func(string) error
+	FloatInterfaceCtxFunc             func(context.Context, string) error
+	DefloatInterfaceFunc              func(string) error
+	DefloatInterfaceCtxFunc           func(context.Context, string) error
+	WaitForNetworkStateFunc           func(context.Context, string, []client.State, []client.State) (client.Network, error)
+	GetNamespacesFunc                 func() ([]string, error)
+	GetNamespacesCtxFunc              func(context.Context) ([]string, error)
+	CreateNamespaceFunc               func(string) error
+	CreateNamespaceCtxFunc            func(context.Context, string) error
+	DeleteNamespaceFunc               func(string) error
+	DeleteNamespaceCtxFunc            func(context.Context, string) error
+	GetNamespaceKeysFunc              func(string) ([]string, error)
+	GetNamespaceKeysCtxFunc           func(context.Context, string) ([]string, error)
+	CreateNamespaceKeyFunc            func(string, string, string) error
+	CreateNamespaceKeyCtxFunc         func(context.Context, string, string, string) error
+	UpdateNamespaceKeyFunc            func(string, string, string) error
+	UpdateNamespaceKeyCtxFunc         func(context.Context, string, string, string) error
+	DeleteNamespaceKeyFunc            func(string, string) error
+	DeleteNamespaceKeyCtxFunc         func(context.Context, string, string) error
+	GetMetadataFunc                   func(client.ResourceType, string) (client.Metadata, error)
+	GetMetadataCtxFunc                func(context.Context, client.ResourceType, string) (client.Metadata, error)
+	SetMetadataFunc                   func(client.ResourceType, string, string, string) error
+	SetMetadataCtxFunc                func(context.Context, client.ResourceType, string, string, string) error
+	DeleteMetadataFunc                func(client.ResourceType, string, string) error
+	DeleteMetadataCtxFunc             func(context.Context, client.ResourceType, string, string) error
+	GetInstanceMetadataFunc           func(string) (client.Metadata, error)
+	GetInstanceMetadataCtxFunc        func(context.Context, string) (client.Metadata, error)
+	SetInstanceMetadataFunc           func(string, string, string) error
+	SetInstanceMetadataCtxFunc        func(context.Context, string, string, string) error
+	DeleteInstanceMetadataFunc        func(string, string) error
+	DeleteInstanceMetadataCtxFunc     func(context.Context, string, string) error
+	SetInstanceMetadataItemFunc       func(string, string, string) error
+	SetInstanceMetadataItemCtxFunc    func(context.Context, string, string, string) error
+	DeleteInstanceMetadataItemFunc    func(string, string) error
+	DeleteInstanceMetadataItemCtxFunc func(context.Context, string, string) error
+	GetNetworkMetadataFunc            func(string) (client.Metadata, error)
+	GetNetworkMetadataCtxFunc         func(context.Context, string) (client.Metadata, error)
+	SetNetworkMetadataFunc            func(string, string, string) error
+	SetNetworkMetadataCtxFunc         func(context.Context, string, string, string) error
+	DeleteNetworkMetadataFunc         func(string, string) error
+	DeleteNetworkMetadataCtxFunc      func(context.Context, string, string) error
+	GetNamespaceMetadataFunc          func(string) (client.Metadata, error)
+	GetNamespaceMetadataCtxFunc       func(context.Context, string) (client.Metadata, error)
+	SetNamespaceMetadataFunc          func(string, string, string) error
+	SetNamespaceMetadataCtxFunc       func(context.Context, string, string, string) error
+	DeleteNamespaceMetadataFunc       func(string, string) error
+	DeleteNamespaceMetadataCtxFunc    func(context.Context, string, string) error
+	CacheArtifactFunc                 func(string) error
+	CacheArtifactCtxFunc              func(context.Context, string) error
+	GetArtifactFunc                   func(string) (client.Artifact, error)
+	GetArtifactCtxFunc                func(context.Context, string) (client.Artifact, error)
+	GetArtifactsFunc                  func(string) ([]client.Artifact, error)
+	GetArtifactsCtxFunc               func(context.Context, string) ([]client.Artifact, error)
+	GetArtifactEventsFunc             func(string) ([]client.Event, error)
+	GetArtifactEventsCtxFunc          func(context.Context, string) ([]client.Event, error)
+	GetArtifactVersionsFunc           func(string) ([]client.Blob, error)
+	GetArtifactVersionsCtxFunc        func(context.Context, string) ([]client.Blob, error)
+	GetBlobsFunc                      func(string) ([]client.Blob, error)
+	GetBlobsCtxFunc                   func(context.Context, string) ([]client.Blob, error)
+	UpdateLabelFunc                   func(string, string) error
+	UpdateLabelCtxFunc                func(context.Context, string, string) error
+	CacheImageFunc                    func(string) error
+	CacheImageCtxFunc                 func(context.Context, string) error
+	GetImageMetaFunc                  func() ([]client.ImageMeta, error)
+	GetImageMetaCtxFunc               func(context.Context) ([]client.ImageMeta, error)
+	CreateUploadFunc                  func() (client.Upload, error)
+	CreateUploadCtxFunc               func(context.Context) (client.Upload, error)
+	SendUploadFunc                    func(string, io.ReaderAt, int64) (int64, error)
+	SendUploadCtxFunc                 func(context.Context, string, io.ReaderAt, int64) (int64, error)
+	SendUploadFileFunc                func(string, string) (int64, error)
+	SendUploadFileCtxFunc             func(context.Context, string, string) (int64, error)
+	TruncateUploadFunc                func(string, int64) error
+	TruncateUploadCtxFunc             func(context.Context, string, int64) error
+	WaitForDeletedFunc                func(context.Context, client.ResourceType, string) error
+	WatchEventsFunc                   func(context.Context, client.ResourceType, string, ...client.EventFilter) *client.EventWatch
+	GetNodesFunc                      func() ([]client.Node, error)
+	GetNodesCtxFunc                   func(context.Context) ([]client.Node, error)
+	GetLocksFunc                      func() (client.Locks, error)
+	GetLocksCtxFunc                   func(context.Context) (client.Locks, error)
+	GetServerInfoFunc                 func() (client.ServerInfo, error)
+	GetServerInfoCtxFunc              func(context.Context) (client.ServerInfo, error)
+}
+
+var _ client.API = (*API)(nil)
+
+// GetInstances implements client.API.
+func (m *API) GetInstances() ([]client.Instance, error) {
+	m.record("GetInstances")
+	if m.GetInstancesFunc != nil {
+		return m.GetInstancesFunc()
+	}
+	var r0 []client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// GetInstancesCtx implements client.API.
+func (m *API) GetInstancesCtx(ctx context.Context) ([]client.Instance, error) {
+	m.record("GetInstancesCtx", ctx)
+	if m.GetInstancesCtxFunc != nil {
+		return m.GetInstancesCtxFunc(ctx)
+	}
+	var r0 []client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// GetInstance implements client.API.
+func (m *API) GetInstance(uuid string) (client.Instance, error) {
+	m.record("GetInstance", uuid)
+	if m.GetInstanceFunc != nil {
+		return m.GetInstanceFunc(uuid)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceCtx implements client.API.
+func (m *API) GetInstanceCtx(ctx context.Context, uuid string) (client.Instance, error) {
+	m.record("GetInstanceCtx", ctx, uuid)
+	if m.GetInstanceCtxFunc != nil {
+		return m.GetInstanceCtxFunc(ctx, uuid)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// CreateInstance implements client.API.
+func (m *API) CreateInstance(name string, cpus int, memory int, networks []client.NetworkSpec, disks []client.DiskSpec, video client.VideoSpec, sshKey string, userData string, nameSpace string, metadata string, secureBoot bool, uefi bool, nvramTemplate string) (client.Instance, error) {
+	m.record("CreateInstance", name, cpus, memory, networks, disks, video, sshKey, userData, nameSpace, metadata, secureBoot, uefi, nvramTemplate)
+	if m.CreateInstanceFunc != nil {
+		return m.CreateInstanceFunc(name, cpus, memory, networks, disks, video, sshKey, userData, nameSpace, metadata, secureBoot, uefi, nvramTemplate)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// CreateInstanceCtx implements client.API.
+func (m *API) CreateInstanceCtx(ctx context.Context, name string, cpus int, memory int, networks []client.NetworkSpec, disks []client.DiskSpec, video client.VideoSpec, sshKey string, userData string, nameSpace string, metadata string, secureBoot bool, uefi bool, nvramTemplate string) (client.Instance, error) {
+	m.record("CreateInstanceCtx", ctx, name, cpus, memory, networks, disks, video, sshKey, userData, nameSpace, metadata, secureBoot, uefi, nvramTemplate)
+	if m.CreateInstanceCtxFunc != nil {
+		return m.CreateInstanceCtxFunc(ctx, name, cpus, memory, networks, disks, video, sshKey, userData, nameSpace, metadata, secureBoot, uefi, nvramTemplate)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// CreateInstanceFromSpec implements client.API.
+func (m *API) CreateInstanceFromSpec(spec client.InstanceSpec) (client.Instance, error) {
+	m.record("CreateInstanceFromSpec", spec)
+	if m.CreateInstanceFromSpecFunc != nil {
+		return m.CreateInstanceFromSpecFunc(spec)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// CreateInstanceFromSpecCtx implements client.API.
+func (m *API) CreateInstanceFromSpecCtx(ctx context.Context, spec client.InstanceSpec) (client.Instance, error) {
+	m.record("CreateInstanceFromSpecCtx", ctx, spec)
+	if m.CreateInstanceFromSpecCtxFunc != nil {
+		return m.CreateInstanceFromSpecCtxFunc(ctx, spec)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// SnapshotInstance implements client.API.
+func (m *API) SnapshotInstance(uuid string, all bool, device string) error {
+	m.record("SnapshotInstance", uuid, all, device)
+	if m.SnapshotInstanceFunc != nil {
+		return m.SnapshotInstanceFunc(uuid, all, device)
+	}
+	return nil
+}
+
+// SnapshotInstanceCtx implements client.API.
+func (m *API) SnapshotInstanceCtx(ctx context.Context, uuid string, all bool, device string) error {
+	m.record("SnapshotInstanceCtx", ctx, uuid, all, device)
+	if m.SnapshotInstanceCtxFunc != nil {
+		return m.SnapshotInstanceCtxFunc(ctx, uuid, all, device)
+	}
+	return nil
+}
+
+// GetInstanceSnapshots implements client.API.
+func (m *API) GetInstanceSnapshots(uuid string) ([]client.Snapshot, error) {
+	m.record("GetInstanceSnapshots", uuid)
+	if m.GetInstanceSnapshotsFunc != nil {
+		return m.GetInstanceSnapshotsFunc(uuid)
+	}
+	var r0 []client.Snapshot
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceSnapshotsCtx implements client.API.
+func (m *API) GetInstanceSnapshotsCtx(ctx context.Context, uuid string) ([]client.Snapshot, error) {
+	m.record("GetInstanceSnapshotsCtx", ctx, uuid)
+	if m.GetInstanceSnapshotsCtxFunc != nil {
+		return m.GetInstanceSnapshotsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Snapshot
+	var r1 error
+	return r0, r1
+}
+
+// RebootInstance implements client.API.
+func (m *API) RebootInstance(uuid string) error {
+	m.record("RebootInstance", uuid)
+	if m.RebootInstanceFunc != nil {
+		return m.RebootInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// RebootInstanceCtx implements client.API.
+func (m *API) RebootInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("RebootInstanceCtx", ctx, uuid)
+	if m.RebootInstanceCtxFunc != nil {
+		return m.RebootInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// PowerOffInstance implements client.API.
+func (m *API) PowerOffInstance(uuid string) error {
+	m.record("PowerOffInstance", uuid)
+	if m.PowerOffInstanceFunc != nil {
+		return m.PowerOffInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// PowerOffInstanceCtx implements client.API.
+func (m *API) PowerOffInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("PowerOffInstanceCtx", ctx, uuid)
+	if m.PowerOffInstanceCtxFunc != nil {
+		return m.PowerOffInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// PowerOnInstance implements client.API.
+func (m *API) PowerOnInstance(uuid string) error {
+	m.record("PowerOnInstance", uuid)
+	if m.PowerOnInstanceFunc != nil {
+		return m.PowerOnInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// PowerOnInstanceCtx implements client.API.
+func (m *API) PowerOnInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("PowerOnInstanceCtx", ctx, uuid)
+	if m.PowerOnInstanceCtxFunc != nil {
+		return m.PowerOnInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// PauseInstance implements client.API.
+func (m *API) PauseInstance(uuid string) error {
+	m.record("PauseInstance", uuid)
+	if m.PauseInstanceFunc != nil {
+		return m.PauseInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// PauseInstanceCtx implements client.API.
+func (m *API) PauseInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("PauseInstanceCtx", ctx, uuid)
+	if m.PauseInstanceCtxFunc != nil {
+		return m.PauseInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// UnPauseInstance implements client.API.
+func (m *API) UnPauseInstance(uuid string) error {
+	m.record("UnPauseInstance", uuid)
+	if m.UnPauseInstanceFunc != nil {
+		return m.UnPauseInstanceFunc(uuid)
+	}
+	return nil
+}
+
+// UnPauseInstanceCtx implements client.API.
+func (m *API) UnPauseInstanceCtx(ctx context.Context, uuid string) error {
+	m.record("UnPauseInstanceCtx", ctx, uuid)
+	if m.UnPauseInstanceCtxFunc != nil {
+		return m.UnPauseInstanceCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// DeleteInstance implements client.API.
+func (m *API) DeleteInstance(uuid string, namespace string) error {
+	m.record("DeleteInstance", uuid, namespace)
+	if m.DeleteInstanceFunc != nil {
+		return m.DeleteInstanceFunc(uuid, namespace)
+	}
+	return nil
+}
+
+// DeleteInstanceCtx implements client.API.
+func (m *API) DeleteInstanceCtx(ctx context.Context, uuid string, namespace string) error {
+	m.record("DeleteInstanceCtx", ctx, uuid, namespace)
+	if m.DeleteInstanceCtxFunc != nil {
+		return m.DeleteInstanceCtxFunc(ctx, uuid, namespace)
+	}
+	return nil
+}
+
+// DeleteAllInstances implements client.API.
+func (m *API) DeleteAllInstances(namespace string) ([]string, error) {
+	m.record("DeleteAllInstances", namespace)
+	if m.DeleteAllInstancesFunc != nil {
+		return m.DeleteAllInstancesFunc(namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// DeleteAllInstancesCtx implements client.API.
+func (m *API) DeleteAllInstancesCtx(ctx context.Context, namespace string) ([]string, error) {
+	m.record("DeleteAllInstancesCtx", ctx, namespace)
+	if m.DeleteAllInstancesCtxFunc != nil {
+		return m.DeleteAllInstancesCtxFunc(ctx, namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceEvents implements client.API.
+func (m *API) GetInstanceEvents(uuid string) ([]client.Event, error) {
+	m.record("GetInstanceEvents", uuid)
+	if m.GetInstanceEventsFunc != nil {
+		return m.GetInstanceEventsFunc(uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceEventsCtx implements client.API.
+func (m *API) GetInstanceEventsCtx(ctx context.Context, uuid string) ([]client.Event, error) {
+	m.record("GetInstanceEventsCtx", ctx, uuid)
+	if m.GetInstanceEventsCtxFunc != nil {
+		return m.GetInstanceEventsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetConsoleData implements client.API.
+func (m *API) GetConsoleData(uuid string, n int) (string, error) {
+	m.record("GetConsoleData", uuid, n)
+	if m.GetConsoleDataFunc != nil {
+		return m.GetConsoleDataFunc(uuid, n)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetConsoleDataCtx implements client.API.
+func (m *API) GetConsoleDataCtx(ctx context.Context, uuid string, n int) (string, error) {
+	m.record("GetConsoleDataCtx", ctx, uuid, n)
+	if m.GetConsoleDataCtxFunc != nil {
+		return m.GetConsoleDataCtxFunc(ctx, uuid, n)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// WaitForInstanceState implements client.API.
+func (m *API) WaitForInstanceState(ctx context.Context, uuid string, targets []client.State, failures []client.State) (client.Instance, error) {
+	m.record("WaitForInstanceState", ctx, uuid, targets, failures)
+	if m.WaitForInstanceStateFunc != nil {
+		return m.WaitForInstanceStateFunc(ctx, uuid, targets, failures)
+	}
+	var r0 client.Instance
+	var r1 error
+	return r0, r1
+}
+
+// StreamConsole implements client.API.
+func (m *API) StreamConsole(ctx context.Context, uuid string) io.ReadCloser {
+	m.record("StreamConsole", ctx, uuid)
+	if m.StreamConsoleFunc != nil {
+		return m.StreamConsoleFunc(ctx, uuid)
+	}
+	var r0 io.ReadCloser
+	return r0
+}
+
+// GetNetworks implements client.API.
+func (m *API) GetNetworks() ([]client.Network, error) {
+	m.record("GetNetworks")
+	if m.GetNetworksFunc != nil {
+		return m.GetNetworksFunc()
+	}
+	var r0 []client.Network
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworksCtx implements client.API.
+func (m *API) GetNetworksCtx(ctx context.Context) ([]client.Network, error) {
+	m.record("GetNetworksCtx", ctx)
+	if m.GetNetworksCtxFunc != nil {
+		return m.GetNetworksCtxFunc(ctx)
+	}
+	var r0 []client.Network
+	var r1 error
+	return r0, r1
+}
+
+// GetNetwork implements client.API.
+func (m *API) GetNetwork(uuid string) (client.Network, error) {
+	m.record("GetNetwork", uuid)
+	if m.GetNetworkFunc != nil {
+		return m.GetNetworkFunc(uuid)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkCtx implements client.API.
+func (m *API) GetNetworkCtx(ctx context.Context, uuid string) (client.Network, error) {
+	m.record("GetNetworkCtx", ctx, uuid)
+	if m.GetNetworkCtxFunc != nil {
+		return m.GetNetworkCtxFunc(ctx, uuid)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// CreateNetwork implements client.API.
+func (m *API) CreateNetwork(netblock string, provideDHCP bool, provideNAT bool, name string) (client.Network, error) {
+	m.record("CreateNetwork", netblock, provideDHCP, provideNAT, name)
+	if m.CreateNetworkFunc != nil {
+		return m.CreateNetworkFunc(netblock, provideDHCP, provideNAT, name)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// CreateNetworkCtx implements client.API.
+func (m *API) CreateNetworkCtx(ctx context.Context, netblock string, provideDHCP bool, provideNAT bool, name string) (client.Network, error) {
+	m.record("CreateNetworkCtx", ctx, netblock, provideDHCP, provideNAT, name)
+	if m.CreateNetworkCtxFunc != nil {
+		return m.CreateNetworkCtxFunc(ctx, netblock, provideDHCP, provideNAT, name)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// DeleteNetwork implements client.API.
+func (m *API) DeleteNetwork(uuid string) error {
+	m.record("DeleteNetwork", uuid)
+	if m.DeleteNetworkFunc != nil {
+		return m.DeleteNetworkFunc(uuid)
+	}
+	return nil
+}
+
+// DeleteNetworkCtx implements client.API.
+func (m *API) DeleteNetworkCtx(ctx context.Context, uuid string) error {
+	m.record("DeleteNetworkCtx", ctx, uuid)
+	if m.DeleteNetworkCtxFunc != nil {
+		return m.DeleteNetworkCtxFunc(ctx, uuid)
+	}
+	return nil
+}
+
+// DeleteAllNetworks implements client.API.
+func (m *API) DeleteAllNetworks(namespace string) ([]string, error) {
+	m.record("DeleteAllNetworks", namespace)
+	if m.DeleteAllNetworksFunc != nil {
+		return m.DeleteAllNetworksFunc(namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// DeleteAllNetworksCtx implements client.API.
+func (m *API) DeleteAllNetworksCtx(ctx context.Context, namespace string) ([]string, error) {
+	m.record("DeleteAllNetworksCtx", ctx, namespace)
+	if m.DeleteAllNetworksCtxFunc != nil {
+		return m.DeleteAllNetworksCtxFunc(ctx, namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkEvents implements client.API.
+func (m *API) GetNetworkEvents(uuid string) ([]client.Event, error) {
+	m.record("GetNetworkEvents", uuid)
+	if m.GetNetworkEventsFunc != nil {
+		return m.GetNetworkEventsFunc(uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkEventsCtx implements client.API.
+func (m *API) GetNetworkEventsCtx(ctx context.Context, uuid string) ([]client.Event, error) {
+	m.record("GetNetworkEventsCtx", ctx, uuid)
+	if m.GetNetworkEventsCtxFunc != nil {
+		return m.GetNetworkEventsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceInterfaces implements client.API.
+func (m *API) GetInstanceInterfaces(uuid string) ([]client.NetworkInterface, error) {
+	m.record("GetInstanceInterfaces", uuid)
+	if m.GetInstanceInterfacesFunc != nil {
+		return m.GetInstanceInterfacesFunc(uuid)
+	}
+	var r0 []client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceInterfacesCtx implements client.API.
+func (m *API) GetInstanceInterfacesCtx(ctx context.Context, uuid string) ([]client.NetworkInterface, error) {
+	m.record("GetInstanceInterfacesCtx", ctx, uuid)
+	if m.GetInstanceInterfacesCtxFunc != nil {
+		return m.GetInstanceInterfacesCtxFunc(ctx, uuid)
+	}
+	var r0 []client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkInterfaces implements client.API.
+func (m *API) GetNetworkInterfaces(uuid string) ([]client.NetworkInterface, error) {
+	m.record("GetNetworkInterfaces", uuid)
+	if m.GetNetworkInterfacesFunc != nil {
+		return m.GetNetworkInterfacesFunc(uuid)
+	}
+	var r0 []client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkInterfacesCtx implements client.API.
+func (m *API) GetNetworkInterfacesCtx(ctx context.Context, uuid string) ([]client.NetworkInterface, error) {
+	m.record("GetNetworkInterfacesCtx", ctx, uuid)
+	if m.GetNetworkInterfacesCtxFunc != nil {
+		return m.GetNetworkInterfacesCtxFunc(ctx, uuid)
+	}
+	var r0 []client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetInterface implements client.API.
+func (m *API) GetInterface(uuid string) (client.NetworkInterface, error) {
+	m.record("GetInterface", uuid)
+	if m.GetInterfaceFunc != nil {
+		return m.GetInterfaceFunc(uuid)
+	}
+	var r0 client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// GetInterfaceCtx implements client.API.
+func (m *API) GetInterfaceCtx(ctx context.Context, uuid string) (client.NetworkInterface, error) {
+	m.record("GetInterfaceCtx", ctx, uuid)
+	if m.GetInterfaceCtxFunc != nil {
+		return m.GetInterfaceCtxFunc(ctx, uuid)
+	}
+	var r0 client.NetworkInterface
+	var r1 error
+	return r0, r1
+}
+
+// FloatInterface implements client.API.
+func (m *API) FloatInterface(interfaceUUID string) error {
+	m.record("FloatInterface", interfaceUUID)
+	if m.FloatInterfaceFunc != nil {
+		return m.FloatInterfaceFunc(interfaceUUID)
+	}
+	return nil
+}
+
+// FloatInterfaceCtx implements client.API.
+func (m *API) FloatInterfaceCtx(ctx context.Context, interfaceUUID string) error {
+	m.record("FloatInterfaceCtx", ctx, interfaceUUID)
+	if m.FloatInterfaceCtxFunc != nil {
+		return m.FloatInterfaceCtxFunc(ctx, interfaceUUID)
+	}
+	return nil
+}
+
+// DefloatInterface implements client.API.
+func (m *API) DefloatInterface(interfaceUUID string) error {
+	m.record("DefloatInterface", interfaceUUID)
+	if m.DefloatInterfaceFunc != nil {
+		return m.DefloatInterfaceFunc(interfaceUUID)
+	}
+	return nil
+}
+
+// DefloatInterfaceCtx implements client.API.
+func (m *API) DefloatInterfaceCtx(ctx context.Context, interfaceUUID string) error {
+	m.record("DefloatInterfaceCtx", ctx, interfaceUUID)
+	if m.DefloatInterfaceCtxFunc != nil {
+		return m.DefloatInterfaceCtxFunc(ctx, interfaceUUID)
+	}
+	return nil
+}
+
+// WaitForNetworkState implements client.API.
+func (m *API) WaitForNetworkState(ctx context.Context, uuid string, targets []client.State, failures []client.State) (client.Network, error) {
+	m.record("WaitForNetworkState", ctx, uuid, targets, failures)
+	if m.WaitForNetworkStateFunc != nil {
+		return m.WaitForNetworkStateFunc(ctx, uuid, targets, failures)
+	}
+	var r0 client.Network
+	var r1 error
+	return r0, r1
+}
+
+// GetNamespaces implements client.API.
+func (m *API) GetNamespaces() ([]string, error) {
+	m.record("GetNamespaces")
+	if m.GetNamespacesFunc != nil {
+		return m.GetNamespacesFunc()
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetNamespacesCtx implements client.API.
+func (m *API) GetNamespacesCtx(ctx context.Context) ([]string, error) {
+	m.record("GetNamespacesCtx", ctx)
+	if m.GetNamespacesCtxFunc != nil {
+		return m.GetNamespacesCtxFunc(ctx)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// CreateNamespace implements client.API.
+func (m *API) CreateNamespace(namespace string) error {
+	m.record("CreateNamespace", namespace)
+	if m.CreateNamespaceFunc != nil {
+		return m.CreateNamespaceFunc(namespace)
+	}
+	return nil
+}
+
+// CreateNamespaceCtx implements client.API.
+func (m *API) CreateNamespaceCtx(ctx context.Context, namespace string) error {
+	m.record("CreateNamespaceCtx", ctx, namespace)
+	if m.CreateNamespaceCtxFunc != nil {
+		return m.CreateNamespaceCtxFunc(ctx, namespace)
+	}
+	return nil
+}
+
+// DeleteNamespace implements client.API.
+func (m *API) DeleteNamespace(namespace string) error {
+	m.record("DeleteNamespace", namespace)
+	if m.DeleteNamespaceFunc != nil {
+		return m.DeleteNamespaceFunc(namespace)
+	}
+	return nil
+}
+
+// DeleteNamespaceCtx implements client.API.
+func (m *API) DeleteNamespaceCtx(ctx context.Context, namespace string) error {
+	m.record("DeleteNamespaceCtx", ctx, namespace)
+	if m.DeleteNamespaceCtxFunc != nil {
+		return m.DeleteNamespaceCtxFunc(ctx, namespace)
+	}
+	return nil
+}
+
+// GetNamespaceKeys implements client.API.
+func (m *API) GetNamespaceKeys(namespace string) ([]string, error) {
+	m.record("GetNamespaceKeys", namespace)
+	if m.GetNamespaceKeysFunc != nil {
+		return m.GetNamespaceKeysFunc(namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetNamespaceKeysCtx implements client.API.
+func (m *API) GetNamespaceKeysCtx(ctx context.Context, namespace string) ([]string, error) {
+	m.record("GetNamespaceKeysCtx", ctx, namespace)
+	if m.GetNamespaceKeysCtxFunc != nil {
+		return m.GetNamespaceKeysCtxFunc(ctx, namespace)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// CreateNamespaceKey implements client.API.
+func (m *API) CreateNamespaceKey(namespace string, keyName string, key string) error {
+	m.record("CreateNamespaceKey", namespace, keyName, key)
+	if m.CreateNamespaceKeyFunc != nil {
+		return m.CreateNamespaceKeyFunc(namespace, keyName, key)
+	}
+	return nil
+}
+
+// CreateNamespaceKeyCtx implements client.API.
+func (m *API) CreateNamespaceKeyCtx(ctx context.Context, namespace string, keyName string, key string) error {
+	m.record("CreateNamespaceKeyCtx", ctx, namespace, keyName, key)
+	if m.CreateNamespaceKeyCtxFunc != nil {
+		return m.CreateNamespaceKeyCtxFunc(ctx, namespace, keyName, key)
+	}
+	return nil
+}
+
+// UpdateNamespaceKey implements client.API.
+func (m *API) UpdateNamespaceKey(namespace string, keyName string, key string) error {
+	m.record("UpdateNamespaceKey", namespace, keyName, key)
+	if m.UpdateNamespaceKeyFunc != nil {
+		return m.UpdateNamespaceKeyFunc(namespace, keyName, key)
+	}
+	return nil
+}
+
+// UpdateNamespaceKeyCtx implements client.API.
+func (m *API) UpdateNamespaceKeyCtx(ctx context.Context, namespace string, keyName string, key string) error {
+	m.record("UpdateNamespaceKeyCtx", ctx, namespace, keyName, key)
+	if m.UpdateNamespaceKeyCtxFunc != nil {
+		return m.UpdateNamespaceKeyCtxFunc(ctx, namespace, keyName, key)
+	}
+	return nil
+}
+
+// DeleteNamespaceKey implements client.API.
+func (m *API) DeleteNamespaceKey(namespace string, keyName string) error {
+	m.record("DeleteNamespaceKey", namespace, keyName)
+	if m.DeleteNamespaceKeyFunc != nil {
+		return m.DeleteNamespaceKeyFunc(namespace, keyName)
+	}
+	return nil
+}
+
+// DeleteNamespaceKeyCtx implements client.API.
+func (m *API) DeleteNamespaceKeyCtx(ctx context.Context, namespace string, keyName string) error {
+	m.record("DeleteNamespaceKeyCtx", ctx, namespace, keyName)
+	if m.DeleteNamespaceKeyCtxFunc != nil {
+		return m.DeleteNamespaceKeyCtxFunc(ctx, namespace, keyName)
+	}
+	return nil
+}
+
+// GetMetadata implements client.API.
+func (m *API) GetMetadata(res client.ResourceType, uuid string) (client.Metadata, error) {
+	m.record("GetMetadata", res, uuid)
+	if m.GetMetadataFunc != nil {
+		return m.GetMetadataFunc(res, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// GetMetadataCtx implements client.API.
+func (m *API) GetMetadataCtx(ctx context.Context, res client.ResourceType, uuid string) (client.Metadata, error) {
+	m.record("GetMetadataCtx", ctx, res, uuid)
+	if m.GetMetadataCtxFunc != nil {
+		return m.GetMetadataCtxFunc(ctx, res, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// SetMetadata implements client.API.
+func (m *API) SetMetadata(res client.ResourceType, uuid string, key string, value string) error {
+	m.record("SetMetadata", res, uuid, key, value)
+	if m.SetMetadataFunc != nil {
+		return m.SetMetadataFunc(res, uuid, key, value)
+	}
+	return nil
+}
+
+// SetMetadataCtx implements client.API.
+func (m *API) SetMetadataCtx(ctx context.Context, res client.ResourceType, uuid string, key string, value string) error {
+	m.record("SetMetadataCtx", ctx, res, uuid, key, value)
+	if m.SetMetadataCtxFunc != nil {
+		return m.SetMetadataCtxFunc(ctx, res, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteMetadata implements client.API.
+func (m *API) DeleteMetadata(res client.ResourceType, uuid string, key string) error {
+	m.record("DeleteMetadata", res, uuid, key)
+	if m.DeleteMetadataFunc != nil {
+		return m.DeleteMetadataFunc(res, uuid, key)
+	}
+	return nil
+}
+
+// DeleteMetadataCtx implements client.API.
+func (m *API) DeleteMetadataCtx(ctx context.Context, res client.ResourceType, uuid string, key string) error {
+	m.record("DeleteMetadataCtx", ctx, res, uuid, key)
+	if m.DeleteMetadataCtxFunc != nil {
+		return m.DeleteMetadataCtxFunc(ctx, res, uuid, key)
+	}
+	return nil
+}
+
+// GetInstanceMetadata implements client.API.
+func (m *API) GetInstanceMetadata(uuid string) (client.Metadata, error) {
+	m.record("GetInstanceMetadata", uuid)
+	if m.GetInstanceMetadataFunc != nil {
+		return m.GetInstanceMetadataFunc(uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// GetInstanceMetadataCtx implements client.API.
+func (m *API) GetInstanceMetadataCtx(ctx context.Context, uuid string) (client.Metadata, error) {
+	m.record("GetInstanceMetadataCtx", ctx, uuid)
+	if m.GetInstanceMetadataCtxFunc != nil {
+		return m.GetInstanceMetadataCtxFunc(ctx, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// SetInstanceMetadata implements client.API.
+func (m *API) SetInstanceMetadata(uuid string, key string, value string) error {
+	m.record("SetInstanceMetadata", uuid, key, value)
+	if m.SetInstanceMetadataFunc != nil {
+		return m.SetInstanceMetadataFunc(uuid, key, value)
+	}
+	return nil
+}
+
+// SetInstanceMetadataCtx implements client.API.
+func (m *API) SetInstanceMetadataCtx(ctx context.Context, uuid string, key string, value string) error {
+	m.record("SetInstanceMetadataCtx", ctx, uuid, key, value)
+	if m.SetInstanceMetadataCtxFunc != nil {
+		return m.SetInstanceMetadataCtxFunc(ctx, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteInstanceMetadata implements client.API.
+func (m *API) DeleteInstanceMetadata(uuid string, key string) error {
+	m.record("DeleteInstanceMetadata", uuid, key)
+	if m.DeleteInstanceMetadataFunc != nil {
+		return m.DeleteInstanceMetadataFunc(uuid, key)
+	}
+	return nil
+}
+
+// DeleteInstanceMetadataCtx implements client.API.
+func (m *API) DeleteInstanceMetadataCtx(ctx context.Context, uuid string, key string) error {
+	m.record("DeleteInstanceMetadataCtx", ctx, uuid, key)
+	if m.DeleteInstanceMetadataCtxFunc != nil {
+		return m.DeleteInstanceMetadataCtxFunc(ctx, uuid, key)
+	}
+	return nil
+}
+
+// SetInstanceMetadataItem implements client.API.
+func (m *API) SetInstanceMetadataItem(uuid string, key string, value string) error {
+	m.record("SetInstanceMetadataItem", uuid, key, value)
+	if m.SetInstanceMetadataItemFunc != nil {
+		return m.SetInstanceMetadataItemFunc(uuid, key, value)
+	}
+	return nil
+}
+
+// SetInstanceMetadataItemCtx implements client.API.
+func (m *API) SetInstanceMetadataItemCtx(ctx context.Context, uuid string, key string, value string) error {
+	m.record("SetInstanceMetadataItemCtx", ctx, uuid, key, value)
+	if m.SetInstanceMetadataItemCtxFunc != nil {
+		return m.SetInstanceMetadataItemCtxFunc(ctx, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteInstanceMetadataItem implements client.API.
+func (m *API) DeleteInstanceMetadataItem(uuid string, key string) error {
+	m.record("DeleteInstanceMetadataItem", uuid, key)
+	if m.DeleteInstanceMetadataItemFunc != nil {
+		return m.DeleteInstanceMetadataItemFunc(uuid, key)
+	}
+	return nil
+}
+
+// DeleteInstanceMetadataItemCtx implements client.API.
+func (m *API) DeleteInstanceMetadataItemCtx(ctx context.Context, uuid string, key string) error {
+	m.record("DeleteInstanceMetadataItemCtx", ctx, uuid, key)
+	if m.DeleteInstanceMetadataItemCtxFunc != nil {
+		return m.DeleteInstanceMetadataItemCtxFunc(ctx, uuid, key)
+	}
+	return nil
+}
+
+// GetNetworkMetadata implements client.API.
+func (m *API) GetNetworkMetadata(uuid string) (client.Metadata, error) {
+	m.record("GetNetworkMetadata", uuid)
+	if m.GetNetworkMetadataFunc != nil {
+		return m.GetNetworkMetadataFunc(uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// GetNetworkMetadataCtx implements client.API.
+func (m *API) GetNetworkMetadataCtx(ctx context.Context, uuid string) (client.Metadata, error) {
+	m.record("GetNetworkMetadataCtx", ctx, uuid)
+	if m.GetNetworkMetadataCtxFunc != nil {
+		return m.GetNetworkMetadataCtxFunc(ctx, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// SetNetworkMetadata implements client.API.
+func (m *API) SetNetworkMetadata(uuid string, key string, value string) error {
+	m.record("SetNetworkMetadata", uuid, key, value)
+	if m.SetNetworkMetadataFunc != nil {
+		return m.SetNetworkMetadataFunc(uuid, key, value)
+	}
+	return nil
+}
+
+// SetNetworkMetadataCtx implements client.API.
+func (m *API) SetNetworkMetadataCtx(ctx context.Context, uuid string, key string, value string) error {
+	m.record("SetNetworkMetadataCtx", ctx, uuid, key, value)
+	if m.SetNetworkMetadataCtxFunc != nil {
+		return m.SetNetworkMetadataCtxFunc(ctx, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteNetworkMetadata implements client.API.
+func (m *API) DeleteNetworkMetadata(uuid string, key string) error {
+	m.record("DeleteNetworkMetadata", uuid, key)
+	if m.DeleteNetworkMetadataFunc != nil {
+		return m.DeleteNetworkMetadataFunc(uuid, key)
+	}
+	return nil
+}
+
+// DeleteNetworkMetadataCtx implements client.API.
+func (m *API) DeleteNetworkMetadataCtx(ctx context.Context, uuid string, key string) error {
+	m.record("DeleteNetworkMetadataCtx", ctx, uuid, key)
+	if m.DeleteNetworkMetadataCtxFunc != nil {
+		return m.DeleteNetworkMetadataCtxFunc(ctx, uuid, key)
+	}
+	return nil
+}
+
+// GetNamespaceMetadata implements client.API.
+func (m *API) GetNamespaceMetadata(uuid string) (client.Metadata, error) {
+	m.record("GetNamespaceMetadata", uuid)
+	if m.GetNamespaceMetadataFunc != nil {
+		return m.GetNamespaceMetadataFunc(uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// GetNamespaceMetadataCtx implements client.API.
+func (m *API) GetNamespaceMetadataCtx(ctx context.Context, uuid string) (client.Metadata, error) {
+	m.record("GetNamespaceMetadataCtx", ctx, uuid)
+	if m.GetNamespaceMetadataCtxFunc != nil {
+		return m.GetNamespaceMetadataCtxFunc(ctx, uuid)
+	}
+	var r0 client.Metadata
+	var r1 error
+	return r0, r1
+}
+
+// SetNamespaceMetadata implements client.API.
+func (m *API) SetNamespaceMetadata(uuid string, key string, value string) error {
+	m.record("SetNamespaceMetadata", uuid, key, value)
+	if m.SetNamespaceMetadataFunc != nil {
+		return m.SetNamespaceMetadataFunc(uuid, key, value)
+	}
+	return nil
+}
+
+// SetNamespaceMetadataCtx implements client.API.
+func (m *API) SetNamespaceMetadataCtx(ctx context.Context, uuid string, key string, value string) error {
+	m.record("SetNamespaceMetadataCtx", ctx, uuid, key, value)
+	if m.SetNamespaceMetadataCtxFunc != nil {
+		return m.SetNamespaceMetadataCtxFunc(ctx, uuid, key, value)
+	}
+	return nil
+}
+
+// DeleteNamespaceMetadata implements client.API.
+func (m *API) DeleteNamespaceMetadata(uuid string, key string) error {
+	m.record("DeleteNamespaceMetadata", uuid, key)
+	if m.DeleteNamespaceMetadataFunc != nil {
+		return m.DeleteNamespaceMetadataFunc(uuid, key)
+	}
+	return nil
+}
+
+// DeleteNamespaceMetadataCtx implements client.API.
+func (m *API) DeleteNamespaceMetadataCtx(ctx context.Context, uuid string, key string) error {
+	m.record("DeleteNamespaceMetadataCtx", ctx, uuid, key)
+	if m.DeleteNamespaceMetadataCtxFunc != nil {
+		return m.DeleteNamespaceMetadataCtxFunc(ctx, uuid, key)
+	}
+	return nil
+}
+
+// CacheArtifact implements client.API.
+func (m *API) CacheArtifact(image_url string) error {
+	m.record("CacheArtifact", image_url)
+	if m.CacheArtifactFunc != nil {
+		return m.CacheArtifactFunc(image_url)
+	}
+	return nil
+}
+
+// CacheArtifactCtx implements client.API.
+func (m *API) CacheArtifactCtx(ctx context.Context, image_url string) error {
+	m.record("CacheArtifactCtx", ctx, image_url)
+	if m.CacheArtifactCtxFunc != nil {
+		return m.CacheArtifactCtxFunc(ctx, image_url)
+	}
+	return nil
+}
+
+// GetArtifact implements client.API.
+func (m *API) GetArtifact(uuid string) (client.Artifact, error) {
+	m.record("GetArtifact", uuid)
+	if m.GetArtifactFunc != nil {
+		return m.GetArtifactFunc(uuid)
+	}
+	var r0 client.Artifact
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactCtx implements client.API.
+func (m *API) GetArtifactCtx(ctx context.Context, uuid string) (client.Artifact, error) {
+	m.record("GetArtifactCtx", ctx, uuid)
+	if m.GetArtifactCtxFunc != nil {
+		return m.GetArtifactCtxFunc(ctx, uuid)
+	}
+	var r0 client.Artifact
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifacts implements client.API.
+func (m *API) GetArtifacts(node string) ([]client.Artifact, error) {
+	m.record("GetArtifacts", node)
+	if m.GetArtifactsFunc != nil {
+		return m.GetArtifactsFunc(node)
+	}
+	var r0 []client.Artifact
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactsCtx implements client.API.
+func (m *API) GetArtifactsCtx(ctx context.Context, node string) ([]client.Artifact, error) {
+	m.record("GetArtifactsCtx", ctx, node)
+	if m.GetArtifactsCtxFunc != nil {
+		return m.GetArtifactsCtxFunc(ctx, node)
+	}
+	var r0 []client.Artifact
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactEvents implements client.API.
+func (m *API) GetArtifactEvents(uuid string) ([]client.Event, error) {
+	m.record("GetArtifactEvents", uuid)
+	if m.GetArtifactEventsFunc != nil {
+		return m.GetArtifactEventsFunc(uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactEventsCtx implements client.API.
+func (m *API) GetArtifactEventsCtx(ctx context.Context, uuid string) ([]client.Event, error) {
+	m.record("GetArtifactEventsCtx", ctx, uuid)
+	if m.GetArtifactEventsCtxFunc != nil {
+		return m.GetArtifactEventsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Event
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactVersions implements client.API.
+func (m *API) GetArtifactVersions(uuid string) ([]client.Blob, error) {
+	m.record("GetArtifactVersions", uuid)
+	if m.GetArtifactVersionsFunc != nil {
+		return m.GetArtifactVersionsFunc(uuid)
+	}
+	var r0 []client.Blob
+	var r1 error
+	return r0, r1
+}
+
+// GetArtifactVersionsCtx implements client.API.
+func (m *API) GetArtifactVersionsCtx(ctx context.Context, uuid string) ([]client.Blob, error) {
+	m.record("GetArtifactVersionsCtx", ctx, uuid)
+	if m.GetArtifactVersionsCtxFunc != nil {
+		return m.GetArtifactVersionsCtxFunc(ctx, uuid)
+	}
+	var r0 []client.Blob
+	var r1 error
+	return r0, r1
+}
+
+// GetBlobs implements client.API.
+func (m *API) GetBlobs(node string) ([]client.Blob, error) {
+	m.record("GetBlobs", node)
+	if m.GetBlobsFunc != nil {
+		return m.GetBlobsFunc(node)
+	}
+	var r0 []client.Blob
+	var r1 error
+	return r0, r1
+}
+
+// GetBlobsCtx implements client.API.
+func (m *API) GetBlobsCtx(ctx context.Context, node string) ([]client.Blob, error) {
+	m.record("GetBlobsCtx", ctx, node)
+	if m.GetBlobsCtxFunc != nil {
+		return m.GetBlobsCtxFunc(ctx, node)
+	}
+	var r0 []client.Blob
+	var r1 error
+	return r0, r1
+}
+
+// UpdateLabel implements client.API.
+func (m *API) UpdateLabel(labelName string, blobUUID string) error {
+	m.record("UpdateLabel", labelName, blobUUID)
+	if m.UpdateLabelFunc != nil {
+		return m.UpdateLabelFunc(labelName, blobUUID)
+	}
+	return nil
+}
+
+// UpdateLabelCtx implements client.API.
+func (m *API) UpdateLabelCtx(ctx context.Context, labelName string, blobUUID string) error {
+	m.record("UpdateLabelCtx", ctx, labelName, blobUUID)
+	if m.UpdateLabelCtxFunc != nil {
+		return m.UpdateLabelCtxFunc(ctx, labelName, blobUUID)
+	}
+	return nil
+}
+
+// CacheImage implements client.API.
+func (m *API) CacheImage(imageURL string) error {
+	m.record("CacheImage", imageURL)
+	if m.CacheImageFunc != nil {
+		return m.CacheImageFunc(imageURL)
+	}
+	return nil
+}
+
+// CacheImageCtx implements client.API.
+func (m *API) CacheImageCtx(ctx context.Context, imageURL string) error {
+	m.record("CacheImageCtx", ctx, imageURL)
+	if m.CacheImageCtxFunc != nil {
+		return m.CacheImageCtxFunc(ctx, imageURL)
+	}
+	return nil
+}
+
+// GetImageMeta implements client.API.
+func (m *API) GetImageMeta() ([]client.ImageMeta, error) {
+	m.record("GetImageMeta")
+	if m.GetImageMetaFunc != nil {
+		return m.GetImageMetaFunc()
+	}
+	var r0 []client.ImageMeta
+	var r1 error
+	return r0, r1
+}
+
+// GetImageMetaCtx implements client.API.
+func (m *API) GetImageMetaCtx(ctx context.Context) ([]client.ImageMeta, error) {
+	m.record("GetImageMetaCtx", ctx)
+	if m.GetImageMetaCtxFunc != nil {
+		return m.GetImageMetaCtxFunc(ctx)
+	}
+	var r0 []client.ImageMeta
+	var r1 error
+	return r0, r1
+}
+
+// CreateUpload implements client.API.
+func (m *API) CreateUpload() (client.Upload, error) {
+	m.record("CreateUpload")
+	if m.CreateUploadFunc != nil {
+		return m.CreateUploadFunc()
+	}
+	var r0 client.Upload
+	var r1 error
+	return r0, r1
+}
+
+// CreateUploadCtx implements client.API.
+func (m *API) CreateUploadCtx(ctx context.Context) (client.Upload, error) {
+	m.record("CreateUploadCtx", ctx)
+	if m.CreateUploadCtxFunc != nil {
+		return m.CreateUploadCtxFunc(ctx)
+	}
+	var r0 client.Upload
+	var r1 error
+	return r0, r1
+}
+
+// SendUpload implements client.API.
+func (m *API) SendUpload(uuid string, data io.ReaderAt, size int64) (int64, error) {
+	m.record("SendUpload", uuid, data, size)
+	if m.SendUploadFunc != nil {
+		return m.SendUploadFunc(uuid, data, size)
+	}
+	var r0 int64
+	var r1 error
+	return r0, r1
+}
+
+// SendUploadCtx implements client.API.
+func (m *API) SendUploadCtx(ctx context.Context, uuid string, data io.ReaderAt, size int64) (int64, error) {
+	m.record("SendUploadCtx", ctx, uuid, data, size)
+	if m.SendUploadCtxFunc != nil {
+		return m.SendUploadCtxFunc(ctx, uuid, data, size)
+	}
+	var r0 int64
+	var r1 error
+	return r0, r1
+}
+
+// SendUploadFile implements client.API.
+func (m *API) SendUploadFile(uuid string, path string) (int64, error) {
+	m.record("SendUploadFile", uuid, path)
+	if m.SendUploadFileFunc != nil {
+		return m.SendUploadFileFunc(uuid, path)
+	}
+	var r0 int64
+	var r1 error
+	return r0, r1
+}
+
+// SendUploadFileCtx implements client.API.
+func (m *API) SendUploadFileCtx(ctx context.Context, uuid string, path string) (int64, error) {
+	m.record("SendUploadFileCtx", ctx, uuid, path)
+	if m.SendUploadFileCtxFunc != nil {
+		return m.SendUploadFileCtxFunc(ctx, uuid, path)
+	}
+	var r0 int64
+	var r1 error
+	return r0, r1
+}
+
+// TruncateUpload implements client.API.
+func (m *API) TruncateUpload(uuid string, offset int64) error {
+	m.record("TruncateUpload", uuid, offset)
+	if m.TruncateUploadFunc != nil {
+		return m.TruncateUploadFunc(uuid, offset)
+	}
+	return nil
+}
+
+// TruncateUploadCtx implements client.API.
+func (m *API) TruncateUploadCtx(ctx context.Context, uuid string, offset int64) error {
+	m.record("TruncateUploadCtx", ctx, uuid, offset)
+	if m.TruncateUploadCtxFunc != nil {
+		return m.TruncateUploadCtxFunc(ctx, uuid, offset)
+	}
+	return nil
+}
+
+// WaitForDeleted implements client.API.
+func (m *API) WaitForDeleted(ctx context.Context, res client.ResourceType, uuid string) error {
+	m.record("WaitForDeleted", ctx, res, uuid)
+	if m.WaitForDeletedFunc != nil {
+		return m.WaitForDeletedFunc(ctx, res, uuid)
+	}
+	return nil
+}
+
+// WatchEvents implements client.API.
+func (m *API) WatchEvents(ctx context.Context, res client.ResourceType, uuid string, filters ...client.EventFilter) *client.EventWatch {
+	m.record("WatchEvents", ctx, res, uuid, filters)
+	if m.WatchEventsFunc != nil {
+		return m.WatchEventsFunc(ctx, res, uuid, filters...)
+	}
+	var r0 *client.EventWatch
+	return r0
+}
+
+// GetNodes implements client.API.
+func (m *API) GetNodes() ([]client.Node, error) {
+	m.record("GetNodes")
+	if m.GetNodesFunc != nil {
+		return m.GetNodesFunc()
+	}
+	var r0 []client.Node
+	var r1 error
+	return r0, r1
+}
+
+// GetNodesCtx implements client.API.
+func (m *API) GetNodesCtx(ctx context.Context) ([]client.Node, error) {
+	m.record("GetNodesCtx", ctx)
+	if m.GetNodesCtxFunc != nil {
+		return m.GetNodesCtxFunc(ctx)
+	}
+	var r0 []client.Node
+	var r1 error
+	return r0, r1
+}
+
+// GetLocks implements client.API.
+func (m *API) GetLocks() (client.Locks, error) {
+	m.record("GetLocks")
+	if m.GetLocksFunc != nil {
+		return m.GetLocksFunc()
+	}
+	var r0 client.Locks
+	var r1 error
+	return r0, r1
+}
+
+// GetLocksCtx implements client.API.
+func (m *API) GetLocksCtx(ctx context.Context) (client.Locks, error) {
+	m.record("GetLocksCtx", ctx)
+	if m.GetLocksCtxFunc != nil {
+		return m.GetLocksCtxFunc(ctx)
+	}
+	var r0 client.Locks
+	var r1 error
+	return r0, r1
+}
+
+// GetServerInfo implements client.API.
+func (m *API) GetServerInfo() (client.ServerInfo, error) {
+	m.record("GetServerInfo")
+	if m.GetServerInfoFunc != nil {
+		return m.GetServerInfoFunc()
+	}
+	var r0 client.ServerInfo
+	var r1 error
+	return r0, r1
+}
+
+// GetServerInfoCtx implements client.API.
+func (m *API) GetServerInfoCtx(ctx context.Context) (client.ServerInfo, error) {
+	m.record("GetServerInfoCtx", ctx)
+	if m.GetServerInfoCtxFunc != nil {
+		return m.GetServerInfoCtxFunc(ctx)
+	}
+	var r0 client.ServerInfo
+	var r1 error
+	return r0, r1
+}
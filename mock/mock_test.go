@@ -0,0 +1,91 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	client "github.com/shakenfist/client-go"
+)
+
+// powerOn is code under test which only needs part of the API.
+func powerOn(instances client.Instances, uuid string) error {
+	instance, err := instances.GetInstance(uuid)
+	if err != nil {
+		return err
+	}
+	if instance.PowerState == "on" {
+		return nil
+	}
+	return instances.PowerOnInstance(uuid)
+}
+
+var _ = Describe("Fakes", func() {
+	It("should call the function fields", func() {
+		instances := &Instances{
+			GetInstanceFunc: func(uuid string) (client.Instance, error) {
+				return client.Instance{UUID: uuid, PowerState: "off"}, nil
+			},
+		}
+
+		Expect(powerOn(instances, "abc")).To(Succeed())
+		Expect(instances.Calls()).To(Equal([]Call{
+			{Method: "GetInstance", Args: []interface{}{"abc"}},
+			{Method: "PowerOnInstance", Args: []interface{}{"abc"}},
+		}))
+	})
+
+	It("should return zero values for unset functions", func() {
+		fake := &API{}
+
+		instance, err := fake.GetInstanceCtx(context.Background(), "abc")
+		Expect(err).To(BeNil())
+		Expect(instance).To(Equal(client.Instance{}))
+
+		networks, err := fake.GetNetworks()
+		Expect(err).To(BeNil())
+		Expect(networks).To(BeNil())
+		Expect(fake.DeleteNetwork("abc")).To(Succeed())
+		Expect(fake.CallsTo("DeleteNetwork")).To(HaveLen(1))
+	})
+
+	It("should pass errors through", func() {
+		failure := errors.New("no capacity")
+		instances := &Instances{
+			GetInstanceFunc: func(uuid string) (client.Instance, error) {
+				return client.Instance{}, failure
+			},
+		}
+
+		Expect(powerOn(instances, "abc")).To(MatchError(failure))
+		Expect(instances.CallsTo("PowerOnInstance")).To(BeEmpty())
+	})
+
+	It("should record calls from many goroutines", func() {
+		fake := &Admin{}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fake.GetNodes()
+			}()
+		}
+		wg.Wait()
+		Expect(fake.CallsTo("GetNodes")).To(HaveLen(10))
+	})
+
+	It("should implement every interface", func() {
+		var api client.API = &API{}
+		var _ client.Instances = api
+		var _ client.Networks = &Networks{}
+		var _ client.Namespaces = &Namespaces{}
+		var _ client.MetadataStore = &MetadataStore{}
+		var _ client.Artifacts = &Artifacts{}
+		var _ client.Uploads = &Uploads{}
+		var _ client.Watcher = &Watcher{}
+		var _ client.Admin = &Admin{}
+	})
+})
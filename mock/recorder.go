@@ -0,0 +1,58 @@
+// Package mock has fakes of the client API interfaces, for testing code
+// which uses the client without an API server.
+//
+// Each fake has a function field for every method, named after the method
+// with Func appended. A method calls its field if it is set, and otherwise
+// returns zero values. Every call is recorded.
+//
+//	instances := &mock.Instances{
+//		GetInstanceFunc: func(uuid string) (client.Instance, error) {
+//			return client.Instance{UUID: uuid, State: "created"}, nil
+//		},
+//	}
+//	runDeploy(instances)
+//	calls := instances.CallsTo("GetInstance")
+//
+// The fakes are generated from api.go with "go generate".
+package mock
+
+import "sync"
+
+// Call is a call made to a fake.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Recorder records the calls made to a fake. It is safe for concurrent use.
+type Recorder struct {
+	lock  sync.Mutex
+	calls []Call
+}
+
+// record adds a call.
+func (r *Recorder) record(method string, args ...interface{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made so far, in order.
+func (r *Recorder) Calls() []Call {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Call{}, r.calls...)
+}
+
+// CallsTo returns the calls made so far to one method, in order.
+func (r *Recorder) CallsTo(method string) []Call {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var calls []Call
+	for _, c := range r.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
@@ -1,15 +1,19 @@
 package client
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 )
 
 // GetNamespaces fetches a list of all Namespaces.
 func (c *Client) GetNamespaces() ([]string, error) {
+	return c.GetNamespacesCtx(context.Background())
+}
+
+// GetNamespacesCtx is GetNamespaces with a caller supplied context.
+func (c *Client) GetNamespacesCtx(ctx context.Context) ([]string, error) {
 	namespaces := []string{}
-	err := c.doRequestJSON("auth/namespaces", "GET", bytes.Buffer{}, &namespaces)
+	err := c.doRequestJSON(ctx, "auth/namespaces", "GET", nil, &namespaces)
 	return namespaces, err
 }
 
@@ -19,18 +23,18 @@ type createNamespaceReq struct {
 
 // CreateNameSpace creates a new Namespace.
 func (c *Client) CreateNamespace(namespace string) error {
+	return c.CreateNamespaceCtx(context.Background(), namespace)
+}
+
+// CreateNamespaceCtx is CreateNamespace with a caller supplied context.
+func (c *Client) CreateNamespaceCtx(ctx context.Context, namespace string) error {
 	req := &createNamespaceReq{
 		Namespace: namespace,
 	}
 
-	post, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("cannot marshal namespace req: %v", err)
-	}
-
-	err = c.doRequestJSON("auth/namespaces", "POST", *bytes.NewBuffer(post), nil)
+	err := c.doRequestJSON(ctx, "auth/namespaces", "POST", req, nil)
 	if err != nil {
-		return fmt.Errorf("cannot create namespace: %v", err)
+		return fmt.Errorf("cannot create namespace: %w", err)
 	}
 
 	return nil
@@ -43,20 +47,22 @@ type createNamespaceKeyReq struct {
 
 // CreateNameSpaceKey creates a key within a namespace.
 func (c *Client) CreateNamespaceKey(namespace, keyName, key string) error {
+	return c.CreateNamespaceKeyCtx(context.Background(), namespace, keyName, key)
+}
+
+// CreateNamespaceKeyCtx is CreateNamespaceKey with a caller supplied context.
+func (c *Client) CreateNamespaceKeyCtx(ctx context.Context,
+	namespace, keyName, key string) error {
+
 	req := &createNamespaceKeyReq{
 		KeyName: keyName,
 		Key:     key,
 	}
 
-	post, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("cannot marshal key req: %v", err)
-	}
-
 	path := "auth/namespaces/" + namespace + "/keys"
-	err = c.doRequestJSON(path, "POST", *bytes.NewBuffer(post), nil)
+	err := c.doRequestJSON(ctx, path, "POST", req, nil)
 	if err != nil {
-		return fmt.Errorf("cannot create namespace: %v", err)
+		return fmt.Errorf("cannot create namespace: %w", err)
 	}
 
 	return nil
@@ -68,19 +74,21 @@ type updateNamespaceKeyReq struct {
 
 // UpdateNameSpaceKey will modify an existing Key within a Namespace.
 func (c *Client) UpdateNamespaceKey(namespace, keyName, key string) error {
+	return c.UpdateNamespaceKeyCtx(context.Background(), namespace, keyName, key)
+}
+
+// UpdateNamespaceKeyCtx is UpdateNamespaceKey with a caller supplied context.
+func (c *Client) UpdateNamespaceKeyCtx(ctx context.Context,
+	namespace, keyName, key string) error {
+
 	req := &updateNamespaceKeyReq{
 		Key: key,
 	}
 
-	put, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("cannot marshal key req: %v", err)
-	}
-
 	path := "auth/namespaces/" + namespace + "/keys/" + keyName
-	err = c.doRequestJSON(path, "PUT", *bytes.NewBuffer(put), nil)
+	err := c.doRequestJSON(ctx, path, "PUT", req, nil)
 	if err != nil {
-		return fmt.Errorf("cannot create namespace: %v", err)
+		return fmt.Errorf("cannot create namespace: %w", err)
 	}
 
 	return nil
@@ -88,18 +96,30 @@ func (c *Client) UpdateNamespaceKey(namespace, keyName, key string) error {
 
 // GetNameSpaceKeys retrieves a list of keys within the namespace
 func (c *Client) GetNamespaceKeys(namespace string) ([]string, error) {
+	return c.GetNamespaceKeysCtx(context.Background(), namespace)
+}
+
+// GetNamespaceKeysCtx is GetNamespaceKeys with a caller supplied context.
+func (c *Client) GetNamespaceKeysCtx(ctx context.Context,
+	namespace string) ([]string, error) {
+
 	keyNames := []string{}
-	err := c.getRequest("auth/namespaces", namespace, "keys", &keyNames)
+	err := c.getRequest(ctx, "auth/namespaces", namespace, "keys", &keyNames)
 	return keyNames, err
 }
 
 // DeleteNameSpace attempts to delete the namespace from Shaken Fist.
 func (c *Client) DeleteNamespace(namespace string) error {
+	return c.DeleteNamespaceCtx(context.Background(), namespace)
+}
+
+// DeleteNamespaceCtx is DeleteNamespace with a caller supplied context.
+func (c *Client) DeleteNamespaceCtx(ctx context.Context, namespace string) error {
 	path := "auth/namespaces/" + namespace
 
-	err := c.doRequestJSON(path, "DELETE", bytes.Buffer{}, nil)
+	err := c.doRequestJSON(ctx, path, "DELETE", nil, nil)
 	if err != nil {
-		return fmt.Errorf("unable to delete namespace: %v", err)
+		return fmt.Errorf("unable to delete namespace: %w", err)
 	}
 
 	return nil
@@ -107,11 +127,18 @@ func (c *Client) DeleteNamespace(namespace string) error {
 
 // DeleteNameSpaceKey attempts to delete the key from the specified namespace.
 func (c *Client) DeleteNamespaceKey(namespace, keyName string) error {
+	return c.DeleteNamespaceKeyCtx(context.Background(), namespace, keyName)
+}
+
+// DeleteNamespaceKeyCtx is DeleteNamespaceKey with a caller supplied context.
+func (c *Client) DeleteNamespaceKeyCtx(ctx context.Context,
+	namespace, keyName string) error {
+
 	path := "auth/namespaces/" + namespace + "/keys/" + keyName
 
-	err := c.doRequestJSON(path, "DELETE", bytes.Buffer{}, nil)
+	err := c.doRequestJSON(ctx, path, "DELETE", nil, nil)
 	if err != nil {
-		return fmt.Errorf("unable to delete key: %v", err)
+		return fmt.Errorf("unable to delete key: %w", err)
 	}
 
 	return nil
@@ -1,9 +1,7 @@
 package client
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 )
 
 // Network is a definition of a network.
@@ -16,21 +14,31 @@ type Network struct {
 	ProvideNAT      bool    `json:"provide_nat"`
 	Owner           string  `json:"owner"`
 	FloatingGateway string  `json:"floating_gateway"`
-	State           string  `json:"state"`
+	State           State   `json:"state"`
 	StateUpdated    float64 `json:"state_updated"`
 }
 
 // GetNetworks fetches a list of networks.
 func (c *Client) GetNetworks() ([]Network, error) {
+	return c.GetNetworksCtx(context.Background())
+}
+
+// GetNetworksCtx is GetNetworks with a caller supplied context.
+func (c *Client) GetNetworksCtx(ctx context.Context) ([]Network, error) {
 	networks := []Network{}
-	err := c.doRequestJSON("networks", "GET", bytes.Buffer{}, &networks)
+	err := c.doRequestJSON(ctx, "networks", "GET", nil, &networks)
 	return networks, err
 }
 
 // GetNetwork fetches a specific instance by UUID.
 func (c *Client) GetNetwork(uuid string) (Network, error) {
+	return c.GetNetworkCtx(context.Background(), uuid)
+}
+
+// GetNetworkCtx is GetNetwork with a caller supplied context.
+func (c *Client) GetNetworkCtx(ctx context.Context, uuid string) (Network, error) {
 	network := Network{}
-	err := c.doRequestJSON("networks/"+uuid, "GET", bytes.Buffer{}, &network)
+	err := c.doRequestJSON(ctx, "networks/"+uuid, "GET", nil, &network)
 	return network, err
 }
 
@@ -44,54 +52,63 @@ type createNetworkRequest struct {
 // CreateNetwork creates a new network.
 func (c *Client) CreateNetwork(netblock string, provideDHCP bool, provideNAT bool,
 	name string) (Network, error) {
+	return c.CreateNetworkCtx(context.Background(),
+		netblock, provideDHCP, provideNAT, name)
+}
+
+// CreateNetworkCtx is CreateNetwork with a caller supplied context.
+func (c *Client) CreateNetworkCtx(ctx context.Context, netblock string,
+	provideDHCP bool, provideNAT bool, name string) (Network, error) {
 	request := &createNetworkRequest{
 		Netblock:    netblock,
 		ProvideDHCP: provideDHCP,
 		ProvideNAT:  provideNAT,
 		Name:        name,
 	}
-	post, err := json.Marshal(request)
-	if err != nil {
-		return Network{}, err
-	}
-
 	network := Network{}
-	err = c.doRequestJSON("networks", "POST", *bytes.NewBuffer(post), &network)
+	err := c.doRequestJSON(ctx, "networks", "POST", request, &network)
 	return network, err
 }
 
 // DeleteNetwork removes a network with a specified UUID.
 func (c *Client) DeleteNetwork(uuid string) error {
+	return c.DeleteNetworkCtx(context.Background(), uuid)
+}
+
+// DeleteNetworkCtx is DeleteNetwork with a caller supplied context.
+func (c *Client) DeleteNetworkCtx(ctx context.Context, uuid string) error {
 	path := "networks/" + uuid
-	err := c.doRequestJSON(path, "DELETE", bytes.Buffer{}, nil)
+	err := c.doRequestJSON(ctx, path, "DELETE", nil, nil)
 	return err
 }
 
 // DeleteAllNetworks deletes all networks within a namespace. Specifying
 // namespace of "system" will attempt to delete all networks in a cluster.
 func (c *Client) DeleteAllNetworks(namespace string) ([]string, error) {
+	return c.DeleteAllNetworksCtx(context.Background(), namespace)
+}
+
+// DeleteAllNetworksCtx is DeleteAllNetworks with a caller supplied context.
+func (c *Client) DeleteAllNetworksCtx(ctx context.Context,
+	namespace string) ([]string, error) {
+
 	networks := []string{}
 
 	n := deleteAllRequest{
 		Namespace: namespace,
 		Confirm:   true,
 	}
-	req, err := json.Marshal(n)
-	if err != nil {
-		return networks, fmt.Errorf("Unable to marshal data: %v", err)
-	}
-
-	err = c.doRequestJSON("networks", "DELETE", *bytes.NewBuffer(req), &networks)
+	err := c.doRequestJSON(ctx, "networks", "DELETE", n, &networks)
 
 	return networks, err
 }
 
 // NetworkSpec is a definition of an instance network connect.
 type NetworkSpec struct {
-	NetworkUUID string `json:"network_uuid"`
-	Address     string `json:"address"`
-	MACAddress  string `json:"macaddress"`
-	Model       string `json:"model"`
+	NetworkUUID string `json:"network_uuid" yaml:"network-uuid"`
+	Address     string `json:"address" yaml:"address,omitempty"`
+	MACAddress  string `json:"macaddress" yaml:"mac-address,omitempty"`
+	Model       string `json:"model" yaml:"model,omitempty"`
 }
 
 // NetworkInterface is a definition of an network interface for an instance.
@@ -103,51 +120,89 @@ type NetworkInterface struct {
 	IPv4         string  `json:"ipv4"`
 	Order        int     `json:"order"`
 	Floating     string  `json:"floating"`
-	State        string  `json:"state"`
+	State        State   `json:"state"`
 	StateUpdated float64 `json:"state_updated"`
 	Model        string  `json:"model"`
 }
 
 // GetInstanceInterfaces fetches a list of network interfaces for an instance.
 func (c *Client) GetInstanceInterfaces(uuid string) ([]NetworkInterface, error) {
+	return c.GetInstanceInterfacesCtx(context.Background(), uuid)
+}
+
+// GetInstanceInterfacesCtx is GetInstanceInterfaces with a caller supplied
+// context.
+func (c *Client) GetInstanceInterfacesCtx(ctx context.Context,
+	uuid string) ([]NetworkInterface, error) {
+
 	path := "instances/" + uuid + "/interfaces"
 	interfaces := []NetworkInterface{}
-	err := c.doRequestJSON(path, "GET", bytes.Buffer{}, &interfaces)
+	err := c.doRequestJSON(ctx, path, "GET", nil, &interfaces)
 
 	return interfaces, err
 }
 
 // GetNetworkInterfaces fetches a list of interfaces on a network.
 func (c *Client) GetNetworkInterfaces(uuid string) ([]NetworkInterface, error) {
+	return c.GetNetworkInterfacesCtx(context.Background(), uuid)
+}
+
+// GetNetworkInterfacesCtx is GetNetworkInterfaces with a caller supplied
+// context.
+func (c *Client) GetNetworkInterfacesCtx(ctx context.Context,
+	uuid string) ([]NetworkInterface, error) {
+
 	path := "networks/" + uuid + "/interfaces"
 	interfaces := []NetworkInterface{}
-	err := c.doRequestJSON(path, "GET", bytes.Buffer{}, &interfaces)
+	err := c.doRequestJSON(ctx, path, "GET", nil, &interfaces)
 
 	return interfaces, err
 }
 
 // GetInterface fetches a specific network interface.
 func (c *Client) GetInterface(uuid string) (NetworkInterface, error) {
+	return c.GetInterfaceCtx(context.Background(), uuid)
+}
+
+// GetInterfaceCtx is GetInterface with a caller supplied context.
+func (c *Client) GetInterfaceCtx(ctx context.Context,
+	uuid string) (NetworkInterface, error) {
+
 	path := "interfaces/" + uuid
 	iface := NetworkInterface{}
-	err := c.doRequestJSON(path, "GET", bytes.Buffer{}, &iface)
+	err := c.doRequestJSON(ctx, path, "GET", nil, &iface)
 
 	return iface, err
 }
 
 // FloatInterface adds a floating IP to an interface.
 func (c *Client) FloatInterface(interfaceUUID string) error {
-	return c.postRequest("interfaces", interfaceUUID, "float")
+	return c.FloatInterfaceCtx(context.Background(), interfaceUUID)
+}
+
+// FloatInterfaceCtx is FloatInterface with a caller supplied context.
+func (c *Client) FloatInterfaceCtx(ctx context.Context, interfaceUUID string) error {
+	return c.postRequest(ctx, "interfaces", interfaceUUID, "float")
 }
 
 // DefloatInterface removes a floating IP from an interface.
 func (c *Client) DefloatInterface(interfaceUUID string) error {
-	return c.postRequest("interfaces", interfaceUUID, "defloat")
+	return c.DefloatInterfaceCtx(context.Background(), interfaceUUID)
+}
+
+// DefloatInterfaceCtx is DefloatInterface with a caller supplied context.
+func (c *Client) DefloatInterfaceCtx(ctx context.Context, interfaceUUID string) error {
+	return c.postRequest(ctx, "interfaces", interfaceUUID, "defloat")
 }
 
 // GetNetworkEvents fetches events that have occurred on a specific network.
 func (c *Client) GetNetworkEvents(uuid string) ([]Event, error) {
+	return c.GetNetworkEventsCtx(context.Background(), uuid)
+}
+
+// GetNetworkEventsCtx is GetNetworkEvents with a caller supplied context.
+func (c *Client) GetNetworkEventsCtx(ctx context.Context, uuid string) ([]Event, error) {
 	events := []Event{}
-	err := c.getRequest("networks", uuid, "events", &events)
+	err := c.getRequest(ctx, "networks", uuid, "events", &events)
 	return events, err
 }
@@ -1,7 +1,7 @@
 package client
 
 import (
-	"bytes"
+	"context"
 )
 
 // Node defines a ShakenFist node.
@@ -13,7 +13,12 @@ type Node struct {
 
 // GetNodes fetches a list of nodes.
 func (c *Client) GetNodes() ([]Node, error) {
+	return c.GetNodesCtx(context.Background())
+}
+
+// GetNodesCtx is GetNodes with a caller supplied context.
+func (c *Client) GetNodesCtx(ctx context.Context) ([]Node, error) {
 	nodes := []Node{}
-	err := c.doRequestJSON("nodes", "GET", bytes.Buffer{}, &nodes)
+	err := c.doRequestJSON(ctx, "nodes", "GET", nil, &nodes)
 	return nodes, err
 }
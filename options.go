@@ -0,0 +1,231 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is sent with every request unless WithUserAgent is used.
+const DefaultUserAgent = "shakenfist-client-go"
+
+// Option configures a Client created by NewClientWithOptions.
+type Option func(*Client) error
+
+// Logger receives diagnostic messages from the client. *log.Logger
+// satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// NewClientWithOptions returns a Shaken Fist client configured by opts.
+//
+// The server_url string should be the base URL of the server including
+// the port number, as for NewClient.
+func NewClientWithOptions(server_url string, namespace, apiKey string,
+	opts ...Option) (*Client, error) {
+
+	c := &Client{
+		server_url:  strings.TrimSuffix(server_url, "/"),
+		httpClient:  &http.Client{},
+		namespace:   namespace,
+		apiKey:      apiKey,
+		retryPolicy: DefaultRetryPolicy(),
+		userAgent:   DefaultUserAgent,
+		headers:     http.Header{},
+
+		endpointCoolDown: DefaultEndpointCoolDown,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	urls, sockets, err := unixEndpoints(
+		append([]string{c.server_url}, c.extraEndpoints...))
+	if err != nil {
+		return nil, err
+	}
+	c.sockets = sockets
+	c.endpoints = newEndpointPool(urls, c.endpointStrategy, c.endpointCoolDown)
+	c.throttle = newThrottle(c.globalLimit, c.routeLimits)
+
+	if err := c.configureTransport(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// WithHTTPClient makes the client send requests using a copy of hc.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) error {
+		if hc == nil {
+			return errors.New("http client must not be nil")
+		}
+		copied := *hc
+		c.httpClient = &copied
+		return nil
+	}
+}
+
+// WithTransport sets the RoundTripper used to send requests.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) error {
+		c.transport = rt
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithHeader adds a header which is sent with every request.
+func WithHeader(key, value string) Option {
+	return func(c *Client) error {
+		c.headers.Add(key, value)
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout for each HTTP request. Zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		c.timeout = &timeout
+		return nil
+	}
+}
+
+// WithRetryPolicy replaces the default retry policy. A nil policy disables
+// retries.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Client) error {
+		c.retryPolicy = policy
+		return nil
+	}
+}
+
+// WithLogger sets a logger for diagnostic messages such as retries.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) error {
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the server.
+// It cannot be combined with a transport which is not an *http.Transport.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) error {
+		c.tlsConfig = config
+		return nil
+	}
+}
+
+// WithBasePath sets a path prefix which is added to every API path, for
+// servers which publish the API below the root of the URL.
+func WithBasePath(prefix string) Option {
+	return func(c *Client) error {
+		c.basePath = strings.Trim(prefix, "/")
+		return nil
+	}
+}
+
+// WithEndpoints adds further API servers for the same cluster. Requests are
+// spread over them and fail over between them as described by the
+// endpoint strategy.
+func WithEndpoints(urls ...string) Option {
+	return func(c *Client) error {
+		c.extraEndpoints = append(c.extraEndpoints, urls...)
+		return nil
+	}
+}
+
+// WithEndpointStrategy sets how requests are spread over the API servers.
+// The default is RoundRobin.
+func WithEndpointStrategy(strategy EndpointStrategy) Option {
+	return func(c *Client) error {
+		c.endpointStrategy = strategy
+		return nil
+	}
+}
+
+// WithEndpointCoolDown sets how long an API server which failed is avoided.
+func WithEndpointCoolDown(coolDown time.Duration) Option {
+	return func(c *Client) error {
+		c.endpointCoolDown = coolDown
+		return nil
+	}
+}
+
+// configureTransport applies the transport related options to the
+// http.Client once all options have been processed.
+func (c *Client) configureTransport() error {
+	if c.transport != nil {
+		c.httpClient.Transport = c.transport
+	}
+	if c.timeout != nil {
+		c.httpClient.Timeout = *c.timeout
+	}
+
+	if c.tlsConfig == nil && c.tls.empty() && c.proxy == nil &&
+		len(c.sockets) == 0 {
+		return nil
+	}
+
+	t, err := c.cloneTransport()
+	if err != nil {
+		return err
+	}
+
+	if c.tlsConfig != nil || !c.tls.empty() {
+		config := c.tlsConfig
+		if !c.tls.empty() {
+			if config, err = c.tls.apply(config); err != nil {
+				return fmt.Errorf("invalid TLS settings: %w", err)
+			}
+		}
+		t.TLSClientConfig = config
+	}
+	c.applyProxy(t)
+
+	c.httpClient.Transport = t
+	return nil
+}
+
+// cloneTransport returns a copy of the current *http.Transport which can be
+// modified without affecting anyone else using it.
+func (c *Client) cloneTransport() (*http.Transport, error) {
+	rt := c.httpClient.Transport
+	if rt == nil {
+		// http.DefaultTransport may have been replaced, for example by a
+		// mocking library, in which case start from a fresh transport.
+		if t, ok := http.DefaultTransport.(*http.Transport); ok {
+			return t.Clone(), nil
+		}
+		return &http.Transport{Proxy: http.ProxyFromEnvironment}, nil
+	}
+
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, errors.New("transport options require an *http.Transport")
+	}
+	return t.Clone(), nil
+}
+
+// logf sends a message to the logger, if one has been configured.
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+	}
+}
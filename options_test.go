@@ -0,0 +1,127 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client options", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	authResponder := httpmock.NewBytesResponder(200,
+		[]byte(`{"access_token":"ABC123"}`))
+
+	It("should keep NewClient defaults", func() {
+		client := NewClient(test_url, test_namespace, test_key)
+		Expect(client.userAgent).To(Equal(DefaultUserAgent))
+		Expect(client.retryPolicy).ToNot(BeNil())
+		Expect(client.httpClient.Transport).To(BeNil())
+	})
+
+	It("should send requests through a custom transport", func() {
+		transport := httpmock.NewMockTransport()
+		transport.RegisterResponder("POST", test_url+"/auth", authResponder)
+		transport.RegisterResponder("GET", test_url+"/nodes",
+			httpmock.NewStringResponder(200, `[]`))
+
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithTransport(transport))
+		Expect(err).To(BeNil())
+
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+
+		Expect(transport.GetCallCountInfo()["GET "+test_url+"/nodes"]).To(Equal(1))
+		Expect(httpmock.GetTotalCallCount()).To(Equal(0))
+	})
+
+	It("should copy a custom http.Client rather than modify it", func() {
+		hc := &http.Client{Timeout: time.Minute}
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithHTTPClient(hc), WithTimeout(5*time.Second))
+		Expect(err).To(BeNil())
+
+		Expect(client.httpClient.Timeout).To(Equal(5 * time.Second))
+		Expect(hc.Timeout).To(Equal(time.Minute))
+	})
+
+	It("should send the user agent and default headers", func() {
+		httpmock.RegisterResponder("POST", test_url+"/auth", authResponder)
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			func(req *http.Request) (*http.Response, error) {
+				Expect(req.Header.Get("User-Agent")).To(Equal("robot/1.0"))
+				Expect(req.Header.Get("X-Team")).To(Equal("infra"))
+				Expect(req.Header.Get("Authorization")).To(Equal("Bearer ABC123"))
+				return httpmock.NewStringResponse(200, `[]`), nil
+			})
+
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithUserAgent("robot/1.0"), WithHeader("X-Team", "infra"))
+		Expect(err).To(BeNil())
+
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+	})
+
+	It("should prefix paths with the base path", func() {
+		httpmock.RegisterResponder("POST", test_url+"/api/v1/auth", authResponder)
+		httpmock.RegisterResponder("GET", test_url+"/api/v1/nodes",
+			httpmock.NewStringResponder(200, `[]`))
+
+		client, err := NewClientWithOptions(test_url+"/", test_namespace,
+			test_key, WithBasePath("/api/v1/"))
+		Expect(err).To(BeNil())
+
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["GET "+test_url+"/api/v1/nodes"]).To(Equal(1))
+	})
+
+	It("should set the retry policy and logger", func() {
+		httpmock.RegisterResponder("POST", test_url+"/auth", authResponder)
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			httpmock.NewStringResponder(503, "busy"))
+
+		policy := &RetryPolicy{MaxAttempts: 2, RetryableStatusCodes: []int{503}}
+		out := &bytes.Buffer{}
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithRetryPolicy(policy), WithLogger(log.New(out, "", 0)))
+		Expect(err).To(BeNil())
+		Expect(client.retryPolicy).To(BeIdenticalTo(policy))
+
+		_, err = client.GetNodes()
+		Expect(err).ToNot(BeNil())
+		Expect(out.String()).To(ContainSubstring("retrying GET nodes"))
+	})
+
+	It("should apply a TLS configuration to a fresh transport", func() {
+		config := &tls.Config{ServerName: "sf.example.com"}
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithTLSConfig(config))
+		Expect(err).To(BeNil())
+
+		t, ok := client.httpClient.Transport.(*http.Transport)
+		Expect(ok).To(BeTrue())
+		Expect(t.TLSClientConfig).To(BeIdenticalTo(config))
+	})
+
+	It("should reject a TLS configuration for a custom round tripper", func() {
+		_, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithTransport(httpmock.NewMockTransport()),
+			WithTLSConfig(&tls.Config{}))
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,281 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EnvProfiles names the environment variable holding a list of profile
+// files to merge, separated like PATH.
+const EnvProfiles = "SHAKENFIST_PROFILES"
+
+// DefaultProfilePath is the profile file used when EnvProfiles is not set.
+const DefaultProfilePath = "~/.config/shakenfist/profiles.yaml"
+
+// Profiles describe a set of Shaken Fist clusters, the credentials used to
+// access them and named contexts pairing the two. The file format is YAML
+// (or JSON), modelled on kubeconfig:
+//
+//	current-context: dev
+//	clusters:
+//	  - name: dev
+//	    server: https://sf-dev:13000
+//	    tls:
+//	      ca-file: ~/.config/shakenfist/dev-ca.pem
+//	credentials:
+//	  - name: dev-admin
+//	    namespace: system
+//	    key: secret
+//	contexts:
+//	  - name: dev
+//	    cluster: dev
+//	    credential: dev-admin
+type Profiles struct {
+	CurrentContext string              `yaml:"current-context"`
+	Clusters       []ClusterProfile    `yaml:"clusters"`
+	Credentials    []CredentialProfile `yaml:"credentials"`
+	Contexts       []ContextProfile    `yaml:"contexts"`
+
+	// path is the file changes to the current context are saved to
+	path string
+}
+
+// ClusterProfile is a named Shaken Fist API server.
+type ClusterProfile struct {
+	Name   string       `yaml:"name"`
+	Server string       `yaml:"server"`
+	TLS    *TLSSettings `yaml:"tls,omitempty"`
+}
+
+// CredentialProfile is a named set of credentials for a namespace.
+type CredentialProfile struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+	Key       string `yaml:"key,omitempty"`
+	Token     string `yaml:"token,omitempty"`
+}
+
+// ContextProfile pairs a cluster with the credentials used to access it.
+type ContextProfile struct {
+	Name       string `yaml:"name"`
+	Cluster    string `yaml:"cluster"`
+	Credential string `yaml:"credential"`
+}
+
+// LoadProfileFile reads profiles from a single file.
+func LoadProfileFile(path string) (*Profiles, error) {
+	expanded, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read profiles: %w", err)
+	}
+
+	p := &Profiles{}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("cannot parse profiles %s: %w", expanded, err)
+	}
+	p.path = expanded
+
+	return p, nil
+}
+
+// LoadProfileFiles reads and merges profiles from several files. As with
+// kubeconfig, the first file to define a name wins, and the current
+// context comes from the first file which sets one. Missing files are
+// skipped.
+func LoadProfileFiles(paths ...string) (*Profiles, error) {
+	merged := &Profiles{}
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		p, err := LoadProfileFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		merged.merge(p)
+	}
+
+	if merged.path == "" {
+		return nil, fmt.Errorf("no profile files found in %s",
+			strings.Join(paths, string(os.PathListSeparator)))
+	}
+	return merged, nil
+}
+
+// LoadProfiles reads the profile files listed in SHAKENFIST_PROFILES, or
+// DefaultProfilePath if it is not set.
+func LoadProfiles() (*Profiles, error) {
+	if env := os.Getenv(EnvProfiles); env != "" {
+		return LoadProfileFiles(filepath.SplitList(env)...)
+	}
+	return LoadProfileFiles(DefaultProfilePath)
+}
+
+// merge adds the entries of other which are not already defined.
+func (p *Profiles) merge(other *Profiles) {
+	if p.path == "" {
+		p.path = other.path
+	}
+	if p.CurrentContext == "" {
+		p.CurrentContext = other.CurrentContext
+	}
+
+	for _, c := range other.Clusters {
+		if _, ok := p.cluster(c.Name); !ok {
+			p.Clusters = append(p.Clusters, c)
+		}
+	}
+	for _, c := range other.Credentials {
+		if _, ok := p.credential(c.Name); !ok {
+			p.Credentials = append(p.Credentials, c)
+		}
+	}
+	for _, c := range other.Contexts {
+		if _, ok := p.context(c.Name); !ok {
+			p.Contexts = append(p.Contexts, c)
+		}
+	}
+}
+
+func (p *Profiles) cluster(name string) (ClusterProfile, bool) {
+	for _, c := range p.Clusters {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ClusterProfile{}, false
+}
+
+func (p *Profiles) credential(name string) (CredentialProfile, bool) {
+	for _, c := range p.Credentials {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return CredentialProfile{}, false
+}
+
+func (p *Profiles) context(name string) (ContextProfile, bool) {
+	for _, c := range p.Contexts {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ContextProfile{}, false
+}
+
+// ContextNames returns the names of all contexts, sorted.
+func (p *Profiles) ContextNames() []string {
+	names := []string{}
+	for _, c := range p.Contexts {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseContext makes name the current context. Call SaveCurrentContext to
+// persist the change.
+func (p *Profiles) UseContext(name string) error {
+	if _, ok := p.context(name); !ok {
+		return fmt.Errorf("context %q not found", name)
+	}
+	p.CurrentContext = name
+	return nil
+}
+
+// SaveCurrentContext writes the current context to the first profile file
+// loaded. The file is parsed and written out again, so its other settings
+// and their order are kept but comments and formatting are not.
+func (p *Profiles) SaveCurrentContext() error {
+	if p.path == "" {
+		return fmt.Errorf("profiles were not loaded from a file")
+	}
+
+	data, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("cannot read profiles: %w", err)
+	}
+	raw := yaml.MapSlice{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("cannot parse profiles %s: %w", p.path, err)
+	}
+
+	found := false
+	for i := range raw {
+		if raw[i].Key == "current-context" {
+			raw[i].Value = p.CurrentContext
+			found = true
+		}
+	}
+	if !found {
+		raw = append(yaml.MapSlice{{Key: "current-context",
+			Value: p.CurrentContext}}, raw...)
+	}
+
+	out, err := yaml.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("cannot marshal profiles: %w", err)
+	}
+	return ioutil.WriteFile(p.path, out, 0600)
+}
+
+// Provider returns a CredentialProvider for the named context. An empty
+// name selects the current context.
+func (p *Profiles) Provider(name string) (CredentialProvider, error) {
+	if name == "" {
+		name = p.CurrentContext
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no context given and no current context set")
+	}
+
+	ctx, ok := p.context(name)
+	if !ok {
+		return nil, fmt.Errorf("context %q not found", name)
+	}
+	cluster, ok := p.cluster(ctx.Cluster)
+	if !ok {
+		return nil, fmt.Errorf("context %q refers to unknown cluster %q",
+			name, ctx.Cluster)
+	}
+	cred, ok := p.credential(ctx.Credential)
+	if !ok {
+		return nil, fmt.Errorf("context %q refers to unknown credential %q",
+			name, ctx.Credential)
+	}
+
+	return StaticProvider{
+		APIURL:    cluster.Server,
+		Namespace: cred.Namespace,
+		Key:       cred.Key,
+		Token:     cred.Token,
+		TLS:       cluster.TLS,
+	}, nil
+}
+
+// NewClient returns a client for the named context. An empty name selects
+// the current context.
+func (p *Profiles) NewClient(name string, opts ...Option) (*Client, error) {
+	provider, err := p.Provider(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromProvider(provider, opts...)
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cluster profiles", func() {
+	const devProfiles = `
+current-context: dev
+clusters:
+  - name: dev
+    server: http://sf-dev:13000
+  - name: prod
+    server: http://sf-prod:13000
+credentials:
+  - name: dev-admin
+    namespace: system
+    key: devkey
+  - name: prod-team
+    namespace: team
+    key: prodkey
+contexts:
+  - name: dev
+    cluster: dev
+    credential: dev-admin
+  - name: prod
+    cluster: prod
+    credential: prod-team
+`
+
+	const stagingProfiles = `
+current-context: staging
+clusters:
+  - name: staging
+    server: http://sf-staging:13000
+  - name: prod
+    server: http://elsewhere:13000
+credentials:
+  - name: staging-admin
+    namespace: system
+    token: XYZ
+contexts:
+  - name: staging
+    cluster: staging
+    credential: staging-admin
+`
+
+	var (
+		tmpDir string
+		oldEnv string
+	)
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "sfprofiles")
+		Expect(err).To(BeNil())
+		oldEnv = os.Getenv(EnvProfiles)
+	})
+
+	AfterEach(func() {
+		os.Setenv(EnvProfiles, oldEnv)
+		os.RemoveAll(tmpDir)
+	})
+
+	It("should create a client for a named context", func() {
+		p, err := LoadProfileFile(writeFile("dev.yaml", devProfiles))
+		Expect(err).To(BeNil())
+
+		client, err := p.NewClient("prod")
+		Expect(err).To(BeNil())
+		Expect(client.server_url).To(Equal("http://sf-prod:13000"))
+		Expect(client.namespace).To(Equal("team"))
+		Expect(client.apiKey).To(Equal("prodkey"))
+
+		client, err = p.NewClient("")
+		Expect(err).To(BeNil())
+		Expect(client.server_url).To(Equal("http://sf-dev:13000"))
+	})
+
+	It("should list and switch contexts", func() {
+		path := writeFile("dev.yaml", devProfiles)
+		p, err := LoadProfileFile(path)
+		Expect(err).To(BeNil())
+
+		Expect(p.ContextNames()).To(Equal([]string{"dev", "prod"}))
+		Expect(p.UseContext("missing")).ToNot(Succeed())
+		Expect(p.UseContext("prod")).To(Succeed())
+		Expect(p.SaveCurrentContext()).To(Succeed())
+
+		reloaded, err := LoadProfileFile(path)
+		Expect(err).To(BeNil())
+		Expect(reloaded.CurrentContext).To(Equal("prod"))
+		Expect(reloaded.Clusters).To(Equal(p.Clusters))
+	})
+
+	It("should report broken references", func() {
+		p, err := LoadProfileFile(writeFile("bad.yaml", `
+contexts:
+  - name: broken
+    cluster: nowhere
+    credential: nobody
+`))
+		Expect(err).To(BeNil())
+
+		_, err = p.NewClient("broken")
+		Expect(err).To(MatchError(ContainSubstring("unknown cluster")))
+		_, err = p.NewClient("")
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("should merge files from the environment with the first winning", func() {
+		dev := writeFile("dev.yaml", devProfiles)
+		staging := writeFile("staging.yaml", stagingProfiles)
+		missing := filepath.Join(tmpDir, "missing.yaml")
+		os.Setenv(EnvProfiles, strings.Join([]string{missing, dev, staging},
+			string(os.PathListSeparator)))
+
+		p, err := LoadProfiles()
+		Expect(err).To(BeNil())
+		Expect(p.CurrentContext).To(Equal("dev"))
+		Expect(p.ContextNames()).To(Equal([]string{"dev", "prod", "staging"}))
+
+		prod, ok := p.cluster("prod")
+		Expect(ok).To(BeTrue())
+		Expect(prod.Server).To(Equal("http://sf-prod:13000"))
+
+		client, err := p.NewClient("staging")
+		Expect(err).To(BeNil())
+		Expect(client.cachedAuth).To(Equal("Bearer XYZ"))
+
+		// Switching context is saved to the first file which exists
+		Expect(p.UseContext("staging")).To(Succeed())
+		Expect(p.SaveCurrentContext()).To(Succeed())
+		reloaded, err := LoadProfileFile(dev)
+		Expect(err).To(BeNil())
+		Expect(reloaded.CurrentContext).To(Equal("staging"))
+		Expect(reloaded.ContextNames()).To(Equal([]string{"dev", "prod"}))
+	})
+
+	It("should fail when no profile files exist", func() {
+		_, err := LoadProfileFiles(filepath.Join(tmpDir, "missing.yaml"))
+		Expect(err).ToNot(BeNil())
+	})
+})
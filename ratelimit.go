@@ -0,0 +1,282 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// RouteClass groups API requests for rate limiting.
+type RouteClass int
+
+const (
+	// ReadRoutes are GET, HEAD and OPTIONS requests
+	ReadRoutes RouteClass = iota
+
+	// MutationRoutes are requests which change something
+	MutationRoutes
+
+	// AuthRoutes are requests for auth tokens
+	AuthRoutes
+)
+
+func (r RouteClass) String() string {
+	return [...]string{"reads", "mutations", "auth"}[r]
+}
+
+// routeClass returns the class of a request.
+func routeClass(method, path string) RouteClass {
+	switch {
+	case path == "auth":
+		return AuthRoutes
+	case method == "GET" || method == "HEAD" || method == "OPTIONS":
+		return ReadRoutes
+	default:
+		return MutationRoutes
+	}
+}
+
+// Limit describes how hard the client may use the API server.
+type Limit struct {
+	// Rate is the sustained number of requests per second. Zero means no
+	// limit.
+	Rate float64
+
+	// Burst is how many requests may be sent at once before Rate applies.
+	// It is at least one.
+	Burst int
+
+	// MaxInFlight is the most requests which may be in progress at once,
+	// including reading the response. Zero means no limit.
+	MaxInFlight int
+}
+
+// ThrottleStats describe how much requests have been held back by the
+// client's limits.
+type ThrottleStats struct {
+	// Requests is the number of requests which passed through the limits
+	Requests int64
+
+	// Throttled is the number of requests which had to wait
+	Throttled int64
+
+	// Waited is the total time requests spent waiting
+	Waited time.Duration
+}
+
+// WithRateLimit limits all requests made by the client. Every retry and
+// failover attempt counts as a request.
+func WithRateLimit(limit Limit) Option {
+	return func(c *Client) error {
+		c.globalLimit = &limit
+		return nil
+	}
+}
+
+// WithRouteLimit limits one class of requests. It applies as well as any
+// limit set with WithRateLimit.
+func WithRouteLimit(class RouteClass, limit Limit) Option {
+	return func(c *Client) error {
+		if c.routeLimits == nil {
+			c.routeLimits = map[RouteClass]Limit{}
+		}
+		c.routeLimits[class] = limit
+		return nil
+	}
+}
+
+// ThrottleStats returns, for each class of request, how much requests have
+// been held back by the client's limits.
+func (c *Client) ThrottleStats() map[RouteClass]ThrottleStats {
+	stats := map[RouteClass]ThrottleStats{}
+	if c.throttle == nil {
+		return stats
+	}
+
+	c.throttle.lock.Lock()
+	defer c.throttle.lock.Unlock()
+	for class, s := range c.throttle.stats {
+		stats[class] = *s
+	}
+	return stats
+}
+
+// throttle applies the global and per class limits.
+type throttle struct {
+	global  *limiter
+	classes map[RouteClass]*limiter
+
+	lock  sync.Mutex
+	stats map[RouteClass]*ThrottleStats
+}
+
+// newThrottle returns a throttle, or nil if there are no limits.
+func newThrottle(global *Limit, classes map[RouteClass]Limit) *throttle {
+	if global == nil && len(classes) == 0 {
+		return nil
+	}
+
+	t := &throttle{
+		classes: map[RouteClass]*limiter{},
+		stats:   map[RouteClass]*ThrottleStats{},
+	}
+	if global != nil {
+		t.global = newLimiter(*global)
+	}
+	for class, limit := range classes {
+		t.classes[class] = newLimiter(limit)
+	}
+	return t
+}
+
+// wait blocks until a request of class may be sent, or ctx is done. The
+// returned function must be called once the request has finished.
+func (t *throttle) wait(ctx context.Context, class RouteClass) (func(), error) {
+	if t == nil {
+		return func() {}, nil
+	}
+
+	start := time.Now()
+	releaseGlobal, blockedGlobal, err := t.global.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	releaseClass, blockedClass, err := t.classes[class].wait(ctx)
+	if err != nil {
+		releaseGlobal()
+		return nil, err
+	}
+
+	t.lock.Lock()
+	s, ok := t.stats[class]
+	if !ok {
+		s = &ThrottleStats{}
+		t.stats[class] = s
+	}
+	s.Requests++
+	if blockedGlobal || blockedClass {
+		s.Throttled++
+		s.Waited += time.Since(start)
+	}
+	t.lock.Unlock()
+
+	return func() {
+		releaseClass()
+		releaseGlobal()
+	}, nil
+}
+
+// limiter is a token bucket and a cap on requests in flight.
+type limiter struct {
+	slots chan struct{}
+
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(limit Limit) *limiter {
+	l := &limiter{rate: limit.Rate, burst: float64(limit.Burst)}
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	l.tokens = l.burst
+	l.last = time.Now()
+
+	if limit.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limit.MaxInFlight)
+	}
+	return l
+}
+
+// wait blocks until a request may be sent, reporting whether it had to
+// wait. A nil limiter never blocks.
+func (l *limiter) wait(ctx context.Context) (func(), bool, error) {
+	if l == nil {
+		return func() {}, false, nil
+	}
+
+	blocked := false
+	release := func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			blocked = true
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, true, fmt.Errorf("waiting for a request slot: %w",
+					ctx.Err())
+			}
+		}
+		release = func() { <-l.slots }
+	}
+
+	if delay := l.reserve(); delay > 0 {
+		blocked = true
+		if err := sleepCtx(ctx, delay); err != nil {
+			l.unreserve()
+			release()
+			return nil, true, fmt.Errorf("waiting for rate limit: %w", err)
+		}
+	}
+
+	return release, blocked, nil
+}
+
+// reserve takes a token from the bucket, returning how long to wait before
+// it may be used.
+func (l *limiter) reserve() time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// unreserve returns a token which was not used.
+func (l *limiter) unreserve() {
+	if l.rate <= 0 {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// releasingBody calls release when a response body is closed, so that the
+// request counts as in flight until the caller has finished reading it.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rate limits", func() {
+	var (
+		server *httptest.Server
+
+		lock     sync.Mutex
+		inFlight int
+		peak     int
+		delay    time.Duration
+	)
+
+	BeforeEach(func() {
+		inFlight, peak, delay = 0, 0, 0
+		server = httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				inFlight++
+				if inFlight > peak {
+					peak = inFlight
+				}
+				wait := delay
+				lock.Unlock()
+
+				time.Sleep(wait)
+				tlsHandler.ServeHTTP(w, r)
+
+				lock.Lock()
+				inFlight--
+				lock.Unlock()
+			}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func(opts ...Option) *Client {
+		client, err := newTestClient(server.URL, opts...)
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	getNodes := func(client *Client, n int) {
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := client.GetNodes()
+				Expect(err).To(BeNil())
+			}()
+		}
+		wg.Wait()
+	}
+
+	getNode := func(client *Client) error {
+		_, err := client.GetNodes()
+		return err
+	}
+
+	It("should classify routes", func() {
+		Expect(routeClass("GET", "instances")).To(Equal(ReadRoutes))
+		Expect(routeClass("POST", "instances")).To(Equal(MutationRoutes))
+		Expect(routeClass("DELETE", "instances/1")).To(Equal(MutationRoutes))
+		Expect(routeClass("POST", "auth")).To(Equal(AuthRoutes))
+		Expect(AuthRoutes.String()).To(Equal("auth"))
+	})
+
+	It("should cap requests in flight", func() {
+		delay = 20 * time.Millisecond
+		client := newClient(WithRateLimit(Limit{MaxInFlight: 2}))
+		Expect(getNode(client)).To(Succeed())
+
+		getNodes(client, 10)
+		Expect(peak).To(Equal(2))
+
+		stats := client.ThrottleStats()
+		Expect(stats[ReadRoutes].Requests).To(Equal(int64(11)))
+		Expect(stats[ReadRoutes].Throttled).To(BeNumerically(">", 0))
+		Expect(stats[ReadRoutes].Waited).To(BeNumerically(">", 0))
+		Expect(stats[AuthRoutes].Requests).To(Equal(int64(1)))
+		Expect(stats[AuthRoutes].Throttled).To(Equal(int64(0)))
+	})
+
+	It("should limit the request rate of a route class", func() {
+		client := newClient(WithRouteLimit(ReadRoutes, Limit{Rate: 50, Burst: 2}))
+		Expect(getNode(client)).To(Succeed())
+
+		start := time.Now()
+		getNodes(client, 6)
+
+		// One token is left from the burst, the other five arrive 20ms apart
+		Expect(time.Since(start)).To(BeNumerically(">=", 90*time.Millisecond))
+		Expect(client.ThrottleStats()[ReadRoutes].Throttled).To(
+			BeNumerically(">=", 5))
+
+		// Auth is not a read, so it is not limited
+		Expect(client.ThrottleStats()[AuthRoutes].Throttled).To(Equal(int64(0)))
+	})
+
+	It("should stop waiting when the context is done", func() {
+		// Enough burst to authenticate and fetch nodes once
+		client := newClient(WithRateLimit(Limit{Rate: 0.1, Burst: 2}))
+		Expect(getNode(client)).To(Succeed())
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			50*time.Millisecond)
+		defer cancel()
+		_, err := client.GetNodesCtx(ctx)
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("waiting for rate limit")))
+	})
+
+	It("should hold a slot until the response is read", func() {
+		client := newClient(WithRateLimit(Limit{MaxInFlight: 1}))
+		Expect(getNode(client)).To(Succeed())
+
+		release, err := client.throttle.wait(context.Background(), ReadRoutes)
+		Expect(err).To(BeNil())
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			50*time.Millisecond)
+		defer cancel()
+		_, err = client.GetNodesCtx(ctx)
+		Expect(err).To(MatchError(ContainSubstring("waiting for a request slot")))
+
+		release()
+		Expect(getNode(client)).To(Succeed())
+	})
+})
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// support records what is known about whether the server has a feature.
+type support int
+
+const (
+	supportUnknown support = iota
+	supportYes
+	supportNo
+)
+
+// apiRequest describes a call to the API. Methods build one up and pass it
+// to send or sendJSON.
+type apiRequest struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+
+	// fallback is sent as a JSON body in place of the query parameters to
+	// servers which only read arguments from the body
+	fallback interface{}
+}
+
+// newAPIRequest returns a request for method on an API path.
+func newAPIRequest(method, path string) *apiRequest {
+	return &apiRequest{method: method, path: path, query: url.Values{}}
+}
+
+// param adds a query parameter.
+func (r *apiRequest) param(key, value string) *apiRequest {
+	r.query.Add(key, value)
+	return r
+}
+
+// withBody sets the request body, which is a *requestBody or a value to
+// send as JSON.
+func (r *apiRequest) withBody(v interface{}) *apiRequest {
+	r.body = v
+	return r
+}
+
+// withFallback sets the JSON body which replaces the query parameters for
+// servers which do not read them.
+func (r *apiRequest) withFallback(v interface{}) *apiRequest {
+	r.fallback = v
+	return r
+}
+
+// target returns the path including any query parameters.
+func (r *apiRequest) target() string {
+	if len(r.query) == 0 {
+		return r.path
+	}
+	return r.path + "?" + r.query.Encode()
+}
+
+// WithQueryParameters says whether the server reads GET arguments from
+// query parameters. By default the client sends query parameters only to
+// servers which list CapabilityQueryParameters, and a JSON body to others.
+func WithQueryParameters(supported bool) Option {
+	return func(c *Client) error {
+		if supported {
+			c.queryParams = supportYes
+		} else {
+			c.queryParams = supportNo
+		}
+		return nil
+	}
+}
+
+// useQuery reports whether to send the arguments of a request as query
+// parameters rather than in a JSON body. Unless WithQueryParameters was
+// given, this is decided by the capabilities of the server. A server whose
+// capabilities cannot be found is sent the body form, which every release
+// reads.
+func (c *Client) useQuery(ctx context.Context) bool {
+	c.capLock.Lock()
+	s := c.queryParams
+	c.capLock.Unlock()
+	if s != supportUnknown {
+		return s == supportYes
+	}
+
+	info, err := c.lazyServerInfo(ctx)
+	if err != nil {
+		return false
+	}
+	return info.Supports(CapabilityQueryParameters)
+}
+
+// send makes the request described by r. A request with a fallback body is
+// sent in that form to servers which do not read query parameters.
+func (c *Client) send(ctx context.Context, r *apiRequest) (io.ReadCloser, error) {
+	if r.fallback != nil && !c.useQuery(ctx) {
+		return c.sendBody(ctx, r.method, r.path, r.fallback)
+	}
+	return c.sendBody(ctx, r.method, r.target(), r.body)
+}
+
+// sendBody sends data, if it is not nil, as the request body. A
+// *requestBody is sent as it is, and anything else is encoded as JSON.
+func (c *Client) sendBody(ctx context.Context, method, path string,
+	data interface{}) (io.ReadCloser, error) {
+
+	var body *requestBody
+	switch d := data.(type) {
+	case nil:
+	case *requestBody:
+		body = d
+	default:
+		var err error
+		if body, err = jsonBody(d); err != nil {
+			return nil, err
+		}
+	}
+	return c.doRequest(ctx, path, method, body)
+}
+
+// sendJSON makes the request described by r and decodes the JSON response
+// into resp, if it is not nil.
+func (c *Client) sendJSON(ctx context.Context, r *apiRequest,
+	resp interface{}) error {
+
+	if c.cache != nil && r.method == "GET" {
+		return c.cachedJSON(ctx, r, resp)
+	}
+	return c.fetchJSON(ctx, r, resp)
+}
+
+// fetchJSON is sendJSON without the response cache.
+func (c *Client) fetchJSON(ctx context.Context, r *apiRequest,
+	resp interface{}) error {
+
+	if r.method == "GET" {
+		f, err := c.fetch(ctx, r)
+		if err != nil {
+			return err
+		}
+		return decodeResponse(f.data, resp)
+	}
+
+	respBody, err := c.send(ctx, r)
+	if err != nil {
+		return fmt.Errorf("request error: %w", err)
+	}
+	defer respBody.Close()
+
+	// Check if JSON decoding is required. The body is tied to the request
+	// context, so cancelling ctx also aborts a slow decode.
+	if resp != nil {
+		err = json.NewDecoder(respBody).Decode(resp)
+	}
+
+	return err
+}
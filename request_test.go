@@ -0,0 +1,168 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Query parameters", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	var (
+		queries []string
+		bodies  []string
+	)
+
+	// responder answers like a server which reads arguments from the query
+	// string, or from the body only if bodyOnly is set
+	responder := func(bodyOnly bool, resp string) httpmock.Responder {
+		return func(req *http.Request) (*http.Response, error) {
+			body := []byte{}
+			if req.Body != nil {
+				body, _ = ioutil.ReadAll(req.Body)
+			}
+			queries = append(queries, req.URL.RawQuery)
+			bodies = append(bodies, string(body))
+
+			if bodyOnly && len(body) == 0 {
+				return httpmock.NewStringResponse(400,
+					`{"error": "failed to decode JSON object"}`), nil
+			}
+			return httpmock.NewStringResponse(200, resp), nil
+		}
+	}
+
+	// serverInfo answers the API root with info
+	serverInfo := func(info string) {
+		httpmock.RegisterResponder("GET", test_url+"/",
+			httpmock.NewStringResponder(200, info))
+	}
+
+	BeforeEach(func() {
+		queries = []string{}
+		bodies = []string{}
+
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewBytesResponder(200, []byte(`{"access_token":"ABC123"}`)))
+	})
+
+	It("should send arguments as query parameters", func() {
+		serverInfo(`{"version": "0.8.0",
+			"capabilities": ["artifacts", "query-parameters"]}`)
+		httpmock.RegisterResponder("GET", test_url+"/artifacts",
+			responder(false, `[]`))
+		httpmock.RegisterResponder("GET", test_url+"/blobs",
+			responder(false, `[]`))
+		httpmock.RegisterResponder("GET", test_url+"/instances/abc/consoledata",
+			responder(false, `console output`))
+
+		client := NewClient(test_url, test_namespace, test_key)
+
+		_, err := client.GetArtifacts("sf-1")
+		Expect(err).To(BeNil())
+		_, err = client.GetBlobs("sf 2")
+		Expect(err).To(BeNil())
+		data, err := client.GetConsoleData("abc", 1000)
+		Expect(err).To(BeNil())
+		Expect(data).To(Equal("console output"))
+
+		Expect(queries).To(Equal([]string{"node=sf-1", "node=sf+2",
+			"length=1000"}))
+		Expect(bodies).To(Equal([]string{"", "", ""}))
+	})
+
+	It("should not send an empty node", func() {
+		serverInfo(`{"version": "0.8.0",
+			"capabilities": ["artifacts", "query-parameters"]}`)
+		httpmock.RegisterResponder("GET", test_url+"/blobs",
+			responder(false, `[]`))
+
+		_, err := NewClient(test_url, test_namespace, test_key).GetBlobs("")
+		Expect(err).To(BeNil())
+		Expect(queries).To(Equal([]string{""}))
+		Expect(bodies).To(Equal([]string{""}))
+	})
+
+	It("should send a body to servers which do not list query parameters", func() {
+		serverInfo(`{"version": "0.7.3"}`)
+		httpmock.RegisterResponder("GET", test_url+"/artifacts",
+			responder(false, `[{"uuid": "a7b1c9e2"}]`))
+		httpmock.RegisterResponder("GET", test_url+"/instances/abc/consoledata",
+			responder(false, `console output`))
+
+		client := NewClient(test_url, test_namespace, test_key)
+
+		// The server would answer a query with unfiltered data, so the
+		// body form is used even though it accepts both
+		artifacts, err := client.GetArtifacts("sf-1")
+		Expect(err).To(BeNil())
+		Expect(artifacts).To(HaveLen(1))
+		data, err := client.GetConsoleData("abc", 1000)
+		Expect(err).To(BeNil())
+		Expect(data).To(Equal("console output"))
+
+		Expect(queries).To(Equal([]string{"", ""}))
+		Expect(bodies[0]).To(MatchJSON(`{"node": "sf-1"}`))
+		Expect(bodies[1]).To(MatchJSON(`{"length": 1000}`))
+	})
+
+	It("should send a body to servers which do not describe themselves", func() {
+		httpmock.RegisterResponder("GET", test_url+"/",
+			httpmock.NewStringResponder(404, `{"error": "not found"}`))
+		httpmock.RegisterResponder("GET", test_url+"/blobs",
+			responder(true, `[]`))
+
+		_, err := NewClient(test_url, test_namespace, test_key).GetBlobs("sf-1")
+		Expect(err).To(BeNil())
+		Expect(queries).To(Equal([]string{""}))
+		Expect(bodies[0]).To(MatchJSON(`{"node": "sf-1"}`))
+	})
+
+	It("should not resend a rejected request in the other form", func() {
+		serverInfo(`{"version": "0.8.0",
+			"capabilities": ["artifacts", "query-parameters"]}`)
+		httpmock.RegisterResponder("GET", test_url+"/artifacts",
+			responder(true, `[]`))
+
+		_, err := NewClient(test_url, test_namespace, test_key).GetArtifacts("sf-1")
+		Expect(statusCode(err)).To(Equal(400))
+		Expect(queries).To(Equal([]string{"node=sf-1"}))
+	})
+
+	It("should use the body form when told the server needs it", func() {
+		serverInfo(`{"version": "0.8.0",
+			"capabilities": ["artifacts", "query-parameters"]}`)
+		httpmock.RegisterResponder("GET", test_url+"/blobs",
+			responder(true, `[]`))
+
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithQueryParameters(false))
+		Expect(err).To(BeNil())
+
+		_, err = client.GetBlobs("sf-1")
+		Expect(err).To(BeNil())
+		Expect(queries).To(Equal([]string{""}))
+		Expect(bodies[0]).To(MatchJSON(`{"node": "sf-1"}`))
+	})
+
+	It("should use query parameters when told the server reads them", func() {
+		httpmock.RegisterResponder("GET", test_url+"/blobs",
+			responder(false, `[]`))
+
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithQueryParameters(true))
+		Expect(err).To(BeNil())
+
+		_, err = client.GetBlobs("sf-1")
+		Expect(err).To(BeNil())
+		Expect(queries).To(Equal([]string{"node=sf-1"}))
+	})
+})
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// RequestIDHeader is the header which carries request IDs to and from the
+// server.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID identifies a call in the client and server logs.
+type RequestID struct {
+	// Client is the ID the client sent
+	Client string
+
+	// Server is the ID the server returned, if it sent one
+	Server string
+}
+
+type requestIDKey struct{}
+
+type requestIDCaptureKey struct{}
+
+// WithRequestID returns a context which sends id as the X-Request-ID of
+// calls made with it. Without one the client generates an ID for each call.
+// Every request for a call, including retries and fetching an auth token,
+// carries the same ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom returns the request ID set in ctx, or "" if there is none.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// CaptureRequestID returns a context which records the request IDs of calls
+// made with it. Once a call returns, the RequestID holds the IDs of its last
+// request. Calls answered from the response cache leave it unchanged, and
+// calls which shared a request with another record the shared request's
+// IDs.
+//
+//	ctx, id := client.CaptureRequestID(context.Background())
+//	instance, err := c.GetInstanceCtx(ctx, uuid)
+//	log.Printf("fetched %s in request %s", uuid, id.Server)
+func CaptureRequestID(ctx context.Context) (context.Context, *RequestID) {
+	id := &RequestID{}
+	return context.WithValue(ctx, requestIDCaptureKey{}, id), id
+}
+
+// recordRequestID stores id in the capture from ctx, if there is one.
+func recordRequestID(ctx context.Context, id RequestID) {
+	if capture, ok := ctx.Value(requestIDCaptureKey{}).(*RequestID); ok {
+		*capture = id
+	}
+}
+
+// ensureRequestID returns ctx with a request ID, generating one if needed.
+func ensureRequestID(ctx context.Context) context.Context {
+	if RequestIDFrom(ctx) != "" {
+		return ctx
+	}
+	return WithRequestID(ctx, newRequestID())
+}
+
+// newRequestID returns a random version 4 UUID.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// serverRequestID returns the request ID the server sent with a response.
+func serverRequestID(resp *http.Response) string {
+	return resp.Header.Get(RequestIDHeader)
+}
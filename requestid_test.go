@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request IDs", func() {
+	var (
+		server *httptest.Server
+
+		lock sync.Mutex
+		seen []string
+		// Responses to send for /nodes before succeeding
+		failures []int
+		// Whether to tell the client its first token has expired
+		expire bool
+	)
+
+	BeforeEach(func() {
+		seen = []string{}
+		failures = nil
+		expire = false
+
+		server = httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				seen = append(seen, r.URL.Path+" "+r.Header.Get(RequestIDHeader))
+				w.Header().Set(RequestIDHeader, "server-"+r.URL.Path[1:])
+
+				switch {
+				case r.URL.Path == "/auth":
+					w.Write([]byte(`{"access_token":"token"}`))
+				case expire:
+					expire = false
+					w.WriteHeader(http.StatusUnauthorized)
+				case len(failures) > 0:
+					w.WriteHeader(failures[0])
+					failures = failures[1:]
+				default:
+					w.Write([]byte(`[]`))
+				}
+			}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func() *Client {
+		policy := DefaultRetryPolicy()
+		policy.InitialBackoff = time.Millisecond
+		client, err := newTestClient(server.URL, WithRetryPolicy(policy))
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	requests := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string{}, seen...)
+	}
+
+	It("should generate an ID for each call", func() {
+		client := newClient()
+
+		_, err := client.GetNodes()
+		Expect(err).To(BeNil())
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+
+		sent := requests()
+		Expect(sent).To(HaveLen(3))
+		Expect(sent[0]).To(MatchRegexp(
+			`^/auth [0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+
+		// The auth request is part of the first call
+		Expect(sent[1]).To(Equal("/nodes" + sent[0][len("/auth"):]))
+		Expect(sent[2]).NotTo(Equal(sent[1]))
+	})
+
+	It("should use the same ID across retries and the auth refresh", func() {
+		client := newClient()
+		_, err := client.GetNodes()
+		Expect(err).To(BeNil())
+
+		lock.Lock()
+		seen = []string{}
+		failures = []int{http.StatusServiceUnavailable}
+		expire = true
+		lock.Unlock()
+
+		ctx := WithRequestID(context.Background(), "deploy-42")
+		_, err = client.GetNodesCtx(ctx)
+		Expect(err).To(BeNil())
+		Expect(requests()).To(Equal([]string{
+			"/nodes deploy-42",
+			"/auth deploy-42",
+			"/nodes deploy-42",
+			"/nodes deploy-42",
+		}))
+	})
+
+	It("should capture the server's ID for a successful call", func() {
+		client := newClient()
+
+		ctx, id := CaptureRequestID(WithRequestID(context.Background(), "ops-1"))
+		_, err := client.GetNodesCtx(ctx)
+		Expect(err).To(BeNil())
+		Expect(*id).To(Equal(RequestID{Client: "ops-1", Server: "server-nodes"}))
+	})
+
+	It("should report both IDs in an API error", func() {
+		client := newClient()
+		lock.Lock()
+		failures = []int{http.StatusNotFound}
+		lock.Unlock()
+
+		ctx, id := CaptureRequestID(context.Background())
+		_, err := client.GetNodesCtx(ctx)
+
+		var apiErr *APIError
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+		Expect(apiErr.RequestID).To(Equal("server-nodes"))
+		Expect(apiErr.ClientRequestID).NotTo(BeEmpty())
+		Expect(*id).To(Equal(RequestID{Client: apiErr.ClientRequestID,
+			Server: "server-nodes"}))
+	})
+
+	It("should log the ID sent", func() {
+		var entries []RequestLogEntry
+		client, err := newTestClient(server.URL,
+			WithRequestLogger(RequestLoggerFunc(func(e RequestLogEntry) {
+				entries = append(entries, e)
+			})))
+		Expect(err).To(BeNil())
+
+		_, err = client.GetNodesCtx(WithRequestID(context.Background(), "log-7"))
+		Expect(err).To(BeNil())
+		Expect(entries).To(HaveLen(2))
+		for _, e := range entries {
+			Expect(e.RequestID).To(Equal("log-7"))
+		}
+	})
+})
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the client retries requests which fail with a
+// transient error: a connection failure, a timeout or one of the
+// RetryableStatusCodes.
+//
+// A nil *RetryPolicy makes a single attempt per request.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made, including the first.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. Each following
+	// retry waits Multiplier times longer, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// Jitter is the fraction (0 to 1) of each delay which is randomised.
+	Jitter float64
+
+	// RetryableStatusCodes lists the HTTP status codes worth retrying.
+	RetryableStatusCodes []int
+
+	// Idempotent reports whether a request may safely be sent more than
+	// once. If nil, DefaultIdempotent is used.
+	Idempotent func(method, path string) bool
+
+	// OnAttempt, if set, is called after every attempt.
+	OnAttempt func(RetryAttempt)
+}
+
+// RetryAttempt describes the outcome of a single attempt of a request.
+type RetryAttempt struct {
+	Method     string
+	Path       string
+	Attempt    int
+	StatusCode int
+	Err        error
+
+	// WillRetry is true if another attempt will be made after Delay.
+	WillRetry bool
+	Delay     time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewClient.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// DefaultIdempotent treats GET, HEAD, OPTIONS, PUT and DELETE requests as
+// safe to retry. POST requests, such as creating an instance, are not.
+func DefaultIdempotent(method, path string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// SetRetryPolicy replaces the retry policy of the client. A nil policy
+// disables retries.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// shouldRetry decides whether a failed attempt should be retried.
+func (p *RetryPolicy) shouldRetry(ctx context.Context, method, path string,
+	attempt int, statusCode int, err error) bool {
+
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	// The caller has given up, or the body cannot be sent, so there is no
+	// point trying again
+	if ctx.Err() != nil || isBodyError(err) {
+		return false
+	}
+
+	idempotent := p.Idempotent
+	if idempotent == nil {
+		idempotent = DefaultIdempotent
+	}
+	if !idempotent(method, path) {
+		return false
+	}
+
+	// No status code means the request failed in transport
+	if statusCode == 0 {
+		return true
+	}
+
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry attempt. A Retry-After
+// delay from the server takes precedence if it is longer.
+func (p *RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(mult, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * p.Jitter * rand.Float64()
+	}
+
+	d := time.Duration(delay)
+	if retryAfter > d {
+		d = retryAfter
+	}
+	return d
+}
+
+// report passes the outcome of an attempt to the OnAttempt hook.
+func (p *RetryPolicy) report(a RetryAttempt) {
+	if p != nil && p.OnAttempt != nil {
+		p.OnAttempt(a)
+	}
+}
+
+// retryAfter extracts the server requested delay from an APIError.
+func retryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.retryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// sleepCtx waits for d, returning early with an error if ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Retry policy", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	var (
+		client   *Client
+		policy   *RetryPolicy
+		attempts []RetryAttempt
+	)
+
+	BeforeEach(func() {
+		// Configure client with a fast retry policy
+		client = NewClient(test_url, test_namespace, test_key)
+
+		attempts = []RetryAttempt{}
+		policy = DefaultRetryPolicy()
+		policy.InitialBackoff = time.Millisecond
+		policy.OnAttempt = func(a RetryAttempt) {
+			attempts = append(attempts, a)
+		}
+		client.SetRetryPolicy(policy)
+
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewBytesResponder(200, []byte(`{"access_token":"ABC123"}`)))
+	})
+
+	It("should retry a GET which fails with a transient status", func() {
+		calls := 0
+		httpmock.RegisterResponder("GET", test_url+"/networks",
+			func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls < 3 {
+					return httpmock.NewStringResponse(503, "busy"), nil
+				}
+				return httpmock.NewStringResponse(200, `[]`), nil
+			})
+
+		_, err := client.GetNetworks()
+		Expect(err).To(BeNil())
+		Expect(calls).To(Equal(3))
+
+		Expect(attempts).To(HaveLen(3))
+		Expect(attempts[0].StatusCode).To(Equal(503))
+		Expect(attempts[0].WillRetry).To(BeTrue())
+		Expect(attempts[2].Attempt).To(Equal(3))
+		Expect(attempts[2].Err).To(BeNil())
+		Expect(attempts[2].WillRetry).To(BeFalse())
+	})
+
+	It("should retry connection failures", func() {
+		calls := 0
+		httpmock.RegisterResponder("GET", test_url+"/nodes",
+			func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					return nil, errors.New("connection reset by peer")
+				}
+				return httpmock.NewStringResponse(200, `[]`), nil
+			})
+
+		_, err := client.GetNodes()
+		Expect(err).To(BeNil())
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should give up after the maximum number of attempts", func() {
+		httpmock.RegisterResponder("DELETE", test_url+"/networks/123-456",
+			httpmock.NewStringResponder(504, "timeout"))
+
+		err := client.DeleteNetwork("123-456")
+		Expect(IsServerError(err)).To(BeTrue())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["DELETE "+test_url+"/networks/123-456"]).To(Equal(3))
+	})
+
+	It("should not retry a POST by default", func() {
+		httpmock.RegisterResponder("POST", test_url+"/instances",
+			httpmock.NewStringResponder(503, "busy"))
+
+		_, err := client.CreateInstance("test", 1, 1024, nil, nil,
+			VideoSpec{}, "", "", "", "", false, false, "")
+		Expect(err).ToNot(BeNil())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["POST "+test_url+"/instances"]).To(Equal(1))
+		Expect(attempts).To(HaveLen(1))
+		Expect(attempts[0].WillRetry).To(BeFalse())
+	})
+
+	It("should allow a custom idempotency classification", func() {
+		policy.Idempotent = func(method, path string) bool {
+			return path == "instances/123-456/reboot"
+		}
+		httpmock.RegisterResponder("POST", test_url+"/instances/123-456/reboot",
+			httpmock.NewStringResponder(502, "bad gateway"))
+
+		err := client.RebootInstance("123-456")
+		Expect(err).ToNot(BeNil())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["POST "+test_url+"/instances/123-456/reboot"]).To(Equal(3))
+	})
+
+	It("should not retry client errors", func() {
+		httpmock.RegisterResponder("GET", test_url+"/instances/123-456",
+			httpmock.NewStringResponder(404, `{"error": "not found"}`))
+
+		_, err := client.GetInstance("123-456")
+		Expect(IsNotFound(err)).To(BeTrue())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["GET "+test_url+"/instances/123-456"]).To(Equal(1))
+	})
+
+	It("should respect Retry-After", func() {
+		resp := httpmock.NewStringResponse(429, "slow down")
+		resp.Header.Set("Retry-After", "1")
+		httpmock.RegisterResponder("GET", test_url+"/networks",
+			httpmock.ResponderFromResponse(resp))
+		policy.MaxAttempts = 2
+
+		start := time.Now()
+		_, err := client.GetNetworks()
+		Expect(err).ToNot(BeNil())
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		Expect(attempts[0].Delay).To(Equal(time.Second))
+	})
+
+	It("should make a single attempt without a policy", func() {
+		client.SetRetryPolicy(nil)
+		httpmock.RegisterResponder("GET", test_url+"/networks",
+			httpmock.NewStringResponder(503, "busy"))
+
+		_, err := client.GetNetworks()
+		Expect(err).ToNot(BeNil())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["GET "+test_url+"/networks"]).To(Equal(1))
+	})
+
+	It("should back off exponentially up to the maximum", func() {
+		p := &RetryPolicy{
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     time.Second,
+			Multiplier:     2,
+		}
+		Expect(p.backoff(1, 0)).To(Equal(100 * time.Millisecond))
+		Expect(p.backoff(2, 0)).To(Equal(200 * time.Millisecond))
+		Expect(p.backoff(3, 0)).To(Equal(400 * time.Millisecond))
+		Expect(p.backoff(10, 0)).To(Equal(time.Second))
+		Expect(p.backoff(1, 3*time.Second)).To(Equal(3 * time.Second))
+
+		p.Jitter = 0.5
+		for i := 0; i < 20; i++ {
+			d := p.backoff(2, 0)
+			Expect(d).To(BeNumerically(">", 100*time.Millisecond))
+			Expect(d).To(BeNumerically("<=", 200*time.Millisecond))
+		}
+	})
+})
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Capability is an optional feature of the Shaken Fist API.
+type Capability string
+
+const (
+	CapabilityArtifacts       Capability = "artifacts"
+	CapabilityUploads         Capability = "uploads"
+	CapabilityAgentOperations Capability = "agent-operations"
+	CapabilityLabels          Capability = "labels"
+	CapabilityQueryParameters Capability = "query-parameters"
+)
+
+// capabilityVersions are the releases which introduced each capability,
+// for servers which do not list their capabilities. They are taken from the
+// Shaken Fist release notes: v0.4 added artifacts, blobs, labels and
+// uploads, and v0.7 added agent operations. Reading GET arguments from query
+// parameters is not tied to a release, so it is only known for servers
+// which list it.
+var capabilityVersions = map[Capability][]int{
+	CapabilityArtifacts:       {0, 4},
+	CapabilityUploads:         {0, 4},
+	CapabilityLabels:          {0, 4},
+	CapabilityAgentOperations: {0, 7},
+}
+
+// versionPattern finds a release number in a free text response.
+var versionPattern = regexp.MustCompile(`v?(\d+(\.\d+)+)`)
+
+// ServerInfo describes the Shaken Fist API server.
+type ServerInfo struct {
+	// Version is the server release, or empty if it did not say.
+	Version string `json:"version"`
+
+	// Capabilities are the optional features the server supports. They are
+	// worked out from the version for servers which do not list them.
+	Capabilities []Capability `json:"capabilities"`
+
+	// Message is the text of the response, for servers which do not
+	// respond with JSON.
+	Message string `json:"-"`
+}
+
+// Known reports whether the server said enough about itself to know its
+// capabilities.
+func (s ServerInfo) Known() bool {
+	return s.Version != "" || s.Capabilities != nil
+}
+
+// Supports reports whether the server has capability. It is false if the
+// capabilities are not known.
+func (s ServerInfo) Supports(capability Capability) bool {
+	for _, c := range s.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// GetServerInfo fetches the version and capabilities of the server from the
+// API root. The result is cached, and methods which need a capability the
+// server lacks fail with an UnsupportedError without sending a request. The
+// first such method fetches it if GetServerInfo has not been called.
+//
+// The API root does not need authentication, so no token is fetched, and a
+// request which fails is not retried.
+func (c *Client) GetServerInfo() (ServerInfo, error) {
+	return c.GetServerInfoCtx(context.Background())
+}
+
+// GetServerInfoCtx is GetServerInfo with a caller supplied context.
+func (c *Client) GetServerInfoCtx(ctx context.Context) (ServerInfo, error) {
+	if c.initErr != nil {
+		return ServerInfo{}, c.initErr
+	}
+
+	// Capability checks are made on the way to other requests, whose ETag
+	// exchange this is not part of
+	ctx = ensureRequestID(withoutCacheExchange(ctx))
+	body, _, err := c.httpRequest(ctx, "", "GET", nil, "")
+	if err != nil {
+		return ServerInfo{}, fmt.Errorf("cannot retrieve server info: %w", err)
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return ServerInfo{}, fmt.Errorf("cannot read server info: %w", err)
+	}
+
+	info := parseServerInfo(data)
+
+	c.capLock.Lock()
+	c.serverInfo = &info
+	c.infoErr = nil
+	c.capLock.Unlock()
+
+	return info, nil
+}
+
+// parseServerInfo decodes the API root response. Newer servers respond with
+// JSON, older ones with a line of text.
+func parseServerInfo(data []byte) ServerInfo {
+	info := ServerInfo{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		info = ServerInfo{Message: strings.TrimSpace(string(data))}
+		if m := versionPattern.FindStringSubmatch(info.Message); m != nil {
+			info.Version = m[1]
+		}
+	}
+	info.Version = strings.TrimPrefix(info.Version, "v")
+
+	if info.Capabilities == nil && info.Version != "" {
+		info.Capabilities = []Capability{}
+		release := parseVersion(info.Version)
+		for _, capability := range []Capability{CapabilityArtifacts,
+			CapabilityUploads, CapabilityAgentOperations, CapabilityLabels} {
+
+			if !versionBefore(release, capabilityVersions[capability]) {
+				info.Capabilities = append(info.Capabilities, capability)
+			}
+		}
+	}
+
+	return info
+}
+
+// parseVersion returns the leading numeric parts of a version such as
+// "0.7.3.dev12".
+func parseVersion(version string) []int {
+	parts := []int{}
+	for _, p := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// versionBefore reports whether version a is older than b.
+func versionBefore(a, b []int) bool {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return false
+}
+
+// require returns an UnsupportedError if the server is known to lack
+// capability. The server info is fetched by the first call. If that fails,
+// the call goes ahead.
+func (c *Client) require(ctx context.Context, capability Capability) error {
+	info, err := c.lazyServerInfo(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.logf("cannot check the server supports %s: %v", capability, err)
+		return nil
+	}
+
+	if !info.Known() || info.Supports(capability) {
+		return nil
+	}
+
+	version := info.Version
+	if version == "" {
+		version = "unknown"
+	}
+	return &UnsupportedError{Capability: capability, Version: version}
+}
+
+// infoFetch is a fetch of the server info which other callers can wait for.
+type infoFetch struct {
+	done chan struct{}
+	info ServerInfo
+	err  error
+}
+
+// lazyServerInfo returns the cached server info, fetching it if it has not
+// been fetched yet. Concurrent callers share one fetch, but each stops
+// waiting when its own ctx is done. A server which answers with an error is
+// taken to have unknown capabilities, while one which cannot be reached is
+// not asked again until the endpoint cool-down has passed.
+func (c *Client) lazyServerInfo(ctx context.Context) (ServerInfo, error) {
+	c.capLock.Lock()
+	if c.serverInfo != nil {
+		info := *c.serverInfo
+		c.capLock.Unlock()
+		return info, nil
+	}
+	if c.infoErr != nil && time.Now().Before(c.infoRetry) {
+		err := c.infoErr
+		c.capLock.Unlock()
+		return ServerInfo{}, err
+	}
+
+	if f := c.infoFetch; f != nil {
+		c.capLock.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return ServerInfo{}, ctx.Err()
+		}
+
+		// The fetch was abandoned by the caller who made it, not us
+		if errors.Is(f.err, context.Canceled) ||
+			errors.Is(f.err, context.DeadlineExceeded) {
+			return c.lazyServerInfo(ctx)
+		}
+		return f.info, f.err
+	}
+
+	f := &infoFetch{done: make(chan struct{})}
+	c.infoFetch = f
+	c.capLock.Unlock()
+
+	f.info, f.err = c.GetServerInfoCtx(ctx)
+
+	c.capLock.Lock()
+	c.infoFetch = nil
+	switch {
+	case f.err == nil:
+	case statusCode(f.err) != 0:
+		c.serverInfo = &ServerInfo{}
+	case ctx.Err() == nil:
+		c.infoErr = f.err
+		c.infoRetry = time.Now().Add(c.endpointCoolDown)
+	}
+	c.capLock.Unlock()
+	close(f.done)
+
+	return f.info, f.err
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server info", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	BeforeEach(func() {
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewBytesResponder(200, []byte(`{"access_token":"ABC123"}`)))
+	})
+
+	It("should read capabilities listed by the server", func() {
+		httpmock.RegisterResponder("GET", test_url+"/",
+			httpmock.NewStringResponder(200,
+				`{"version": "v0.8.1", "capabilities": ["artifacts", "labels"]}`))
+
+		info, err := NewClient(test_url, test_namespace, test_key).GetServerInfo()
+		Expect(err).To(BeNil())
+		Expect(info.Version).To(Equal("0.8.1"))
+		Expect(info.Known()).To(BeTrue())
+		Expect(info.Supports(CapabilityArtifacts)).To(BeTrue())
+		Expect(info.Supports(CapabilityLabels)).To(BeTrue())
+		Expect(info.Supports(CapabilityUploads)).To(BeFalse())
+	})
+
+	It("should work out capabilities from the version", func() {
+		info := parseServerInfo([]byte("Shaken Fist REST API service v0.7.3.dev12"))
+		Expect(info.Version).To(Equal("0.7.3"))
+		Expect(info.Capabilities).To(ConsistOf(CapabilityArtifacts,
+			CapabilityUploads, CapabilityLabels, CapabilityAgentOperations))
+
+		info = parseServerInfo([]byte(`{"version": "0.7.0.dev12"}`))
+		Expect(info.Supports(CapabilityAgentOperations)).To(BeTrue())
+
+		info = parseServerInfo([]byte(`{"version": "0.4.0"}`))
+		Expect(info.Capabilities).To(ConsistOf(CapabilityArtifacts,
+			CapabilityUploads, CapabilityLabels))
+
+		info = parseServerInfo([]byte(`{"version": "0.3.9"}`))
+		Expect(info.Known()).To(BeTrue())
+		Expect(info.Capabilities).To(BeEmpty())
+	})
+
+	It("should not know the capabilities of a server without a version", func() {
+		info := parseServerInfo([]byte("Shaken Fist REST API service"))
+		Expect(info.Message).To(Equal("Shaken Fist REST API service"))
+		Expect(info.Known()).To(BeFalse())
+		Expect(info.Supports(CapabilityArtifacts)).To(BeFalse())
+	})
+
+	It("should fail early when the server lacks a capability", func() {
+		httpmock.RegisterResponder("GET", test_url+"/",
+			httpmock.NewStringResponder(200, `{"version": "0.3.2"}`))
+
+		client := NewClient(test_url, test_namespace, test_key)
+		_, err := client.GetServerInfo()
+		Expect(err).To(BeNil())
+
+		httpmock.ZeroCallCounters()
+		_, err = client.GetArtifacts("")
+		Expect(IsUnsupported(err)).To(BeTrue())
+		Expect(err).To(MatchError("artifacts unsupported by server version 0.3.2"))
+
+		err = client.CacheArtifact("http://example.com/image")
+		Expect(IsUnsupported(err)).To(BeTrue())
+		Expect(httpmock.GetTotalCallCount()).To(Equal(0))
+	})
+
+	It("should fetch server info on the first call which needs it", func() {
+		httpmock.RegisterResponder("GET", test_url+"/",
+			httpmock.NewStringResponder(200, `{"version": "0.3.2"}`))
+		httpmock.RegisterResponder("PUT", test_url+"/label/stable",
+			httpmock.NewStringResponder(200, ``))
+
+		client := NewClient(test_url, test_namespace, test_key)
+		err := client.UpdateLabel("stable", "c2d4e6f8")
+		Expect(err).To(MatchError("labels unsupported by server version 0.3.2"))
+		_, err = client.CreateUpload()
+		Expect(IsUnsupported(err)).To(BeTrue())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["GET "+test_url+"/"]).To(Equal(1))
+		Expect(info["PUT "+test_url+"/label/stable"]).To(Equal(0))
+	})
+
+	It("should go ahead when the server info cannot be fetched", func() {
+		httpmock.RegisterResponder("GET", test_url+"/",
+			httpmock.NewStringResponder(500, `{"error": "broken"}`))
+		httpmock.RegisterResponder("GET", test_url+"/artifacts",
+			httpmock.NewStringResponder(200, `[]`))
+
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithRetryPolicy(nil))
+		Expect(err).To(BeNil())
+		_, err = client.GetArtifacts("")
+		Expect(err).To(BeNil())
+		_, err = client.GetArtifacts("")
+		Expect(err).To(BeNil())
+
+		// A server which answered is not asked again
+		info := httpmock.GetCallCountInfo()
+		Expect(info["GET "+test_url+"/"]).To(Equal(1))
+	})
+
+	It("should not block calls when the version is unknown", func() {
+		httpmock.RegisterResponder("GET", test_url+"/",
+			httpmock.NewStringResponder(200, `Shaken Fist REST API service`))
+		httpmock.RegisterResponder("GET", test_url+"/blobs",
+			httpmock.NewStringResponder(200, `[]`))
+
+		client := NewClient(test_url, test_namespace, test_key)
+		_, err := client.GetServerInfo()
+		Expect(err).To(BeNil())
+
+		_, err = client.GetBlobs("")
+		Expect(err).To(BeNil())
+	})
+
+	It("should fetch server info without a token or retries", func() {
+		httpmock.RegisterResponder("GET", test_url+"/",
+			httpmock.NewStringResponder(503, `{"error": "starting"}`))
+
+		_, err := NewClient(test_url, test_namespace, test_key).GetServerInfo()
+		Expect(statusCode(err)).To(Equal(503))
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["GET "+test_url+"/"]).To(Equal(1))
+		Expect(info["POST "+test_url+"/auth"]).To(Equal(0))
+	})
+
+	It("should not ask an unreachable server again straight away", func() {
+		httpmock.RegisterResponder("GET", test_url+"/",
+			httpmock.NewErrorResponder(errors.New("connection refused")))
+		httpmock.RegisterResponder("GET", test_url+"/artifacts",
+			httpmock.NewStringResponder(200, `[]`))
+
+		client := NewClient(test_url, test_namespace, test_key)
+		_, err := client.GetArtifacts("")
+		Expect(err).To(BeNil())
+		_, err = client.GetArtifacts("")
+		Expect(err).To(BeNil())
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["GET "+test_url+"/"]).To(Equal(1))
+		Expect(info["GET "+test_url+"/artifacts"]).To(Equal(2))
+	})
+
+	It("should stop waiting for a shared fetch when the context is done", func() {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		httpmock.RegisterResponder("GET", test_url+"/",
+			func(req *http.Request) (*http.Response, error) {
+				close(started)
+				<-release
+				return httpmock.NewStringResponse(200, `{"version": "0.8.0"}`), nil
+			})
+		httpmock.RegisterResponder("GET", test_url+"/artifacts",
+			httpmock.NewStringResponder(200, `[]`))
+
+		client := NewClient(test_url, test_namespace, test_key)
+		first := make(chan error, 1)
+		go func() {
+			_, err := client.GetArtifacts("")
+			first <- err
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			10*time.Millisecond)
+		defer cancel()
+		_, err := client.GetArtifactsCtx(ctx, "")
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+
+		close(release)
+		Expect(<-first).To(BeNil())
+		Expect(httpmock.GetCallCountInfo()["GET "+test_url+"/"]).To(Equal(1))
+	})
+})
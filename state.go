@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// State is the lifecycle state of an instance, network or interface.
+// Servers newer than this client may send states not listed here, which
+// decode without error and are reported by Known.
+type State string
+
+const (
+	StateInitial   State = "initial"
+	StatePreflight State = "preflight"
+	StateCreating  State = "creating"
+	StateCreated   State = "created"
+	StateDeleted   State = "deleted"
+	StateError     State = "error"
+)
+
+// States are the lifecycle states this client knows of.
+var States = []State{StateInitial, StatePreflight, StateCreating,
+	StateCreated, StateDeleted, StateError}
+
+// Known reports whether the state is one of States.
+func (s State) Known() bool {
+	for _, known := range States {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether the resource will never leave the state.
+func (s State) IsTerminal() bool {
+	return s == StateDeleted || s == StateError
+}
+
+// IsActive reports whether the resource has been created and is usable.
+func (s State) IsActive() bool {
+	return s == StateCreated
+}
+
+// PowerState is the power state of an instance. As with State, unknown
+// power states decode without error.
+type PowerState string
+
+const (
+	PowerInitial           PowerState = "initial"
+	PowerOn                PowerState = "on"
+	PowerOff               PowerState = "off"
+	PowerPaused            PowerState = "paused"
+	PowerCrashed           PowerState = "crashed"
+	PowerTransitionOn      PowerState = "transition-to-on"
+	PowerTransitionOff     PowerState = "transition-to-off"
+	PowerTransitionPaused  PowerState = "transition-to-paused"
+	PowerTransitionUnpause PowerState = "transition-to-unpaused"
+)
+
+// PowerStates are the power states this client knows of.
+var PowerStates = []PowerState{PowerInitial, PowerOn, PowerOff, PowerPaused,
+	PowerCrashed, PowerTransitionOn, PowerTransitionOff,
+	PowerTransitionPaused, PowerTransitionUnpause}
+
+// Known reports whether the power state is one of PowerStates.
+func (p PowerState) Known() bool {
+	for _, known := range PowerStates {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive reports whether the instance is running.
+func (p PowerState) IsActive() bool {
+	return p == PowerOn
+}
+
+// IsTransitioning reports whether the instance is changing power state.
+func (p PowerState) IsTransitioning() bool {
+	return strings.HasPrefix(string(p), "transition-to-")
+}
+
+// Action is something done to an existing instance.
+type Action string
+
+const (
+	ActionPowerOn  Action = "power on"
+	ActionPowerOff Action = "power off"
+	ActionReboot   Action = "reboot"
+	ActionPause    Action = "pause"
+	ActionUnpause  Action = "unpause"
+	ActionSnapshot Action = "snapshot"
+	ActionDelete   Action = "delete"
+)
+
+// Transitions lists the actions allowed on an instance in each state.
+// The client only refuses actions itself for instances it has seen in a
+// terminal state, as any other state may have changed on the server since
+// it was seen. Callers can use Allows to check the rest.
+var Transitions = map[State][]Action{
+	StateInitial:   {ActionDelete},
+	StatePreflight: {ActionDelete},
+	StateCreating:  {ActionDelete},
+	StateCreated: {ActionPowerOn, ActionPowerOff, ActionReboot, ActionPause,
+		ActionUnpause, ActionSnapshot, ActionDelete},
+	StateDeleted: {},
+	StateError:   {ActionDelete},
+}
+
+// Allows reports whether an action is allowed on an instance in the state.
+// Unknown states allow every action, leaving the server to decide.
+func (s State) Allows(action Action) bool {
+	actions, ok := Transitions[s]
+	if !ok {
+		return true
+	}
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// maxTerminalStates bounds the instances a client remembers.
+const maxTerminalStates = 1000
+
+// terminalStates remembers instances seen in a terminal state. As they
+// never leave it, actions on them can be refused without asking the server.
+type terminalStates struct {
+	lock   sync.Mutex
+	states map[string]State
+}
+
+// observe records the state of an instance.
+func (t *terminalStates) observe(uuid string, state State) {
+	if uuid == "" || !state.IsTerminal() {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.states == nil {
+		t.states = map[string]State{}
+	}
+	if _, ok := t.states[uuid]; !ok && len(t.states) >= maxTerminalStates {
+		for forget := range t.states {
+			delete(t.states, forget)
+			break
+		}
+	}
+	t.states[uuid] = state
+}
+
+// check returns a TransitionError if the instance is known to be in a state
+// which does not allow the action.
+func (t *terminalStates) check(uuid string, action Action) error {
+	t.lock.Lock()
+	state, ok := t.states[uuid]
+	t.lock.Unlock()
+
+	if ok && !state.Allows(action) {
+		return &TransitionError{UUID: uuid, State: state, Action: action}
+	}
+	return nil
+}
+
+// instanceAction posts an action to an instance, unless the instance is
+// known to be in a state which does not allow it.
+func (c *Client) instanceAction(ctx context.Context, uuid string,
+	action Action, command string) error {
+
+	if err := c.instanceStates.check(uuid, action); err != nil {
+		return err
+	}
+	return c.postRequest(ctx, "instances", uuid, command)
+}
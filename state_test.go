@@ -0,0 +1,134 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Instance states", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	var (
+		client *Client
+	)
+
+	BeforeEach(func() {
+		// Configure client
+		client = NewClient(test_url, test_namespace, test_key)
+
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewBytesResponder(200, []byte(`{"access_token":"ABC123"}`)))
+	})
+
+	It("should classify states", func() {
+		Expect(StateDeleted.IsTerminal()).To(BeTrue())
+		Expect(StateError.IsTerminal()).To(BeTrue())
+		Expect(StateCreating.IsTerminal()).To(BeFalse())
+		Expect(StateCreated.IsActive()).To(BeTrue())
+		Expect(StateInitial.IsActive()).To(BeFalse())
+
+		Expect(PowerOn.IsActive()).To(BeTrue())
+		Expect(PowerPaused.IsActive()).To(BeFalse())
+		Expect(PowerTransitionOff.IsTransitioning()).To(BeTrue())
+		Expect(PowerOff.IsTransitioning()).To(BeFalse())
+	})
+
+	It("should decode states from newer servers", func() {
+		var instance Instance
+		Expect(json.Unmarshal([]byte(
+			`{"state": "migrating", "power_state": "hibernated"}`),
+			&instance)).To(Succeed())
+		Expect(instance.State).To(Equal(State("migrating")))
+		Expect(instance.State.Known()).To(BeFalse())
+		Expect(instance.PowerState.Known()).To(BeFalse())
+		Expect(StatePreflight.Known()).To(BeTrue())
+		Expect(PowerCrashed.Known()).To(BeTrue())
+
+		// The server decides what is allowed in states we do not know
+		Expect(instance.State.Allows(ActionPowerOn)).To(BeTrue())
+	})
+
+	It("should know which actions each state allows", func() {
+		Expect(StateCreated.Allows(ActionPowerOn)).To(BeTrue())
+		Expect(StateCreating.Allows(ActionPowerOn)).To(BeFalse())
+		Expect(StateCreating.Allows(ActionDelete)).To(BeTrue())
+		Expect(StateError.Allows(ActionReboot)).To(BeFalse())
+		Expect(StateError.Allows(ActionDelete)).To(BeTrue())
+		Expect(StateDeleted.Allows(ActionDelete)).To(BeFalse())
+		for _, state := range States {
+			Expect(Transitions).To(HaveKey(state))
+		}
+	})
+
+	It("should refuse to power on a deleted instance", func() {
+		httpmock.RegisterResponder("GET", test_url+"/instances/123-456",
+			httpmock.NewStringResponder(200,
+				`{"uuid": "123-456", "state": "deleted"}`))
+		httpmock.RegisterResponder("POST", test_url+"/instances/123-456/poweron",
+			httpmock.NewBytesResponder(200, nil))
+
+		_, err := client.GetInstance("123-456")
+		Expect(err).To(BeNil())
+
+		err = client.PowerOnInstance("123-456")
+		Expect(IsTransition(err)).To(BeTrue())
+		Expect(err).To(MatchError(
+			"cannot power on instance 123-456 in state deleted"))
+
+		info := httpmock.GetCallCountInfo()
+		Expect(info["POST "+test_url+"/instances/123-456/poweron"]).To(Equal(0))
+	})
+
+	It("should remember instances it deleted", func() {
+		httpmock.RegisterResponder("DELETE", test_url+"/instances/123-456",
+			httpmock.NewBytesResponder(200, nil))
+
+		Expect(client.DeleteInstance("123-456", "")).To(Succeed())
+		Expect(IsTransition(client.SnapshotInstance("123-456", true, ""))).To(
+			BeTrue())
+		Expect(IsTransition(client.DeleteInstance("123-456", ""))).To(BeTrue())
+		Expect(httpmock.GetTotalCallCount()).To(Equal(2))
+	})
+
+	It("should only delete an instance in error", func() {
+		httpmock.RegisterResponder("GET", test_url+"/instances",
+			httpmock.NewStringResponder(200,
+				`[{"uuid": "123-456", "state": "error"}]`))
+		httpmock.RegisterResponder("DELETE", test_url+"/instances/123-456",
+			httpmock.NewBytesResponder(200, nil))
+
+		_, err := client.GetInstances()
+		Expect(err).To(BeNil())
+		Expect(IsTransition(client.RebootInstance("123-456"))).To(BeTrue())
+		Expect(IsTransition(client.PauseInstance("123-456"))).To(BeTrue())
+		Expect(client.DeleteInstance("123-456", "")).To(Succeed())
+	})
+
+	It("should leave instances in other states to the server", func() {
+		httpmock.RegisterResponder("GET", test_url+"/instances/123-456",
+			httpmock.NewStringResponder(200,
+				`{"uuid": "123-456", "state": "creating"}`))
+		httpmock.RegisterResponder("POST", test_url+"/instances/123-456/poweroff",
+			httpmock.NewBytesResponder(200, nil))
+
+		_, err := client.GetInstance("123-456")
+		Expect(err).To(BeNil())
+		Expect(client.PowerOffInstance("123-456")).To(Succeed())
+	})
+
+	It("should bound the instances it remembers", func() {
+		states := terminalStates{}
+		for i := 0; i < maxTerminalStates+10; i++ {
+			states.observe(fmt.Sprintf("uuid-%d", i), StateDeleted)
+		}
+		Expect(states.states).To(HaveLen(maxTerminalStates))
+	})
+})
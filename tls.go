@@ -0,0 +1,239 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// TLSSettings describe how the client verifies the API server and
+// authenticates to it. They can be set with options or read from the "tls"
+// section of a credentials file or cluster profile.
+//
+// Files and PEM data are alternatives, if both are given the PEM data is
+// used.
+type TLSSettings struct {
+	// CAFile and CAPEM are certificate authorities trusted in addition to
+	// the system roots
+	CAFile string `json:"ca_file,omitempty" yaml:"ca-file,omitempty"`
+	CAPEM  string `json:"ca_pem,omitempty" yaml:"ca-pem,omitempty"`
+
+	// The client certificate and key for mutual TLS
+	CertFile string `json:"cert_file,omitempty" yaml:"cert-file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty" yaml:"key-file,omitempty"`
+	CertPEM  string `json:"cert_pem,omitempty" yaml:"cert-pem,omitempty"`
+	KeyPEM   string `json:"key_pem,omitempty" yaml:"key-pem,omitempty"`
+
+	// PinnedSPKI are base64 encoded SHA-256 hashes of the subject public
+	// key info of certificates, optionally prefixed with "sha256/". The
+	// connection is refused unless a certificate in the server's chain
+	// matches one of them.
+	PinnedSPKI []string `json:"pinned_spki,omitempty" yaml:"pinned-spki,omitempty"`
+
+	// InsecureSkipVerify turns off verification of the server certificate,
+	// other than pinning. It is only intended for lab clusters.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty" yaml:"insecure-skip-verify,omitempty"`
+}
+
+// WithTLS applies settings on top of any TLS options already given. They
+// are added to a configuration given to WithTLSConfig, except that CA
+// settings replace its RootCAs with the system roots and the CAs given.
+func WithTLS(settings TLSSettings) Option {
+	return func(c *Client) error {
+		c.tls.merge(settings)
+		return nil
+	}
+}
+
+// WithCAFile trusts the certificate authorities in a PEM bundle, as well as
+// the system roots.
+func WithCAFile(path string) Option {
+	return WithTLS(TLSSettings{CAFile: path})
+}
+
+// WithCAPEM trusts the PEM encoded certificate authorities, as well as the
+// system roots.
+func WithCAPEM(pem []byte) Option {
+	return WithTLS(TLSSettings{CAPEM: string(pem)})
+}
+
+// WithClientCertificate authenticates to the server with the certificate
+// and key in the PEM files given.
+func WithClientCertificate(certFile, keyFile string) Option {
+	return WithTLS(TLSSettings{CertFile: certFile, KeyFile: keyFile})
+}
+
+// WithClientCertificatePEM authenticates to the server with a PEM encoded
+// certificate and key.
+func WithClientCertificatePEM(cert, key []byte) Option {
+	return WithTLS(TLSSettings{CertPEM: string(cert), KeyPEM: string(key)})
+}
+
+// WithPinnedSPKI refuses connections unless the server's certificate chain
+// includes a public key with one of the given SHA-256 hashes.
+func WithPinnedSPKI(pins ...string) Option {
+	return WithTLS(TLSSettings{PinnedSPKI: pins})
+}
+
+// WithInsecureSkipVerify turns off verification of the server certificate.
+// It is only intended for lab clusters with self signed certificates.
+func WithInsecureSkipVerify() Option {
+	return WithTLS(TLSSettings{InsecureSkipVerify: true})
+}
+
+// SPKIHash returns the pin for a certificate, as used by WithPinnedSPKI.
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// merge sets the fields of s which are set in other.
+func (s *TLSSettings) merge(other TLSSettings) {
+	if other.CAFile != "" {
+		s.CAFile = other.CAFile
+	}
+	if other.CAPEM != "" {
+		s.CAPEM = other.CAPEM
+	}
+	if other.CertFile != "" {
+		s.CertFile = other.CertFile
+	}
+	if other.KeyFile != "" {
+		s.KeyFile = other.KeyFile
+	}
+	if other.CertPEM != "" {
+		s.CertPEM = other.CertPEM
+	}
+	if other.KeyPEM != "" {
+		s.KeyPEM = other.KeyPEM
+	}
+	s.PinnedSPKI = append(s.PinnedSPKI, other.PinnedSPKI...)
+	s.InsecureSkipVerify = s.InsecureSkipVerify || other.InsecureSkipVerify
+}
+
+// empty reports whether no settings have been given.
+func (s *TLSSettings) empty() bool {
+	return s.CAFile == "" && s.CAPEM == "" && s.CertFile == "" &&
+		s.KeyFile == "" && s.CertPEM == "" && s.KeyPEM == "" &&
+		len(s.PinnedSPKI) == 0 && !s.InsecureSkipVerify
+}
+
+// apply returns a copy of base, which may be nil, with the settings added.
+func (s *TLSSettings) apply(base *tls.Config) (*tls.Config, error) {
+	config := &tls.Config{}
+	if base != nil {
+		config = base.Clone()
+	}
+
+	if s.CAFile != "" || s.CAPEM != "" {
+		pem := []byte(s.CAPEM)
+		if len(pem) == 0 {
+			path, err := expandHome(s.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			if pem, err = ioutil.ReadFile(path); err != nil {
+				return nil, fmt.Errorf("cannot read CA bundle: %w", err)
+			}
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in CA bundle")
+		}
+		config.RootCAs = pool
+	}
+
+	if s.CertPEM != "" || s.KeyPEM != "" || s.CertFile != "" || s.KeyFile != "" {
+		cert, err := s.clientCertificate()
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	if len(s.PinnedSPKI) > 0 {
+		pins := map[string]bool{}
+		for _, pin := range s.PinnedSPKI {
+			pins[strings.TrimPrefix(pin, "sha256/")] = true
+		}
+		config.VerifyConnection = chainVerify(config.VerifyConnection,
+			verifyPins(pins))
+	}
+
+	if s.InsecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	return config, nil
+}
+
+// clientCertificate loads the client certificate and key.
+func (s *TLSSettings) clientCertificate() (tls.Certificate, error) {
+	if s.CertPEM != "" || s.KeyPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(s.CertPEM), []byte(s.KeyPEM))
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("cannot load client certificate: %w", err)
+		}
+		return cert, nil
+	}
+
+	certFile, err := expandHome(s.CertFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyFile, err := expandHome(s.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("cannot load client certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// chainVerify returns a connection check which runs first, if it is set,
+// and then second.
+func chainVerify(first, second func(tls.ConnectionState) error) func(tls.ConnectionState) error {
+	if first == nil {
+		return second
+	}
+	return func(cs tls.ConnectionState) error {
+		if err := first(cs); err != nil {
+			return err
+		}
+		return second(cs)
+	}
+}
+
+// verifyPins returns a connection check which passes if the server's
+// verified chain includes a pinned public key. Unlike VerifyPeerCertificate
+// it also runs when a TLS session is resumed. In insecure mode there is no
+// verified chain, and only the server's own certificate is checked as the
+// rest of what it presents proves nothing.
+func verifyPins(pins map[string]bool) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		for _, chain := range cs.VerifiedChains {
+			for _, cert := range chain {
+				if pins[SPKIHash(cert)] {
+					return nil
+				}
+			}
+		}
+
+		if len(cs.VerifiedChains) == 0 && len(cs.PeerCertificates) > 0 &&
+			pins[SPKIHash(cs.PeerCertificates[0])] {
+			return nil
+		}
+		return errors.New("server certificate does not match any pinned public key")
+	}
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// tlsHandler is a minimal API server for the TLS tests.
+var tlsHandler = withTestAuth(func(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/nodes" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Write([]byte(`[]`))
+})
+
+// jsonString returns s as a JSON string literal.
+func jsonString(s string) string {
+	out, err := json.Marshal(s)
+	Expect(err).To(BeNil())
+	return string(out)
+}
+
+// newTestCertificate returns a PEM encoded certificate and key signed by
+// parent, or self signed if parent is nil.
+func newTestCertificate(name string, isCA bool, parent *tls.Certificate) (
+	certPEM, keyPEM []byte) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).To(BeNil())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, interface{}(key)
+	if parent != nil {
+		signer, err = x509.ParseCertificate(parent.Certificate[0])
+		Expect(err).To(BeNil())
+		signerKey = parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer,
+		&key.PublicKey, signerKey)
+	Expect(err).To(BeNil())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).To(BeNil())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+var _ = Describe("TLS settings", func() {
+	var (
+		server *httptest.Server
+		caPEM  []byte
+		dir    string
+	)
+
+	newClient := func(opts ...Option) (*Client, error) {
+		return newTestClient(server.URL, opts...)
+	}
+
+	getNodes := func(opts ...Option) error {
+		client, err := newClient(opts...)
+		Expect(err).To(BeNil())
+		_, err = client.GetNodes()
+		return err
+	}
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(tlsHandler)
+		caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+			Bytes: server.Certificate().Raw})
+
+		var err error
+		dir, err = ioutil.TempDir("", "tls")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("should reject a server signed by an unknown CA", func() {
+		err := getNodes()
+		Expect(err).To(MatchError(ContainSubstring("certificate")))
+	})
+
+	It("should trust a CA given as PEM", func() {
+		Expect(getNodes(WithCAPEM(caPEM))).To(Succeed())
+	})
+
+	It("should trust a CA bundle file", func() {
+		path := filepath.Join(dir, "ca.pem")
+		Expect(ioutil.WriteFile(path, caPEM, 0600)).To(Succeed())
+		Expect(getNodes(WithCAFile(path))).To(Succeed())
+	})
+
+	It("should refuse an invalid CA bundle", func() {
+		_, err := newClient(WithCAPEM([]byte("not a certificate")))
+		Expect(err).To(MatchError(ContainSubstring("no certificates found")))
+
+		_, err = newClient(WithCAFile(filepath.Join(dir, "missing.pem")))
+		Expect(err).To(MatchError(ContainSubstring("cannot read CA bundle")))
+	})
+
+	It("should skip verification in insecure mode", func() {
+		Expect(getNodes(WithInsecureSkipVerify())).To(Succeed())
+	})
+
+	It("should check pinned public keys", func() {
+		pin := "sha256/" + SPKIHash(server.Certificate())
+		Expect(getNodes(WithCAPEM(caPEM), WithPinnedSPKI(pin))).To(Succeed())
+		Expect(getNodes(WithInsecureSkipVerify(), WithPinnedSPKI(pin))).To(
+			Succeed())
+
+		wrong := "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+		err := getNodes(WithCAPEM(caPEM), WithPinnedSPKI(wrong))
+		Expect(err).To(MatchError(ContainSubstring("pinned public key")))
+		err = getNodes(WithInsecureSkipVerify(), WithPinnedSPKI(wrong))
+		Expect(err).To(MatchError(ContainSubstring("pinned public key")))
+	})
+
+	It("should run the pin check after the check of a base config", func() {
+		pin := "sha256/" + SPKIHash(server.Certificate())
+		checked := 0
+		base := &tls.Config{
+			VerifyConnection: func(cs tls.ConnectionState) error {
+				checked++
+				return nil
+			},
+		}
+		Expect(getNodes(WithTLSConfig(base), WithCAPEM(caPEM),
+			WithPinnedSPKI(pin))).To(Succeed())
+		Expect(checked).To(Equal(1))
+
+		base.VerifyConnection = func(cs tls.ConnectionState) error {
+			return errors.New("refused by base config")
+		}
+		err := getNodes(WithTLSConfig(base), WithCAPEM(caPEM),
+			WithPinnedSPKI(pin))
+		Expect(err).To(MatchError(ContainSubstring("refused by base config")))
+	})
+
+	It("should present a client certificate", func() {
+		server.Close()
+
+		ca, caKey := newTestCertificate("client ca", true, nil)
+		caCert, err := tls.X509KeyPair(ca, caKey)
+		Expect(err).To(BeNil())
+		clientCert, clientKey := newTestCertificate("client", false, &caCert)
+
+		clientCAs := x509.NewCertPool()
+		clientCAs.AppendCertsFromPEM(ca)
+		server = httptest.NewUnstartedServer(tlsHandler)
+		server.TLS = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+		}
+		server.StartTLS()
+		caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+			Bytes: server.Certificate().Raw})
+
+		Expect(getNodes(WithCAPEM(caPEM))).NotTo(Succeed())
+		Expect(getNodes(WithCAPEM(caPEM),
+			WithClientCertificatePEM(clientCert, clientKey))).To(Succeed())
+
+		certFile := filepath.Join(dir, "client.pem")
+		keyFile := filepath.Join(dir, "client-key.pem")
+		Expect(ioutil.WriteFile(certFile, clientCert, 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(keyFile, clientKey, 0600)).To(Succeed())
+		Expect(getNodes(WithCAPEM(caPEM),
+			WithClientCertificate(certFile, keyFile))).To(Succeed())
+	})
+
+	It("should read TLS settings from a credentials file", func() {
+		path := filepath.Join(dir, "client.json")
+		creds := `{"apiurl": "` + server.URL + `", "namespace": "system",
+			"key": "secret", "tls": {"ca_pem": ` + jsonString(string(caPEM)) + `}}`
+		Expect(ioutil.WriteFile(path, []byte(creds), 0600)).To(Succeed())
+
+		client, err := NewClientFromProvider(FileProvider{Path: path},
+			WithTransport(&http.Transport{}))
+		Expect(err).To(BeNil())
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+	})
+
+	It("should read TLS settings from a cluster profile", func() {
+		path := filepath.Join(dir, "profiles.yaml")
+		profiles := "current-context: lab\n" +
+			"clusters:\n" +
+			"  - name: lab\n" +
+			"    server: " + server.URL + "\n" +
+			"    tls:\n" +
+			"      insecure-skip-verify: true\n" +
+			"credentials:\n" +
+			"  - name: admin\n" +
+			"    namespace: system\n" +
+			"    key: secret\n" +
+			"contexts:\n" +
+			"  - name: lab\n" +
+			"    cluster: lab\n" +
+			"    credential: admin\n"
+		Expect(ioutil.WriteFile(path, []byte(profiles), 0600)).To(Succeed())
+
+		p, err := LoadProfileFile(path)
+		Expect(err).To(BeNil())
+		client, err := p.NewClient("", WithTransport(&http.Transport{}))
+		Expect(err).To(BeNil())
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+	})
+})
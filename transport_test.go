@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// socksServer is a minimal SOCKS5 proxy with username and password auth. It
+// sends every connection to upstream, whatever the client asked for, and
+// records the targets requested.
+type socksServer struct {
+	net.Listener
+	user, password, upstream string
+
+	lock    sync.Mutex
+	targets []string
+}
+
+func newSOCKSServer(user, password, upstream string) *socksServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).To(BeNil())
+
+	s := &socksServer{Listener: l, user: user, password: password,
+		upstream: upstream}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *socksServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	// Greeting, we only accept username and password auth
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 2})
+
+	readField := func() string {
+		size := make([]byte, 1)
+		io.ReadFull(conn, size)
+		field := make([]byte, size[0])
+		io.ReadFull(conn, field)
+		return string(field)
+	}
+	io.ReadFull(conn, make([]byte, 1))
+	user, password := readField(), readField()
+	if user != s.user || password != s.password {
+		conn.Write([]byte{1, 1})
+		return
+	}
+	conn.Write([]byte{1, 0})
+
+	// Connect request
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		host = readField()
+	default:
+		return
+	}
+	port := make([]byte, 2)
+	io.ReadFull(conn, port)
+
+	s.lock.Lock()
+	s.targets = append(s.targets, net.JoinHostPort(host,
+		strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	s.lock.Unlock()
+
+	upstream, err := net.Dial("tcp", s.upstream)
+	if err != nil {
+		conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go io.Copy(upstream, conn)
+	io.Copy(conn, upstream)
+}
+
+func (s *socksServer) requested() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.targets...)
+}
+
+var _ = Describe("Proxies and Unix sockets", func() {
+	const (
+		// Nothing resolves this, so requests only succeed through a proxy
+		test_url       string = "http://sf-api.invalid:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+	)
+
+	newClient := func(server string, opts ...Option) *Client {
+		client, err := newTestClient(server, opts...)
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	It("should send requests through an HTTP proxy with auth", func() {
+		var lock sync.Mutex
+		seen := []string{}
+		proxy := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				want := "Basic " + base64.StdEncoding.EncodeToString(
+					[]byte("alice:s3cret"))
+				if r.Header.Get("Proxy-Authorization") != want {
+					w.WriteHeader(http.StatusProxyAuthRequired)
+					return
+				}
+
+				lock.Lock()
+				seen = append(seen, r.URL.String())
+				lock.Unlock()
+
+				// Answer as the API server would
+				tlsHandler.ServeHTTP(w, r)
+			}))
+		defer proxy.Close()
+
+		u, err := url.Parse(proxy.URL)
+		Expect(err).To(BeNil())
+		u.User = url.UserPassword("alice", "s3cret")
+
+		_, err = newClient(test_url, WithProxy(u.String())).GetNodes()
+		Expect(err).To(BeNil())
+		Expect(seen).To(Equal([]string{test_url + "/auth", test_url + "/nodes"}))
+
+		u.User = url.UserPassword("alice", "wrong")
+		_, err = newClient(test_url, WithProxy(u.String())).GetNodes()
+		Expect(statusCode(err)).To(Equal(http.StatusProxyAuthRequired))
+	})
+
+	It("should send requests through a SOCKS5 proxy with auth", func() {
+		api := httptest.NewServer(tlsHandler)
+		defer api.Close()
+
+		socks := newSOCKSServer("alice", "s3cret", api.Listener.Addr().String())
+		defer socks.Close()
+
+		proxy := "socks5://alice:s3cret@" + socks.Addr().String()
+		_, err := newClient(test_url, WithProxy(proxy)).GetNodes()
+		Expect(err).To(BeNil())
+		Expect(socks.requested()).To(ContainElement("sf-api.invalid:13000"))
+
+		proxy = "socks5://alice:wrong@" + socks.Addr().String()
+		_, err = newClient(test_url, WithProxy(proxy)).GetNodes()
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should reject unsupported proxies", func() {
+		_, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithProxy("ftp://proxy:21"))
+		Expect(err).To(MatchError(ContainSubstring("unsupported proxy scheme")))
+
+		_, err = NewClientWithOptions(test_url, test_namespace, test_key,
+			WithProxy("http://"))
+		Expect(err).To(MatchError(ContainSubstring("has no host")))
+	})
+
+	It("should connect to a Unix socket", func() {
+		dir, err := ioutil.TempDir("", "sock")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "sf.sock")
+		l, err := net.Listen("unix", path)
+		Expect(err).To(BeNil())
+
+		var lock sync.Mutex
+		paths := []string{}
+		server := &http.Server{Handler: http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				paths = append(paths, r.URL.Path)
+				lock.Unlock()
+
+				r.URL.Path = r.URL.Path[len("/api"):]
+				tlsHandler.ServeHTTP(w, r)
+			})}
+		go server.Serve(l)
+		defer server.Close()
+
+		// A proxy must not be used for the socket
+		client := newClient("http+unix://"+url.PathEscape(path)+"/api",
+			WithProxy("http://proxy.invalid:3128"))
+		_, err = client.GetNodes()
+		Expect(err).To(BeNil())
+		Expect(paths).To(Equal([]string{"/api/auth", "/api/nodes"}))
+	})
+
+	It("should reject an invalid Unix socket URL", func() {
+		_, err := NewClientWithOptions("http+unix:///api", test_namespace,
+			test_key)
+		Expect(err).To(MatchError(ContainSubstring("invalid Unix socket URL")))
+	})
+
+	It("should return a client which reports an invalid URL", func() {
+		client := NewClient("http+unix://%zz/run", test_namespace, test_key)
+		Expect(client).NotTo(BeNil())
+
+		client.SetTimeout(5)
+		_, err := client.GetNodes()
+		Expect(err).To(MatchError(ContainSubstring("cannot create client")))
+		_, err = client.GetArtifacts("")
+		Expect(err).To(MatchError(ContainSubstring("cannot create client")))
+		_, err = ioutil.ReadAll(client.StreamConsole(context.Background(), "abc"))
+		Expect(err).To(MatchError(ContainSubstring("cannot create client")))
+	})
+})
@@ -1,5 +1,106 @@
 package client
 
+import (
+	"context"
+	"io"
+	"strconv"
+)
+
+// Upload is a staging area on an API server for data, usually an image,
+// which is then turned into an artifact.
+type Upload struct {
+	UUID      string
+	Node      string
+	CreatedAt float64 `json:"created_at"`
+}
+
+// CreateUpload starts a new upload.
+func (c *Client) CreateUpload() (Upload, error) {
+	return c.CreateUploadCtx(context.Background())
+}
+
+// CreateUploadCtx is CreateUpload with a caller supplied context.
+func (c *Client) CreateUploadCtx(ctx context.Context) (Upload, error) {
+	if err := c.require(ctx, CapabilityUploads); err != nil {
+		return Upload{}, err
+	}
+
+	var upload Upload
+	err := c.doRequestJSON(ctx, "upload", "POST", nil, &upload)
+	return upload, err
+}
+
+// SendUpload appends size bytes read from data to an upload, and returns
+// the length of the upload so far. The data is streamed from data rather
+// than held in memory, and is read again if the request has to be resent.
+// data must allow concurrent calls to ReadAt, as *os.File does.
+//
+// Large images are best sent as a series of chunks, using
+// io.NewSectionReader, so a failed chunk can be resent after truncating the
+// upload to the length returned for the last one which succeeded.
+func (c *Client) SendUpload(uuid string, data io.ReaderAt,
+	size int64) (int64, error) {
+
+	return c.SendUploadCtx(context.Background(), uuid, data, size)
+}
+
+// SendUploadCtx is SendUpload with a caller supplied context.
+func (c *Client) SendUploadCtx(ctx context.Context, uuid string,
+	data io.ReaderAt, size int64) (int64, error) {
+
+	return c.sendUpload(ctx, uuid,
+		readerAtBody(data, size, "application/octet-stream"))
+}
+
+// SendUploadFile appends the file at path to an upload, and returns the
+// length of the upload so far. The file is streamed rather than held in
+// memory.
+func (c *Client) SendUploadFile(uuid string, path string) (int64, error) {
+	return c.SendUploadFileCtx(context.Background(), uuid, path)
+}
+
+// SendUploadFileCtx is SendUploadFile with a caller supplied context.
+func (c *Client) SendUploadFileCtx(ctx context.Context, uuid string,
+	path string) (int64, error) {
+
+	body, err := fileBody(path, "application/octet-stream")
+	if err != nil {
+		return 0, err
+	}
+	return c.sendUpload(ctx, uuid, body)
+}
+
+// sendUpload appends body to an upload.
+func (c *Client) sendUpload(ctx context.Context, uuid string,
+	body *requestBody) (int64, error) {
+
+	if err := c.require(ctx, CapabilityUploads); err != nil {
+		return 0, err
+	}
+
+	var length int64
+	err := c.doRequestJSON(ctx, "upload/"+uuid, "POST", body, &length)
+	return length, err
+}
+
+// TruncateUpload discards the data in an upload after offset bytes, so a
+// chunk which failed can be sent again.
+func (c *Client) TruncateUpload(uuid string, offset int64) error {
+	return c.TruncateUploadCtx(context.Background(), uuid, offset)
+}
+
+// TruncateUploadCtx is TruncateUpload with a caller supplied context.
+func (c *Client) TruncateUploadCtx(ctx context.Context, uuid string,
+	offset int64) error {
+
+	if err := c.require(ctx, CapabilityUploads); err != nil {
+		return err
+	}
+
+	path := "upload/" + uuid + "/truncate/" + strconv.FormatInt(offset, 10)
+	return c.doRequestJSON(ctx, path, "POST", nil, nil)
+}
+
 /****
 
    def get_existing_locks(self):
@@ -11,18 +112,4 @@ package client
             'GET', '/networks/' + network_ref + '/ping/' + address)
         return r.json()
 
-    def create_upload(self):
-        r = self._request_url('POST', '/upload')
-        return r.json()
-
-    def send_upload(self, upload_uuid, data):
-        r = self._request_url('POST', '/upload/' + upload_uuid,
-                              data=data, request_body_is_binary=True)
-        return r.json()
-
-    def truncate_upload(self, upload_uuid, offset):
-        r = self._request_url(
-            'POST', '/upload/' + upload_uuid + '/truncate/' + str(offset))
-        return r.json()
-
 ****/
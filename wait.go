@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WaitOptions control how often the wait helpers poll the server. The delay
+// between polls starts at Interval and is multiplied by Multiplier after
+// each poll, up to MaxInterval. A zero MaxInterval sets no limit, and a
+// zero Multiplier keeps the delay at Interval.
+type WaitOptions struct {
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Multiplier  float64
+}
+
+// DefaultWaitOptions returns the polling used unless WithWaitPolling is
+// given.
+func DefaultWaitOptions() WaitOptions {
+	return WaitOptions{
+		Interval:    time.Second,
+		MaxInterval: 10 * time.Second,
+		Multiplier:  1.5,
+	}
+}
+
+// WithWaitPolling sets how often the wait helpers poll the server.
+func WithWaitPolling(opts WaitOptions) Option {
+	return func(c *Client) error {
+		if err := opts.validate(); err != nil {
+			return fmt.Errorf("invalid wait polling: %w", err)
+		}
+		c.waitOptions = &opts
+		return nil
+	}
+}
+
+// validate returns an error if the options would poll without a delay or
+// with one which shrinks.
+func (o WaitOptions) validate() error {
+	switch {
+	case o.Interval <= 0:
+		return fmt.Errorf("interval %v is not positive", o.Interval)
+	case o.MaxInterval != 0 && o.MaxInterval < o.Interval:
+		return fmt.Errorf("maximum interval %v is less than interval %v",
+			o.MaxInterval, o.Interval)
+	case o.Multiplier != 0 && o.Multiplier < 1:
+		return fmt.Errorf("multiplier %v is less than 1", o.Multiplier)
+	}
+	return nil
+}
+
+// waitPolling returns the polling set with WithWaitPolling, or the default.
+func (c *Client) waitPolling() WaitOptions {
+	if c.waitOptions != nil {
+		return *c.waitOptions
+	}
+	return DefaultWaitOptions()
+}
+
+// next returns the delay to use after interval.
+func (o WaitOptions) next(interval time.Duration) time.Duration {
+	if o.Multiplier > 1 {
+		interval = time.Duration(float64(interval) * o.Multiplier)
+	}
+	if o.MaxInterval > 0 && interval > o.MaxInterval {
+		interval = o.MaxInterval
+	}
+	return interval
+}
+
+// noun names one resource of the type, for messages.
+func (r ResourceType) noun() string {
+	nouns := [...]string{"namespace", "instance", "network", "artifact"}
+	if r < 0 || int(r) >= len(nouns) {
+		return fmt.Sprintf("resource type %d", int(r))
+	}
+	return nouns[r]
+}
+
+// WaitForInstanceState polls an instance until it reaches one of the target
+// states, and returns it. If the instance reaches one of the failure states
+// instead, it is returned with a FailureStateError. If ctx is done first,
+// the error is a WaitTimeoutError holding the last state seen.
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+//	defer cancel()
+//	instance, err := c.WaitForInstanceState(ctx, uuid,
+//		[]State{StateCreated}, []State{StateError, StateDeleted})
+func (c *Client) WaitForInstanceState(ctx context.Context, uuid string,
+	targets, failures []State) (Instance, error) {
+
+	var instance Instance
+	err := c.waitFor(ctx, TypeInstance, uuid, targets, failures,
+		func(ctx context.Context) (State, error) {
+			var err error
+			instance, err = c.GetInstanceCtx(ctx, uuid)
+			return instance.State, err
+		})
+	return instance, err
+}
+
+// WaitForNetworkState polls a network until it reaches one of the target
+// states, and returns it. Failures and timeouts are reported as for
+// WaitForInstanceState.
+func (c *Client) WaitForNetworkState(ctx context.Context, uuid string,
+	targets, failures []State) (Network, error) {
+
+	var network Network
+	err := c.waitFor(ctx, TypeNetwork, uuid, targets, failures,
+		func(ctx context.Context) (State, error) {
+			var err error
+			network, err = c.GetNetworkCtx(ctx, uuid)
+			return network.State, err
+		})
+	return network, err
+}
+
+// WaitForDeleted polls a resource until it is in the "deleted" state or
+// the server no longer knows of it. A resource which reaches the "error"
+// state instead gives a FailureStateError. If ctx is done first, the error
+// is a WaitTimeoutError holding the last state seen.
+func (c *Client) WaitForDeleted(ctx context.Context, res ResourceType,
+	uuid string) error {
+
+	path := res.String() + "/" + uuid
+	return c.waitFor(ctx, res, uuid, []State{StateDeleted}, []State{StateError},
+		func(ctx context.Context) (State, error) {
+			var resource struct {
+				State State `json:"state"`
+			}
+			err := c.doRequestJSON(ctx, path, "GET", nil, &resource)
+			if IsNotFound(err) {
+				return StateDeleted, nil
+			}
+			return resource.State, err
+		})
+}
+
+// waitFor calls poll until the state it returns is one of targets or
+// failures.
+func (c *Client) waitFor(ctx context.Context, res ResourceType, uuid string,
+	targets, failures []State,
+	poll func(ctx context.Context) (State, error)) error {
+
+	opts := c.waitPolling()
+
+	// The state must come from the server, not the response cache
+	pollCtx := BypassCache(ctx)
+
+	var last State
+	interval := opts.Interval
+	for {
+		state, err := poll(pollCtx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return &WaitTimeoutError{Resource: res, UUID: uuid,
+					Targets: targets, LastState: last, Err: ctx.Err()}
+			}
+			return err
+		}
+		last = state
+
+		if stateIn(state, targets) {
+			return nil
+		}
+		if stateIn(state, failures) {
+			return &FailureStateError{Resource: res, UUID: uuid, State: state}
+		}
+
+		if err := sleepCtx(ctx, interval); err != nil {
+			return &WaitTimeoutError{Resource: res, UUID: uuid,
+				Targets: targets, LastState: last, Err: err}
+		}
+		interval = opts.next(interval)
+	}
+}
+
+// stateIn reports whether state is in states.
+func stateIn(state State, states []State) bool {
+	for _, s := range states {
+		if state == s {
+			return true
+		}
+	}
+	return false
+}
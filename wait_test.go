@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Waiting for states", func() {
+	const (
+		test_uuid string = "5f0c3c9e-7a4b-4d0e-9a55-2b1f3f6e8c11"
+	)
+
+	var (
+		server *httptest.Server
+
+		// states are returned in turn, the last one repeating. States of
+		// "403" and "404" answer with that status.
+		lock   sync.Mutex
+		states []string
+		polls  int
+	)
+
+	BeforeEach(func() {
+		polls = 0
+		server = newTestServer(
+			func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				state := states[len(states)-1]
+				if polls < len(states) {
+					state = states[polls]
+				}
+				polls++
+				lock.Unlock()
+
+				switch state {
+				case "403":
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"error": "forbidden", "status": 403}`))
+					return
+				case "404":
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"error": "not found", "status": 404}`))
+					return
+				}
+				w.Header().Set("ETag", `"same"`)
+				w.Write([]byte(`{"uuid": "` + test_uuid + `", "name": "thing",
+					"state": "` + state + `"}`))
+			})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func(opts ...Option) *Client {
+		opts = append([]Option{
+			WithWaitPolling(WaitOptions{
+				Interval:    time.Millisecond,
+				MaxInterval: 5 * time.Millisecond,
+				Multiplier:  2,
+			}),
+		}, opts...)
+		client, err := newTestClient(server.URL, opts...)
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	pollCount := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return polls
+	}
+
+	It("should wait for an instance to reach a target state", func() {
+		states = []string{"initial", "preflight", "creating", "created"}
+		instance, err := newClient().WaitForInstanceState(context.Background(),
+			test_uuid, []State{StateCreated}, []State{StateError})
+		Expect(err).To(BeNil())
+		Expect(instance.UUID).To(Equal(test_uuid))
+		Expect(instance.State).To(Equal(StateCreated))
+		Expect(pollCount()).To(Equal(4))
+	})
+
+	It("should wait for a network to reach a target state", func() {
+		states = []string{"initial", "created"}
+		network, err := newClient().WaitForNetworkState(context.Background(),
+			test_uuid, []State{StateCreated}, []State{StateError})
+		Expect(err).To(BeNil())
+		Expect(network.State).To(Equal(StateCreated))
+	})
+
+	It("should stop at a failure state", func() {
+		states = []string{"initial", "creating", "error", "created"}
+		instance, err := newClient().WaitForInstanceState(context.Background(),
+			test_uuid, []State{StateCreated}, []State{StateError, StateDeleted})
+		Expect(IsFailureState(err)).To(BeTrue())
+		Expect(err).To(MatchError("instance " + test_uuid + " is in state error"))
+		Expect(instance.State).To(Equal(StateError))
+		Expect(pollCount()).To(Equal(3))
+	})
+
+	It("should report the last state when the context is done", func() {
+		states = []string{"initial", "creating"}
+		ctx, cancel := context.WithTimeout(context.Background(),
+			50*time.Millisecond)
+		defer cancel()
+
+		_, err := newClient().WaitForInstanceState(ctx, test_uuid,
+			[]State{StateCreated}, []State{StateError})
+		Expect(IsWaitTimeout(err)).To(BeTrue())
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+
+		var timeout *WaitTimeoutError
+		Expect(errors.As(err, &timeout)).To(BeTrue())
+		Expect(timeout.LastState).To(Equal(StateCreating))
+		Expect(timeout.Targets).To(Equal([]State{StateCreated}))
+		Expect(err.Error()).To(HavePrefix("instance " + test_uuid +
+			" did not reach created, last state creating: "))
+	})
+
+	It("should back off between polls", func() {
+		states = []string{"initial"}
+		client := newClient(WithWaitPolling(WaitOptions{
+			Interval:    10 * time.Millisecond,
+			MaxInterval: 40 * time.Millisecond,
+			Multiplier:  2,
+		}))
+		ctx, cancel := context.WithTimeout(context.Background(),
+			200*time.Millisecond)
+		defer cancel()
+
+		_, err := client.WaitForNetworkState(ctx, test_uuid,
+			[]State{StateCreated}, nil)
+		Expect(IsWaitTimeout(err)).To(BeTrue())
+
+		// Sleeps of 10, 20, 40, 40, 40 and 40ms fit in 200ms, less the
+		// time taken by the requests themselves
+		Expect(pollCount()).To(BeNumerically(">=", 4))
+		Expect(pollCount()).To(BeNumerically("<=", 7))
+	})
+
+	It("should grow the interval up to the maximum", func() {
+		opts := WaitOptions{Interval: time.Second, MaxInterval: 3 * time.Second,
+			Multiplier: 2}
+		Expect(opts.next(time.Second)).To(Equal(2 * time.Second))
+		Expect(opts.next(2 * time.Second)).To(Equal(3 * time.Second))
+		Expect(WaitOptions{Interval: time.Second}.next(time.Second)).To(
+			Equal(time.Second))
+	})
+
+	It("should reject polling which would not back off", func() {
+		for _, opts := range []WaitOptions{
+			{},
+			{Interval: -time.Second},
+			{Interval: time.Second, MaxInterval: time.Millisecond},
+			{Interval: time.Second, Multiplier: 0.5},
+		} {
+			_, err := newTestClient(server.URL, WithWaitPolling(opts))
+			Expect(err).To(MatchError(HavePrefix("invalid wait polling: ")))
+		}
+
+		_, err := newTestClient(server.URL,
+			WithWaitPolling(WaitOptions{Interval: time.Second}))
+		Expect(err).To(BeNil())
+	})
+
+	It("should treat a 404 as deleted", func() {
+		states = []string{"deleting", "deleting", "404"}
+		err := newClient().WaitForDeleted(context.Background(), TypeInstance,
+			test_uuid)
+		Expect(err).To(BeNil())
+		Expect(pollCount()).To(Equal(3))
+	})
+
+	It("should treat the deleted state as deleted", func() {
+		states = []string{"deleting", "deleted"}
+		err := newClient().WaitForDeleted(context.Background(), TypeNetwork,
+			test_uuid)
+		Expect(err).To(BeNil())
+	})
+
+	It("should stop waiting for deletion at the error state", func() {
+		states = []string{"deleting", "error"}
+		err := newClient().WaitForDeleted(context.Background(), TypeInstance,
+			test_uuid)
+		Expect(IsFailureState(err)).To(BeTrue())
+		Expect(pollCount()).To(Equal(2))
+	})
+
+	It("should return other errors while waiting", func() {
+		states = []string{"deleting", "403"}
+		err := newClient().WaitForDeleted(context.Background(), TypeInstance,
+			test_uuid)
+		Expect(IsForbidden(err)).To(BeTrue())
+		Expect(IsWaitTimeout(err)).To(BeFalse())
+	})
+
+	It("should not wait on cached responses", func() {
+		states = []string{"initial", "initial", "created"}
+		client := newClient(WithResponseCache(CacheSettings{TTL: time.Hour}))
+
+		// Fill the cache with the first state
+		instance, err := client.GetInstance(test_uuid)
+		Expect(err).To(BeNil())
+		Expect(instance.State).To(Equal(StateInitial))
+
+		instance, err = client.WaitForInstanceState(context.Background(),
+			test_uuid, []State{StateCreated}, nil)
+		Expect(err).To(BeNil())
+		Expect(instance.State).To(Equal(StateCreated))
+		Expect(pollCount()).To(Equal(3))
+	})
+
+	It("should name the resource in errors", func() {
+		err := &FailureStateError{Resource: TypeNamespace, UUID: "ns", State: "gone"}
+		Expect(err.Error()).To(Equal("namespace ns is in state gone"))
+
+		timeout := &WaitTimeoutError{Resource: TypeNetwork, UUID: "n",
+			Targets: []State{StateCreated, StateDeleted}, Err: context.Canceled}
+		Expect(timeout.Error()).To(Equal(
+			"network n did not reach created or deleted, last state unknown: " +
+				"context canceled"))
+	})
+})
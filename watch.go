@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// EventFilter selects events by operation, phase and message. Empty fields
+// match every event.
+type EventFilter struct {
+	Operation string
+	Phase     string
+	Message   *regexp.Regexp
+}
+
+// Matches reports whether an event is selected by the filter.
+func (f EventFilter) Matches(e Event) bool {
+	if f.Operation != "" && f.Operation != e.Operation {
+		return false
+	}
+	if f.Phase != "" && f.Phase != e.Phase {
+		return false
+	}
+	if f.Message != nil && !f.Message.MatchString(e.Message) {
+		return false
+	}
+	return true
+}
+
+// EventWatch delivers the events of a resource as they occur.
+type EventWatch struct {
+	events chan Event
+	err    error
+}
+
+// Events returns the channel events are delivered on. It is closed when
+// the watch ends.
+func (w *EventWatch) Events() <-chan Event {
+	return w.events
+}
+
+// Err returns why the watch ended. It must only be called once the events
+// channel is closed.
+func (w *EventWatch) Err() error {
+	return w.err
+}
+
+// WatchEvents polls the events of an instance, network or artifact, and
+// delivers each event once, in the order the server lists them. Events
+// which occurred before the watch started are delivered first. If filters
+// are given, only events matched by at least one of them are delivered.
+//
+// Errors from the server which may be temporary are retried, and polling
+// resumes without delivering events twice. The watch ends when ctx is done
+// or the server refuses the request, after which Err returns the reason.
+// Polling is set with WithWaitPolling, and the interval is reset whenever
+// new events arrive.
+//
+//	watch := c.WatchEvents(ctx, client.TypeInstance, uuid,
+//		client.EventFilter{Phase: "finish"})
+//	for event := range watch.Events() {
+//		bar.Increment()
+//	}
+//	if err := watch.Err(); err != nil && !errors.Is(err, context.Canceled) {
+//		return err
+//	}
+func (c *Client) WatchEvents(ctx context.Context, res ResourceType,
+	uuid string, filters ...EventFilter) *EventWatch {
+
+	w := &EventWatch{events: make(chan Event)}
+
+	var capability Capability
+	switch res {
+	case TypeInstance, TypeNetwork:
+	case TypeArtifact:
+		capability = CapabilityArtifacts
+	case TypeNamespace:
+		w.err = fmt.Errorf("%s resources have no events", res.noun())
+	default:
+		w.err = fmt.Errorf("unknown %s", res.noun())
+	}
+	if w.err != nil {
+		close(w.events)
+		return w
+	}
+
+	path := res.String() + "/" + uuid + "/events"
+	list := func(ctx context.Context) ([]watchedEvent, error) {
+		if capability != "" {
+			if err := c.require(ctx, capability); err != nil {
+				return nil, err
+			}
+		}
+		var events []watchedEvent
+		err := c.doRequestJSON(ctx, path, "GET", nil, &events)
+		return events, err
+	}
+
+	go func() {
+		defer close(w.events)
+		w.err = c.watchEvents(ctx, w.events, list, filters)
+	}()
+	return w
+}
+
+// watchedEvent is an event with its timestamp at full precision. The
+// float32 in Event only resolves current times to about two minutes, too
+// coarse to tell apart events with the same content.
+type watchedEvent struct {
+	Event
+	at float64
+}
+
+func (e *watchedEvent) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &e.Event); err != nil {
+		return err
+	}
+	var timestamp struct {
+		Timestamp float64 `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &timestamp); err != nil {
+		return err
+	}
+	e.at = timestamp.Timestamp
+	return nil
+}
+
+// watchEvents polls list until ctx is done or it fails with an error which
+// will not go away.
+func (c *Client) watchEvents(ctx context.Context, out chan<- Event,
+	list func(ctx context.Context) ([]watchedEvent, error),
+	filters []EventFilter) error {
+
+	opts := c.waitPolling()
+
+	// Events are told apart by their content and full timestamp. Events
+	// older than those in the last poll are forgotten, as the server will
+	// not list them again.
+	seen := map[watchedEvent]bool{}
+
+	pollCtx := BypassCache(ctx)
+	interval := opts.Interval
+	for {
+		events, err := list(pollCtx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !watchRetryable(err) {
+			return err
+		}
+
+		forgetBefore(seen, events)
+		fresh := false
+		for _, e := range events {
+			if seen[e] {
+				continue
+			}
+			seen[e] = true
+			fresh = true
+
+			if !matchesAny(e.Event, filters) {
+				continue
+			}
+			select {
+			case out <- e.Event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if fresh {
+			interval = opts.Interval
+		}
+		if err := sleepCtx(ctx, interval); err != nil {
+			return err
+		}
+		if !fresh {
+			interval = opts.next(interval)
+		}
+	}
+}
+
+// forgetBefore removes the events older than the oldest of events from
+// seen. Nothing is removed if events is empty.
+func forgetBefore(seen map[watchedEvent]bool, events []watchedEvent) {
+	if len(events) == 0 {
+		return
+	}
+	oldest := events[0].at
+	for _, e := range events[1:] {
+		if e.at < oldest {
+			oldest = e.at
+		}
+	}
+	for e := range seen {
+		if e.at < oldest {
+			delete(seen, e)
+		}
+	}
+}
+
+// matchesAny reports whether an event is matched by one of filters, or
+// there are no filters.
+func matchesAny(e Event, filters []EventFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if f.Matches(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchRetryable reports whether a watch should keep polling after err.
+// Errors from the server other than 5xx and 429 responses will not go away,
+// and nor will responses which cannot be decoded.
+func watchRetryable(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if IsUnsupported(err) || errors.Is(err, errInvalidClient) ||
+		errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return false
+	}
+	code := statusCode(err)
+	return code == 0 || code == http.StatusTooManyRequests || code >= 500
+}
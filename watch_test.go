@@ -0,0 +1,236 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watching events", func() {
+	const (
+		test_url       string = "http://server:13000"
+		test_namespace string = "testspace"
+		test_key       string = "testkey"
+		test_uuid      string = "0b6e5a1c-3f2d-4c8e-9d7a-6e4f2a1b3c5d"
+	)
+
+	var (
+		server *httptest.Server
+
+		// Each poll appends the next of pending to the events returned. A
+		// status in failures answers that poll instead.
+		lock     sync.Mutex
+		paths    []string
+		pending  []Event
+		events   []Event
+		failures map[int]int
+		polls    int
+	)
+
+	BeforeEach(func() {
+		paths = nil
+		events = nil
+		failures = map[int]int{}
+		polls = 0
+		pending = []Event{
+			{Timestamp: 1, Operation: "create", Phase: "start", Message: "starting"},
+			{Timestamp: 1, Operation: "create", Phase: "finish", Message: "created disk 0"},
+			{Timestamp: 2, Operation: "create", Phase: "finish", Message: "created disk 1"},
+			{Timestamp: 2, Operation: "power on", Phase: "start", Message: "booting"},
+			{Timestamp: 3, Operation: "power on", Phase: "finish", Message: "booted"},
+		}
+
+		server = newTestServer(
+			func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				paths = append(paths, r.URL.Path)
+				polls++
+				if status, ok := failures[polls]; ok {
+					w.WriteHeader(status)
+					w.Write([]byte(`{"error": "failed", "status": 0}`))
+					return
+				}
+				if len(pending) > 0 {
+					events = append(events, pending[0])
+					pending = pending[1:]
+				}
+				Expect(json.NewEncoder(w).Encode(events)).To(Succeed())
+			})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func() *Client {
+		client, err := newTestClient(server.URL,
+			WithWaitPolling(WaitOptions{
+				Interval:    time.Millisecond,
+				MaxInterval: 5 * time.Millisecond,
+				Multiplier:  2,
+			}))
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	// receive reads n events from a watch.
+	receive := func(watch *EventWatch, n int) []Event {
+		var received []Event
+		for len(received) < n {
+			select {
+			case e, ok := <-watch.Events():
+				Expect(ok).To(BeTrue())
+				received = append(received, e)
+			case <-time.After(5 * time.Second):
+				Fail("timed out waiting for events")
+			}
+		}
+		return received
+	}
+
+	It("should deliver each event once", func() {
+		all := append([]Event{}, pending...)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		watch := newClient().WatchEvents(ctx, TypeInstance, test_uuid)
+		Expect(receive(watch, len(all))).To(Equal(all))
+
+		// Nothing more arrives once the server has no new events
+		Consistently(watch.Events(), 50*time.Millisecond).ShouldNot(Receive())
+
+		cancel()
+		Eventually(watch.Events()).Should(BeClosed())
+		Expect(watch.Err()).To(Equal(context.Canceled))
+
+		lock.Lock()
+		defer lock.Unlock()
+		Expect(paths[0]).To(Equal("/instances/" + test_uuid + "/events"))
+	})
+
+	It("should watch networks and artifacts", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		receive(newClient().WatchEvents(ctx, TypeNetwork, test_uuid), 1)
+		receive(newClient().WatchEvents(ctx, TypeArtifact, test_uuid), 1)
+
+		lock.Lock()
+		defer lock.Unlock()
+		Expect(paths).To(ContainElement("/networks/" + test_uuid + "/events"))
+		Expect(paths).To(ContainElement("/artifacts/" + test_uuid + "/events"))
+	})
+
+	It("should only deliver events matching a filter", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		watch := newClient().WatchEvents(ctx, TypeInstance, test_uuid,
+			EventFilter{Operation: "create", Phase: "finish"},
+			EventFilter{Message: regexp.MustCompile(`^booting`)})
+		received := receive(watch, 3)
+		Expect(received[0].Message).To(Equal("created disk 0"))
+		Expect(received[1].Message).To(Equal("created disk 1"))
+		Expect(received[2].Message).To(Equal("booting"))
+		Consistently(watch.Events(), 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should resume after errors without repeating events", func() {
+		failures[2] = http.StatusInternalServerError
+		failures[3] = http.StatusBadGateway
+		failures[5] = http.StatusTooManyRequests
+		all := append([]Event{}, pending...)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		watch := newClient().WatchEvents(ctx, TypeInstance, test_uuid)
+		Expect(receive(watch, len(all))).To(Equal(all))
+		Consistently(watch.Events(), 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should end when the server refuses the watch", func() {
+		failures[3] = http.StatusNotFound
+		watch := newClient().WatchEvents(context.Background(), TypeInstance,
+			test_uuid)
+		receive(watch, 2)
+		Eventually(watch.Events()).Should(BeClosed())
+		Expect(IsNotFound(watch.Err())).To(BeTrue())
+	})
+
+	It("should end when the server lacks artifacts", func() {
+		client := newClient()
+		client.serverInfo = &ServerInfo{Version: "v0.3.0", Capabilities: []Capability{}}
+		watch := client.WatchEvents(context.Background(), TypeArtifact, test_uuid)
+		Eventually(watch.Events()).Should(BeClosed())
+		Expect(IsUnsupported(watch.Err())).To(BeTrue())
+	})
+
+	It("should refuse resources without events", func() {
+		watch := newClient().WatchEvents(context.Background(), TypeNamespace,
+			"system")
+		Expect(watch.Events()).To(BeClosed())
+		Expect(watch.Err()).To(MatchError("namespace resources have no events"))
+
+		watch = newClient().WatchEvents(context.Background(), ResourceType(9),
+			test_uuid)
+		Expect(watch.Events()).To(BeClosed())
+		Expect(watch.Err()).To(MatchError("unknown resource type 9"))
+	})
+
+	It("should end when the response cannot be decoded", func() {
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithWaitPolling(WaitOptions{Interval: time.Millisecond}))
+		Expect(err).To(BeNil())
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewStringResponder(200, `{"access_token":"token"}`))
+		httpmock.RegisterResponder("GET", test_url+"/instances/"+test_uuid+"/events",
+			httpmock.NewStringResponder(200, `{"not": "a list"}`))
+
+		watch := client.WatchEvents(context.Background(), TypeInstance, test_uuid)
+		Eventually(watch.Events()).Should(BeClosed())
+		var typeErr *json.UnmarshalTypeError
+		Expect(errors.As(watch.Err(), &typeErr)).To(BeTrue())
+	})
+
+	It("should forget events older than the last poll", func() {
+		old := watchedEvent{Event{Message: "old"}, 1}
+		same := watchedEvent{Event{Message: "same time"}, 2}
+		current := watchedEvent{Event{Message: "current"}, 2}
+		seen := map[watchedEvent]bool{old: true, same: true, current: true}
+
+		forgetBefore(seen, nil)
+		Expect(seen).To(HaveLen(3))
+		forgetBefore(seen, []watchedEvent{current, {at: 3}})
+		Expect(seen).To(Equal(map[watchedEvent]bool{same: true, current: true}))
+	})
+
+	It("should tell apart events closer than a float32 can", func() {
+		client, err := NewClientWithOptions(test_url, test_namespace, test_key,
+			WithWaitPolling(WaitOptions{Interval: time.Millisecond}))
+		Expect(err).To(BeNil())
+		httpmock.RegisterResponder("POST", test_url+"/auth",
+			httpmock.NewStringResponder(200, `{"access_token":"token"}`))
+		httpmock.RegisterResponder("GET", test_url+"/instances/"+test_uuid+"/events",
+			httpmock.NewStringResponder(200, `[
+				{"timestamp": 1700000000.25, "operation": "poll", "message": "waiting"},
+				{"timestamp": 1700000030.5, "operation": "poll", "message": "waiting"}
+			]`))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		watch := client.WatchEvents(ctx, TypeInstance, test_uuid)
+		received := receive(watch, 2)
+		Expect(received[0]).To(Equal(received[1]))
+		Consistently(watch.Events(), 20*time.Millisecond).ShouldNot(Receive())
+	})
+})